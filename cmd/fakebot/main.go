@@ -0,0 +1,60 @@
+// fakebot sirve la Bot API falsa de internal/telegram/fakebot para probar
+// SmartSlide de punta a punta sin Telegram. Apuntar telegram_api_endpoint a
+// http://<addr>. Rutas de control:
+//
+//	GET  /_fake/calls                              llamadas registradas
+//	POST /_fake/fail?method=sendPhoto&status=429&retry_after=2
+//	POST /_fake/command?chat_id=1&user_id=1&text=/status
+//	POST /_fake/reset
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"net/http"
+	"strconv"
+
+	"IA1_EV2025_Proyecto2/internal/telegram/fakebot"
+)
+
+func main() {
+	addr := flag.String("addr", "127.0.0.1:8081", "dirección de escucha")
+	flag.Parse()
+
+	bot := fakebot.New()
+	bot.OnCall = func(c fakebot.Call) {
+		log.Printf("[fakebot] %s chat=%d status=%d files=%d messages=%v", c.Method, c.ChatID, c.Status, len(c.Files), c.MessageIDs)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/", bot)
+	mux.HandleFunc("/_fake/calls", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(bot.Calls())
+	})
+	mux.HandleFunc("/_fake/fail", func(w http.ResponseWriter, r *http.Request) {
+		status, err := strconv.Atoi(r.URL.Query().Get("status"))
+		if err != nil || status < 400 {
+			http.Error(w, "status inválido", http.StatusBadRequest)
+			return
+		}
+		retryAfter, _ := strconv.Atoi(r.URL.Query().Get("retry_after"))
+		bot.FailNext(r.URL.Query().Get("method"), status, retryAfter)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("/_fake/command", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		chatID, _ := strconv.ParseInt(q.Get("chat_id"), 10, 64)
+		userID, _ := strconv.ParseInt(q.Get("user_id"), 10, 64)
+		bot.PushCommand(chatID, userID, q.Get("text"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("/_fake/reset", func(w http.ResponseWriter, r *http.Request) {
+		bot.Reset()
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	log.Printf("[fakebot] listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, mux))
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"IA1_EV2025_Proyecto2/internal/auth"
+	"IA1_EV2025_Proyecto2/internal/config"
+)
+
+const authUsage = `uso:
+  smartslide auth password [-role read|admin] usuario   (lee la contraseña de stdin)
+  smartslide auth token [-role read|admin] nombre`
+
+// runAuth implementa "smartslide auth": genera las entradas de admin_auth para
+// pegar en la configuración, que solo guarda hashes.
+func runAuth(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, authUsage)
+		return 2
+	}
+	fs := flag.NewFlagSet("auth "+args[0], flag.ExitOnError)
+	role := fs.String("role", "read", "rol: read o admin")
+	_ = fs.Parse(args[1:])
+	if fs.NArg() != 1 || (*role != "read" && *role != "admin") {
+		fmt.Fprintln(os.Stderr, authUsage)
+		return 2
+	}
+	name := fs.Arg(0)
+
+	var entry any
+	switch args[0] {
+	case "password":
+		fmt.Fprint(os.Stderr, "Contraseña: ")
+		line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		password := strings.TrimRight(line, "\r\n")
+		if password == "" {
+			fmt.Fprintln(os.Stderr, "contraseña vacía")
+			return 1
+		}
+		hash, err := auth.HashPassword(password)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "auth: %v\n", err)
+			return 1
+		}
+		entry = config.AdminUser{Name: name, PasswordHash: hash, Role: *role}
+		fmt.Fprintln(os.Stderr, "Agregar a admin_auth.users:")
+	case "token":
+		token, err := auth.NewToken()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "auth: %v\n", err)
+			return 1
+		}
+		entry = config.AdminToken{Name: name, Hash: auth.HashToken(token), Role: *role}
+		fmt.Fprintf(os.Stderr, "Token (se muestra una sola vez): %s\n", token)
+		fmt.Fprintln(os.Stderr, "Agregar a admin_auth.tokens:")
+	default:
+		fmt.Fprintln(os.Stderr, authUsage)
+		return 2
+	}
+	b, _ := json.MarshalIndent(entry, "", "  ")
+	fmt.Println(string(b))
+	return 0
+}
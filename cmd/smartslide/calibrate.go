@@ -0,0 +1,153 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"log/slog"
+	"math"
+	"os"
+	"slices"
+	"time"
+
+	"gocv.io/x/gocv"
+
+	"IA1_EV2025_Proyecto2/internal/capture"
+	"IA1_EV2025_Proyecto2/internal/config"
+)
+
+// minSensitivity es la menor sensibilidad que sugiere calibrate: por debajo
+// cualquier cambio de luz dispara una diapositiva.
+const minSensitivity = 0.02
+
+// runCalibrate implementa "smartslide calibrate": con la pantalla quieta mide
+// durante unos segundos el puntaje de cambio entre frames (el ruido de la
+// cámara), sugiere una sensibilidad por encima de ese ruido y una ROI con la
+// pantalla detectada. Con -save las guarda en el archivo de configuración.
+func runCalibrate(args []string) int {
+	fs := flag.NewFlagSet("calibrate", flag.ExitOnError)
+	flags := addConfigFlags(fs)
+	flags.field(fs, "camera", "camera_index", "índice de la cámara")
+	seconds := fs.Int("seconds", 10, "segundos de medición")
+	snapshot := fs.String("snapshot", "", "guarda el último frame, con la pantalla marcada, en este archivo")
+	save := fs.Bool("save", false, "guarda la sensibilidad sugerida")
+	saveROI := fs.Bool("save-roi", false, "guarda también la ROI de la pantalla detectada")
+	_ = fs.Parse(args)
+	if fs.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, "uso: smartslide calibrate [-config ruta] [-camera N] [-seconds 10] [-snapshot archivo.jpg] [-save] [-save-roi]")
+		return 2
+	}
+	l, err := flags.load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "config: %v\n", err)
+		return 1
+	}
+	cfg := l.Config
+	slog.SetLogLoggerLevel(slog.LevelWarn)
+
+	cam, err := capture.OpenCamera(cfg.CameraIndex)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cámara %d: %v\n", cfg.CameraIndex, err)
+		return 1
+	}
+	defer cam.Close()
+	// sensibilidad > 1: nunca dispara, solo mide
+	det := capture.NewDetector(2, 0)
+	det.ROI = capture.ROI(cfg.ROI)
+	defer det.Close()
+
+	fmt.Fprintf(os.Stderr, "Midiendo %d s con la cámara %d; no cambiar la diapositiva...\n", *seconds, cfg.CameraIndex)
+	prev, frame := gocv.NewMat(), gocv.NewMat()
+	defer prev.Close()
+	defer frame.Close()
+	var scores []float64
+	ticker := time.NewTicker(time.Second / time.Duration(max(cfg.CaptureFPS, 1)))
+	defer ticker.Stop()
+	for end := time.Now().Add(time.Duration(*seconds) * time.Second); time.Now().Before(end); <-ticker.C {
+		if !cam.Read(&frame) {
+			continue
+		}
+		if !prev.Empty() {
+			_, score := det.IsNewSlide(prev, frame)
+			scores = append(scores, score)
+		}
+		frame.CopyTo(&prev)
+	}
+	if len(scores) == 0 {
+		fmt.Fprintln(os.Stderr, "la cámara no entregó frames")
+		return 1
+	}
+
+	slices.Sort(scores)
+	sum := 0.0
+	for _, s := range scores {
+		sum += s
+	}
+	p95 := scores[int(float64(len(scores)-1)*0.95)]
+	noise := scores[len(scores)-1]
+	suggested := math.Ceil(max(noise*2, minSensitivity)*1000) / 1000
+	fmt.Printf("frames comparados: %d\n", len(scores))
+	fmt.Printf("ruido: promedio %.4f, p95 %.4f, máximo %.4f\n", sum/float64(len(scores)), p95, noise)
+	fmt.Printf("sensibilidad actual %.3f, sugerida %.3f\n", cfg.Sensitivity, suggested)
+
+	var roi *config.ROI
+	if corners := capture.FindScreen(prev); corners != nil {
+		r := image.Rect(corners[0].X, corners[0].Y, corners[0].X, corners[0].Y)
+		for _, p := range corners[1:] {
+			r = r.Union(image.Rectangle{Min: p, Max: p})
+		}
+		w, h := float64(prev.Cols()), float64(prev.Rows())
+		roi = &config.ROI{
+			X: round2(float64(r.Min.X) / w), Y: round2(float64(r.Min.Y) / h),
+			W: round2(float64(r.Dx()) / w), H: round2(float64(r.Dy()) / h),
+		}
+		fmt.Printf("pantalla detectada: roi {\"x\": %g, \"y\": %g, \"w\": %g, \"h\": %g}\n", roi.X, roi.Y, roi.W, roi.H)
+		gocv.Rectangle(&prev, r, color.RGBA{R: 255, A: 255}, 3)
+	} else {
+		fmt.Println("no se detectó la pantalla; ajustar roi a mano si hace falta")
+	}
+	if *snapshot != "" && !gocv.IMWrite(*snapshot, prev) {
+		fmt.Fprintf(os.Stderr, "no se pudo guardar %s\n", *snapshot)
+	}
+
+	if !*save {
+		return 0
+	}
+	if *saveROI && roi == nil {
+		fmt.Fprintln(os.Stderr, "sin pantalla detectada no se guarda la ROI")
+	}
+	file := l.File
+	file.Sensitivity = suggested
+	if *saveROI && roi != nil {
+		file.ROI = *roi
+	}
+	if err := saveCalibration(flags, l, file); err != nil {
+		fmt.Fprintf(os.Stderr, "config: %v\n", err)
+		return 1
+	}
+	fmt.Printf("guardado en %s\n", flags.path)
+	return 0
+}
+
+// saveCalibration guarda file si, con el entorno y los flags encima, sigue
+// siendo válida. Avisa si un override tapa lo guardado.
+func saveCalibration(flags *configFlags, l config.Loaded, file config.Config) error {
+	eff := file
+	if err := flags.overrides.Apply(&eff, nil); err != nil {
+		return err
+	}
+	if err := eff.Validate(); err != nil {
+		return err
+	}
+	for _, field := range []string{"sensitivity", "roi.x", "roi.y", "roi.w", "roi.h"} {
+		if src := l.Sources[field]; src != config.SourceDefault && src != config.SourceFile {
+			fmt.Fprintf(os.Stderr, "aviso: %s está fijado por %s y tapa el valor guardado\n", field, src)
+		}
+	}
+	return config.Save(flags.path, file)
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+
+	"IA1_EV2025_Proyecto2/internal/config"
+)
+
+const configUsage = "uso: smartslide config validate [-config ruta] [-set campo=valor] [-sources]"
+
+// runConfig implementa "smartslide config validate": carga la configuración
+// como lo haría run (archivo, entorno y flags) y muestra los errores por campo.
+func runConfig(args []string) int {
+	if len(args) == 0 || args[0] != "validate" {
+		fmt.Fprintln(os.Stderr, configUsage)
+		return 2
+	}
+	fs := flag.NewFlagSet("config validate", flag.ExitOnError)
+	flags := addConfigFlags(fs)
+	sources := fs.Bool("sources", false, "muestra de dónde sale cada valor que no es el por defecto")
+	_ = fs.Parse(args[1:])
+	if fs.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, configUsage)
+		return 2
+	}
+
+	l, err := flags.load()
+	var verr config.ValidationError
+	if errors.As(err, &verr) {
+		fmt.Fprintf(os.Stderr, "%s: configuración inválida\n", flags.path)
+		for _, fe := range verr {
+			fmt.Fprintf(os.Stderr, "  %s: %s\n", fe.Field, fe.Message)
+		}
+		return 1
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", flags.path, err)
+		return 1
+	}
+
+	fmt.Printf("%s: configuración válida\n", flags.path)
+	if l.Config.Profile != "" {
+		fmt.Printf("perfil activo: %s\n", l.Config.Profile)
+	}
+	if *sources {
+		var paths []string
+		for path, src := range l.Sources {
+			if src != config.SourceDefault {
+				paths = append(paths, path)
+			}
+		}
+		slices.Sort(paths)
+		for _, path := range paths {
+			fmt.Printf("  %-32s %s\n", path, l.Sources[path])
+		}
+	} else if o := l.Sources.Overridden(); len(o) > 0 {
+		fmt.Printf("fijados por entorno o flags: %v\n", o)
+	}
+	return 0
+}
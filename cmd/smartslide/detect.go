@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"gocv.io/x/gocv"
+
+	"IA1_EV2025_Proyecto2/internal/app"
+)
+
+// runDetect implementa "smartslide detect [-config ruta] [-sensitivity x]
+// [-diff salida.png] [-json] a b": compara dos imágenes con el detector de la
+// captura en vivo (misma ROI y sensibilidad) y muestra el puntaje de cambio.
+func runDetect(args []string) int {
+	fs := flag.NewFlagSet("detect", flag.ExitOnError)
+	flags := addConfigFlags(fs)
+	flags.field(fs, "sensitivity", "sensitivity", "umbral de cambio")
+	diffPath := fs.String("diff", "", "guarda la máscara de pixeles cambiados en este archivo")
+	asJSON := fs.Bool("json", false, "salida en JSON")
+	_ = fs.Parse(args)
+	if fs.NArg() != 2 {
+		fmt.Fprintln(os.Stderr, "uso: smartslide detect [-config ruta] [-sensitivity x] [-diff salida.png] [-json] a b")
+		return 2
+	}
+	cfg, ok := loadConfig(flags)
+	if !ok {
+		return 1
+	}
+
+	a := gocv.IMRead(fs.Arg(0), gocv.IMReadColor)
+	defer a.Close()
+	b := gocv.IMRead(fs.Arg(1), gocv.IMReadColor)
+	defer b.Close()
+	for i, m := range []gocv.Mat{a, b} {
+		if m.Empty() {
+			fmt.Fprintf(os.Stderr, "no se pudo leer la imagen %s\n", fs.Arg(i))
+			return 1
+		}
+	}
+	if a.Cols() != b.Cols() || a.Rows() != b.Rows() {
+		fmt.Fprintf(os.Stderr, "las imágenes tienen distinto tamaño (%dx%d y %dx%d)\n", a.Cols(), a.Rows(), b.Cols(), b.Rows())
+		return 1
+	}
+
+	det := app.NewDetector(cfg)
+	defer det.Close()
+	changed, score := det.IsNewSlide(a, b)
+	roi := det.ROI.Rect(a.Cols(), a.Rows())
+
+	if *diffPath != "" {
+		if mask, _, ok := det.Diff(); ok && !gocv.IMWrite(*diffPath, mask) {
+			fmt.Fprintf(os.Stderr, "no se pudo guardar %s\n", *diffPath)
+			return 1
+		}
+	}
+	if *asJSON {
+		_ = json.NewEncoder(os.Stdout).Encode(map[string]any{
+			"score":       score,
+			"sensitivity": cfg.Sensitivity,
+			"changed":     changed,
+			"roi":         map[string]int{"x": roi.Min.X, "y": roi.Min.Y, "w": roi.Dx(), "h": roi.Dy()},
+		})
+		return 0
+	}
+	verdict := "sin cambio"
+	if changed {
+		verdict = "diapositiva nueva"
+	}
+	fmt.Printf("score %.4f (umbral %.4f): %s\n", score, cfg.Sensitivity, verdict)
+	fmt.Printf("zona comparada: %v de %dx%d\n", roi, a.Cols(), a.Rows())
+	return 0
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"gocv.io/x/gocv"
+
+	"IA1_EV2025_Proyecto2/internal/app"
+	"IA1_EV2025_Proyecto2/internal/capture"
+	"IA1_EV2025_Proyecto2/internal/config"
+	"IA1_EV2025_Proyecto2/internal/ocr"
+	"IA1_EV2025_Proyecto2/internal/store"
+	"IA1_EV2025_Proyecto2/internal/telegram"
+)
+
+// Lecturas de la cámara antes de darla por muerta (menos que las que hacen
+// falta para que Camera intente reabrirla).
+const doctorCameraReads = 20
+
+// doctor imprime una línea por comprobación y recuerda si alguna falló.
+type doctor struct {
+	failed bool
+}
+
+func (d *doctor) ok(name, format string, args ...any) {
+	fmt.Printf("ok     %-10s %s\n", name, fmt.Sprintf(format, args...))
+}
+
+func (d *doctor) warn(name, format string, args ...any) {
+	fmt.Printf("aviso  %-10s %s\n", name, fmt.Sprintf(format, args...))
+}
+
+func (d *doctor) fail(name, format string, args ...any) {
+	d.failed = true
+	fmt.Printf("FALLA  %-10s %s\n", name, fmt.Sprintf(format, args...))
+}
+
+// runDoctor implementa "smartslide doctor": comprueba lo que run necesita
+// (configuración, carpeta de salida, base, cámara, idiomas de Tesseract, token
+// y destinos de Telegram, endpoint del LLM y dirección del panel). Sale con 1
+// si algo falla.
+func runDoctor(args []string) int {
+	fs := flag.NewFlagSet("doctor", flag.ExitOnError)
+	flags := addConfigFlags(fs)
+	flags.field(fs, "camera", "camera_index", "índice de la cámara")
+	skipCamera := fs.Bool("no-camera", false, "no prueba la cámara (p. ej. si la usa smartslide)")
+	skipTelegram := fs.Bool("no-telegram", false, "no se conecta a Telegram")
+	_ = fs.Parse(args)
+	if fs.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, "uso: smartslide doctor [-config ruta] [-camera N] [-no-camera] [-no-telegram]")
+		return 2
+	}
+	slog.SetLogLoggerLevel(slog.LevelWarn)
+	d := &doctor{}
+
+	l, err := flags.load()
+	if err != nil {
+		d.fail("config", "%s: %v", flags.path, err)
+		return 1
+	}
+	cfg := l.Config
+	if cfg.Profile != "" {
+		d.ok("config", "%s (perfil %s)", flags.path, cfg.Profile)
+	} else {
+		d.ok("config", "%s", flags.path)
+	}
+
+	d.outputDir(cfg)
+	d.database(cfg)
+	if *skipCamera {
+		d.warn("cámara", "no comprobada")
+	} else {
+		d.camera(cfg)
+	}
+	d.tesseract(cfg)
+	if *skipTelegram {
+		d.warn("telegram", "no comprobado")
+	} else {
+		d.telegram(cfg)
+	}
+	d.llm(cfg)
+	d.admin(cfg)
+
+	if d.failed {
+		return 1
+	}
+	return 0
+}
+
+func (d *doctor) outputDir(cfg config.Config) {
+	if err := os.MkdirAll(cfg.OutputDir, 0755); err != nil {
+		d.fail("salida", "%v", err)
+		return
+	}
+	f, err := os.CreateTemp(cfg.OutputDir, ".doctor-*")
+	if err != nil {
+		d.fail("salida", "%s no es escribible: %v", cfg.OutputDir, err)
+		return
+	}
+	f.Close()
+	os.Remove(f.Name())
+	d.ok("salida", "%s", cfg.OutputDir)
+}
+
+func (d *doctor) database(cfg config.Config) {
+	if _, err := os.Stat(cfg.DatabasePath); errors.Is(err, os.ErrNotExist) {
+		d.warn("base", "%s no existe; se crea al iniciar", cfg.DatabasePath)
+		return
+	}
+	db, err := store.OpenReadOnly(cfg.DatabasePath)
+	if errors.Is(err, store.ErrLocked) {
+		d.warn("base", "%s en uso por otro proceso (¿smartslide ya está corriendo?)", cfg.DatabasePath)
+		return
+	}
+	if err != nil {
+		d.fail("base", "%s: %v", cfg.DatabasePath, err)
+		return
+	}
+	defer db.Close()
+	sessions, err := db.Sessions()
+	if err != nil {
+		d.fail("base", "%s: %v", cfg.DatabasePath, err)
+		return
+	}
+	d.ok("base", "%s, %d sesiones", cfg.DatabasePath, len(sessions))
+}
+
+func (d *doctor) camera(cfg config.Config) {
+	cam, err := capture.OpenCamera(cfg.CameraIndex)
+	if err != nil {
+		d.fail("cámara", "%d: %v", cfg.CameraIndex, err)
+		return
+	}
+	defer cam.Close()
+	frame := gocv.NewMat()
+	defer frame.Close()
+	for i := 0; i < doctorCameraReads; i++ {
+		if cam.Read(&frame) {
+			d.ok("cámara", "%d, %dx%d", cfg.CameraIndex, frame.Cols(), frame.Rows())
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	d.fail("cámara", "%d no entrega frames (¿otro programa la usa?)", cfg.CameraIndex)
+}
+
+func (d *doctor) tesseract(cfg config.Config) {
+	missing, err := ocr.MissingLanguages(cfg.TesseractLang)
+	switch {
+	case err != nil:
+		d.fail("tesseract", "%v", err)
+	case len(missing) > 0:
+		d.fail("tesseract", "faltan los idiomas %s (instalar tesseract-ocr-%s)", strings.Join(missing, ", "), missing[0])
+	default:
+		d.ok("tesseract", "idiomas %s", cfg.TesseractLang)
+	}
+	if _, err := app.NewCorrector(cfg); err != nil {
+		d.fail("corrector", "%v", err)
+	}
+}
+
+func (d *doctor) telegram(cfg config.Config) {
+	if cfg.TelegramBotToken == "" {
+		d.fail("telegram", "falta telegram_bot_token")
+		return
+	}
+	bot, err := telegram.NewWithEndpoint(cfg.TelegramBotToken, cfg.TelegramAPIEndpoint)
+	if err != nil {
+		d.fail("telegram", "token rechazado: %v", err)
+		return
+	}
+	d.ok("telegram", "bot @%s", bot.Username())
+	targets := cfg.Targets()
+	if len(targets) == 0 {
+		d.warn("destinos", "no hay chats configurados")
+	}
+	for _, t := range targets {
+		title, err := bot.ChatTitle(t.ChatID)
+		if err != nil {
+			d.fail("destinos", "%s (%d): %v", t.Name, t.ChatID, err)
+			continue
+		}
+		d.ok("destinos", "%s (%d): %s", t.Name, t.ChatID, title)
+	}
+}
+
+func (d *doctor) llm(cfg config.Config) {
+	if cfg.SummarizerBackend != "llm" {
+		return
+	}
+	url := strings.TrimSuffix(strings.TrimRight(cfg.LLMEndpoint, "/"), "/chat/completions") + "/models"
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		d.fail("llm", "%v", err)
+		return
+	}
+	if cfg.LLMAPIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+cfg.LLMAPIKey)
+	}
+	client := &http.Client{Timeout: time.Duration(cfg.LLMTimeoutSeconds) * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		d.fail("llm", "%s: %v", cfg.LLMEndpoint, err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		d.fail("llm", "%s: %s", url, resp.Status)
+		return
+	}
+	d.ok("llm", "%s, modelo %s", cfg.LLMEndpoint, cfg.LLMModel)
+}
+
+func (d *doctor) admin(cfg config.Config) {
+	if cfg.AdminHTTPAddr == "" {
+		return
+	}
+	ln, err := net.Listen("tcp", cfg.AdminHTTPAddr)
+	if err != nil {
+		d.warn("panel", "%s ocupado (¿smartslide ya está corriendo?): %v", cfg.AdminHTTPAddr, err)
+	} else {
+		ln.Close()
+		d.ok("panel", "%s libre", cfg.AdminHTTPAddr)
+	}
+	if cfg.AdminTLS.Enabled {
+		if _, err := os.Stat(cfg.AdminTLS.CertFile); err != nil {
+			d.warn("panel", "sin certificado en %s; se genera uno autofirmado", cfg.AdminTLS.CertFile)
+		}
+	}
+	if len(cfg.AdminAuth.Users) == 0 && len(cfg.AdminAuth.Tokens) == 0 {
+		d.warn("panel", "sin autenticación (ver \"smartslide auth\")")
+	}
+}
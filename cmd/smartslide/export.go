@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"IA1_EV2025_Proyecto2/internal/app"
+	"IA1_EV2025_Proyecto2/internal/store"
+)
+
+const exportUsage = "uso: smartslide export [-config ruta] [-o archivo] [-json] [sesión|last]"
+
+// runExport implementa "smartslide export": las notas en Markdown (o los datos
+// en JSON) de una sesión guardada, es decir de una ejecución de la captura.
+// Sin sesión lista las que hay.
+func runExport(args []string) int {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	flags := addConfigFlags(fs)
+	out := fs.String("o", "", "archivo de salida (vacío = salida estándar)")
+	asJSON := fs.Bool("json", false, "la sesión y sus diapositivas en JSON")
+	_ = fs.Parse(args)
+	if fs.NArg() > 1 {
+		fmt.Fprintln(os.Stderr, exportUsage)
+		return 2
+	}
+	cfg, ok := loadConfig(flags)
+	if !ok {
+		return 1
+	}
+	// si smartslide está corriendo la base está bloqueada: usar el panel
+	db, err := store.OpenReadOnly(cfg.DatabasePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "store: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	sessions, err := db.Sessions()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "store: %v\n", err)
+		return 1
+	}
+	if fs.NArg() == 0 {
+		stats, _ := db.SessionStats()
+		for _, s := range sessions {
+			fmt.Printf("%s  %s  %3d diapositivas  %s\n", s.ID, s.StartedAt.Format("2006-01-02 15:04"), stats[s.ID].Slides, s.Title)
+		}
+		if len(sessions) == 0 {
+			fmt.Fprintln(os.Stderr, "no hay sesiones guardadas")
+		}
+		return 0
+	}
+
+	id := fs.Arg(0)
+	if id == "last" && len(sessions) > 0 {
+		id = sessions[0].ID
+	}
+	sess, ok, err := db.Session(id)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "store: %v\n", err)
+		return 1
+	}
+	if !ok {
+		fmt.Fprintf(os.Stderr, "sesión %q no encontrada (\"smartslide export\" lista las sesiones)\n", id)
+		return 1
+	}
+	slides, err := db.Slides(store.SlideQuery{Session: sess.ID})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "store: %v\n", err)
+		return 1
+	}
+
+	var data []byte
+	if *asJSON {
+		data, _ = json.MarshalIndent(map[string]any{"session": sess, "slides": slides}, "", "  ")
+		data = append(data, '\n')
+	} else {
+		dir := ""
+		if *out != "" {
+			dir = filepath.Dir(*out)
+		}
+		data = []byte(app.SessionNotes(sess, slides, dir))
+	}
+	if *out == "" {
+		_, _ = os.Stdout.Write(data)
+		return 0
+	}
+	if err := os.WriteFile(*out, data, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "export: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "%d diapositivas de %s en %s\n", len(slides), sess.ID, *out)
+	return 0
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"IA1_EV2025_Proyecto2/internal/config"
+)
+
+// configFlags son las opciones de configuración comunes a los subcomandos:
+// -config y -set, que se suman a las variables SMARTSLIDE_* (los flags ganan).
+type configFlags struct {
+	path      string
+	overrides config.Overrides
+	envErr    error
+}
+
+// addConfigFlags lee el entorno y agrega -config y -set a fs.
+func addConfigFlags(fs *flag.FlagSet) *configFlags {
+	o, err := config.FromEnv(os.Environ())
+	f := &configFlags{path: defaultConfigPath(), overrides: o, envErr: err}
+	fs.StringVar(&f.path, "config", f.path, "archivo de configuración (o "+config.EnvConfig+")")
+	fs.Func("set", "campo=valor de la configuración, p. ej. -set roi.x=0.1 (repetible)", func(s string) error {
+		path, value, ok := strings.Cut(s, "=")
+		if !ok {
+			return fmt.Errorf("se espera campo=valor")
+		}
+		return f.overrides.Set(path, value, "flag:-set "+path)
+	})
+	return f
+}
+
+// field agrega a fs un atajo -name para el campo path.
+func (f *configFlags) field(fs *flag.FlagSet, name, path, usage string) {
+	fs.Func(name, usage+" ("+path+")", func(v string) error {
+		return f.overrides.Set(path, v, "flag:-"+name)
+	})
+}
+
+// load carga la configuración con el entorno y los flags. Avisa de las
+// variables SMARTSLIDE_* que no corresponden a ningún campo.
+func (f *configFlags) load() (config.Loaded, error) {
+	if f.envErr != nil {
+		return config.Loaded{}, f.envErr
+	}
+	for _, name := range f.overrides.Unknown() {
+		slog.Warn("unknown environment variable ignored", "name", name)
+	}
+	return config.LoadLayers(f.path, f.overrides)
+}
+
+// envHelp es la nota sobre las variables de entorno para las ayudas.
+var envHelp = fmt.Sprintf("Cada campo se puede fijar también con %s<CAMPO> (p. ej. %sCAMERA_INDEX=1)\ny los secretos con %s<CAMPO>_FILE.\n", config.EnvPrefix, config.EnvPrefix, config.EnvPrefix)
+
+// defaultConfigPath es SMARTSLIDE_CONFIG o config.DefaultPath.
+func defaultConfigPath() string {
+	if p := os.Getenv(config.EnvConfig); p != "" {
+		return p
+	}
+	return config.DefaultPath
+}
+
+// loadConfig carga la configuración; si falla informa el error y devuelve
+// false para que el subcomando termine.
+func loadConfig(f *configFlags) (config.Config, bool) {
+	l, err := f.load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "config: %v\n", err)
+		return config.Config{}, false
+	}
+	return l.Config, true
+}
@@ -1,86 +1,58 @@
 package main
 
 import (
-	"context"
-	"log"
-	"net/http"
+	"fmt"
 	"os"
-	"os/signal"
-	"syscall"
-
-	"IA1_EV2025_Proyecto2/internal/admin"
-	"IA1_EV2025_Proyecto2/internal/app"
-	"IA1_EV2025_Proyecto2/internal/config"
-	"IA1_EV2025_Proyecto2/internal/metrics"
-	"IA1_EV2025_Proyecto2/internal/telegram"
+	"strings"
 )
 
-func main() {
-	cfgPath := "configs/config.json"
-
-	cfg, err := config.Load(cfgPath)
-	if err != nil {
-		log.Fatalf("config: %v", err)
-	}
-
-	// CREAR DIRECTORIOS NECESARIOS
-	if err := os.MkdirAll(cfg.OutputDir, 0755); err != nil {
-		log.Fatalf("no se pudo crear directorio de salida: %v", err)
-	}
-	
-	// Crear también el directorio para métricas
-	metricsDir := cfg.OutputDir
-	if err := os.MkdirAll(metricsDir, 0755); err != nil {
-		log.Fatalf("no se pudo crear directorio de métricas: %v", err)
-	}
-
-	st := app.NewState()
+// command es un subcomando de smartslide.
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) int
+}
 
-	mw, err := metrics.New(cfg.OutputDir + "/metrics.jsonl")
-	if err != nil {
-		log.Fatalf("metrics: %v", err)
-	}
-	defer func() {
-		_ = mw.Close()
-	}()
+// commands en el orden en que los muestra la ayuda. Sin subcomando (o con
+// solo flags) se ejecuta run, como antes de que hubiera subcomandos.
+var commands = []command{
+	{"run", "captura en vivo con el panel y el bot (por defecto)", runLive},
+	{"replay", "procesa un video o una carpeta de imágenes sin cámara", runReplay},
+	{"ocr", "OCR y resumen de una imagen", runOCR},
+	{"detect", "puntaje de cambio entre dos imágenes", runDetect},
+	{"export", "notas en Markdown de una sesión guardada", runExport},
+	{"search", "busca en las diapositivas guardadas", runSearch},
+	{"config", "valida la configuración y muestra de dónde sale cada valor", runConfig},
+	{"schedule", "muestra las próximas clases del horario", runSchedule},
+	{"calibrate", "mide el ruido de la cámara y sugiere sensibilidad y ROI", runCalibrate},
+	{"doctor", "revisa configuración, cámara, Tesseract, Telegram y panel", runDoctor},
+	{"auth", "genera usuarios y tokens para admin_auth", runAuth},
+}
 
-	bot, err := telegram.New(cfg.TelegramBotToken, cfg.TelegramChatID)
-	if err != nil {
-		log.Fatalf("telegram: %v", err)
+func main() {
+	args := os.Args[1:]
+	name := "run"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
 	}
-
-	runner := app.NewRunner(cfgPath, cfg, st, mw, bot)
-
-	adm := &admin.Server{
-		State:  st,
-		GetCfg: func() config.Config { return runner.GetConfig() },
-		SetCfg: func(c config.Config) error { return runner.UpdateConfig(c) },
-		Control: runner.ControlChan(),
+	if name == "help" {
+		usage()
+		return
 	}
-
-	// Admin server
-	go func() {
-		log.Printf("[admin] listening on %s", cfg.AdminHTTPAddr)
-		if err := http.ListenAndServe(cfg.AdminHTTPAddr, adm.Routes()); err != nil {
-			log.Printf("[admin] error: %v", err)
+	for _, c := range commands {
+		if c.name == name {
+			os.Exit(c.run(args))
 		}
-	}()
-
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	// Señales para cerrar ordenado
-	go func() {
-		ch := make(chan os.Signal, 1)
-		signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM)
-		<-ch
-		log.Println("[main] signal received, stopping...")
-		cancel()
-	}()
+	}
+	fmt.Fprintf(os.Stderr, "comando desconocido %q\n\n", name)
+	usage()
+	os.Exit(2)
+}
 
-	log.Println("[main] SmartSlide starting...")
-	if err := runner.Run(ctx); err != nil {
-		log.Fatalf("runner: %v", err)
+func usage() {
+	fmt.Fprintln(os.Stderr, "uso: smartslide [comando] [flags]\n\nComandos:")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", c.name, c.usage)
 	}
-	log.Println("[main] SmartSlide stopped.")
+	fmt.Fprintln(os.Stderr, "\n\"smartslide comando -h\" muestra los flags de cada uno.")
 }
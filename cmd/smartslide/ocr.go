@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"gocv.io/x/gocv"
+
+	"IA1_EV2025_Proyecto2/internal/app"
+	"IA1_EV2025_Proyecto2/internal/ocr"
+)
+
+// runOCR implementa "smartslide ocr [-config ruta] [-lang spa] [-raw] [-json]
+// imagen": el OCR, la corrección y el resumen de la captura en vivo sobre una
+// imagen.
+func runOCR(args []string) int {
+	fs := flag.NewFlagSet("ocr", flag.ExitOnError)
+	flags := addConfigFlags(fs)
+	flags.field(fs, "lang", "tesseract_lang", "idiomas de Tesseract")
+	raw := fs.Bool("raw", false, "solo el texto, sin corrección ni resumen")
+	asJSON := fs.Bool("json", false, "salida en JSON")
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "uso: smartslide ocr [-config ruta] [-lang spa] [-raw] [-json] imagen")
+		return 2
+	}
+	cfg, ok := loadConfig(flags)
+	if !ok {
+		return 1
+	}
+
+	img := gocv.IMRead(fs.Arg(0), gocv.IMReadColor)
+	defer img.Close()
+	if img.Empty() {
+		fmt.Fprintf(os.Stderr, "no se pudo leer la imagen %s\n", fs.Arg(0))
+		return 1
+	}
+	tess, err := ocr.NewClient(cfg.TesseractLang)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tesseract: %v\n", err)
+		return 1
+	}
+	defer tess.Close()
+	text, ms, err := tess.ExtractText(img)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ocr: %v\n", err)
+		return 1
+	}
+	if *raw {
+		fmt.Println(text)
+		return 0
+	}
+
+	corrected := text
+	corrector, err := app.NewCorrector(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "corrector: %v\n", err)
+		return 1
+	}
+	if corrector != nil {
+		corrected = corrector.Correct(text)
+	}
+	summary := app.NewSummarizer(cfg).Summarize(context.Background(), corrected, nil)
+	caption := ocr.BuildCaption(summary, cfg.MaxCaptionChars, 0)
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(map[string]any{
+			"text":      text,
+			"corrected": corrected,
+			"ocr_ms":    ms,
+			"summary":   summary,
+			"regions":   ocr.RegionKinds(summary.Regions),
+			"caption":   caption,
+		})
+		return 0
+	}
+	fmt.Printf("OCR (%d ms, %s):\n%s\n", ms, cfg.TesseractLang, text)
+	if corrected != text {
+		fmt.Printf("\nCorregido:\n%s\n", corrected)
+	}
+	fmt.Printf("\nResumen (%s):\n", cfg.SummarizerBackend)
+	fmt.Printf("  Título: %s\n", summary.Title)
+	for _, b := range summary.Bullets {
+		fmt.Printf("  - %s\n", b)
+	}
+	if len(summary.Keywords) > 0 {
+		fmt.Printf("  Palabras clave: %s\n", strings.Join(summary.Keywords, ", "))
+	}
+	if kinds := ocr.RegionKinds(summary.Regions); len(kinds) > 0 {
+		fmt.Printf("  Regiones: %s\n", strings.Join(kinds, ", "))
+	}
+	fmt.Printf("\nCaption:\n%s\n", caption)
+	return 0
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"gocv.io/x/gocv"
+
+	"IA1_EV2025_Proyecto2/internal/app"
+	"IA1_EV2025_Proyecto2/internal/capture"
+	"IA1_EV2025_Proyecto2/internal/config"
+	"IA1_EV2025_Proyecto2/internal/ocr"
+	"IA1_EV2025_Proyecto2/internal/store"
+)
+
+const replayUsage = "uso: smartslide replay [-config ruta] [-out carpeta] [-ocr=false] [-first] [-json] video|carpeta"
+
+// runReplay implementa "smartslide replay": pasa un video o una carpeta de
+// imágenes por la detección, el OCR y el resumen de la captura en vivo, sin
+// cámara ni Telegram ni base de datos. Las diapositivas y sus notas quedan en
+// -out.
+func runReplay(args []string) int {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	flags := addConfigFlags(fs)
+	flags.field(fs, "sensitivity", "sensitivity", "umbral de cambio")
+	outDir := fs.String("out", "", "carpeta de salida (vacío = replay/ en output_dir)")
+	withOCR := fs.Bool("ocr", true, "OCR y resumen de cada diapositiva")
+	first := fs.Bool("first", false, "procesa también el primer frame (en vivo solo sirve de referencia)")
+	asJSON := fs.Bool("json", false, "una línea JSON por diapositiva")
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, replayUsage)
+		return 2
+	}
+	cfg, ok := loadConfig(flags)
+	if !ok {
+		return 1
+	}
+	if *outDir == "" {
+		*outDir = filepath.Join(cfg.OutputDir, "replay")
+	}
+	if err := os.MkdirAll(*outDir, 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "replay: %v\n", err)
+		return 1
+	}
+
+	src, err := openFrames(fs.Arg(0), cfg.CaptureFPS)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "replay: %v\n", err)
+		return 1
+	}
+	defer src.Close()
+
+	// la pausa mínima se cuenta en tiempo del video, no del reloj
+	det := capture.NewDetector(cfg.Sensitivity, 0)
+	det.ROI = capture.ROI(cfg.ROI)
+	defer det.Close()
+	minGap := time.Duration(cfg.MinSecondsBetweenSlides) * time.Second
+
+	var pipe *replayOCR
+	if *withOCR {
+		if pipe, err = newReplayOCR(cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "replay: %v\n", err)
+			return 1
+		}
+		defer pipe.Close()
+	}
+
+	prev := gocv.NewMat()
+	defer prev.Close()
+	frame := gocv.NewMat()
+	defer frame.Close()
+	var slides []store.Slide
+	read := 0
+	lastAt := -minGap
+	for {
+		at, name, ok := src.Next(&frame)
+		if !ok {
+			break
+		}
+		read++
+		var score float64
+		isSlide := false
+		switch {
+		case prev.Empty():
+			isSlide = *first
+		case src.video && at-lastAt < minGap:
+		default:
+			isSlide, score = det.IsNewSlide(prev, frame)
+		}
+		frame.CopyTo(&prev)
+		if !isSlide {
+			continue
+		}
+		lastAt = at
+
+		sl := store.Slide{
+			ID:         fmt.Sprintf("%03d", len(slides)+1),
+			Session:    "replay",
+			CapturedAt: time.Time{}.Add(at), // tiempo desde el inicio del video
+			Score:      score,
+		}
+		sl.Path = filepath.Join(*outDir, "slide_"+sl.ID+".jpg")
+		if !gocv.IMWrite(sl.Path, frame) {
+			fmt.Fprintf(os.Stderr, "no se pudo guardar %s\n", sl.Path)
+		}
+		build := false
+		if pipe != nil {
+			build = pipe.process(frame, &sl)
+		}
+		slides = append(slides, sl)
+
+		if *asJSON {
+			_ = json.NewEncoder(os.Stdout).Encode(map[string]any{
+				"slide": sl.ID, "at": at.Seconds(), "frame": name, "score": score,
+				"build": build, "title": sl.Summary.Title, "ocr_ms": sl.OCRMillis, "path": sl.Path,
+			})
+			continue
+		}
+		line := fmt.Sprintf("%s  %s  score %.3f  %s", sl.ID, replayTime(at, name, src.video), score, sl.Summary.Title)
+		if build {
+			line += "  (revelación)"
+		}
+		fmt.Println(strings.TrimRight(line, " "))
+	}
+
+	notes := filepath.Join(*outDir, "notes.md")
+	sess := store.Session{ID: "replay", Title: filepath.Base(fs.Arg(0))}
+	if err := os.WriteFile(notes, []byte(app.SessionNotes(sess, slides, *outDir)), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "replay: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "%d frames, %d diapositivas; notas en %s\n", read, len(slides), notes)
+	return 0
+}
+
+func replayTime(at time.Duration, name string, video bool) string {
+	if !video {
+		return name
+	}
+	s := int(at.Seconds())
+	return fmt.Sprintf("%02d:%02d:%02d", s/3600, s/60%60, s%60)
+}
+
+// replayOCR es el OCR, la corrección y el resumen, con el contexto de las
+// diapositivas anteriores como en vivo.
+type replayOCR struct {
+	cfg        config.Config
+	tess       *ocr.Client
+	corrector  *ocr.Corrector
+	summarizer ocr.Summarizer
+	history    []ocr.Summary
+	last       ocr.Summary
+}
+
+func newReplayOCR(cfg config.Config) (*replayOCR, error) {
+	tess, err := ocr.NewClient(cfg.TesseractLang)
+	if err != nil {
+		return nil, err
+	}
+	corrector, err := app.NewCorrector(cfg)
+	if err != nil {
+		tess.Close()
+		return nil, err
+	}
+	return &replayOCR{cfg: cfg, tess: tess, corrector: corrector, summarizer: app.NewSummarizer(cfg)}, nil
+}
+
+func (p *replayOCR) Close() { p.tess.Close() }
+
+// process completa sl con el texto, el resumen y el caption. Devuelve true si
+// es una revelación incremental de la anterior.
+func (p *replayOCR) process(frame gocv.Mat, sl *store.Slide) bool {
+	text, ms, err := p.tess.ExtractText(frame)
+	sl.OCRMillis = ms
+	if err != nil {
+		sl.OCRError = err.Error()
+	}
+	if p.corrector != nil {
+		text = p.corrector.Correct(text)
+	}
+	sl.Summary = p.summarizer.Summarize(context.Background(), text, p.history)
+	sl.Caption = ocr.BuildCaption(sl.Summary, p.cfg.MaxCaptionChars, sl.Score)
+	if n := p.cfg.LLMContextSlides; n > 0 {
+		p.history = append(p.history, sl.Summary)
+		if len(p.history) > n {
+			p.history = p.history[len(p.history)-n:]
+		}
+	}
+	_, build := ocr.DetectBuild(p.last, sl.Summary)
+	p.last = sl.Summary
+	return build
+}
+
+// frameSource entrega los frames de un video, a capture_fps, o las imágenes
+// de una carpeta en orden alfabético.
+type frameSource struct {
+	video bool
+	cap   *gocv.VideoCapture
+	step  int     // frames del video por cada frame entregado
+	fps   float64 // del video
+	pos   int
+
+	files []string
+}
+
+var imageExts = []string{".jpg", ".jpeg", ".png", ".bmp"}
+
+func openFrames(path string, captureFPS int) (*frameSource, error) {
+	st, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if st.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		f := &frameSource{}
+		for _, e := range entries {
+			if !e.IsDir() && slices.Contains(imageExts, strings.ToLower(filepath.Ext(e.Name()))) {
+				f.files = append(f.files, filepath.Join(path, e.Name()))
+			}
+		}
+		if len(f.files) == 0 {
+			return nil, fmt.Errorf("%s: no hay imágenes", path)
+		}
+		return f, nil
+	}
+
+	vc, err := gocv.VideoCaptureFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if !vc.IsOpened() {
+		vc.Close()
+		return nil, fmt.Errorf("%s: no se pudo abrir el video", path)
+	}
+	fps := vc.Get(gocv.VideoCaptureFPS)
+	if fps <= 0 {
+		fps = 25
+	}
+	step := int(math.Round(fps / float64(max(captureFPS, 1))))
+	return &frameSource{video: true, cap: vc, fps: fps, step: max(step, 1)}, nil
+}
+
+// Next lee el próximo frame en m y devuelve su momento y nombre.
+func (f *frameSource) Next(m *gocv.Mat) (time.Duration, string, bool) {
+	if !f.video {
+		for f.pos < len(f.files) {
+			name := f.files[f.pos]
+			f.pos++
+			img := gocv.IMRead(name, gocv.IMReadColor)
+			if img.Empty() {
+				img.Close()
+				fmt.Fprintf(os.Stderr, "no se pudo leer %s\n", name)
+				continue
+			}
+			img.CopyTo(m)
+			img.Close()
+			return time.Duration(f.pos-1) * time.Second, filepath.Base(name), true
+		}
+		return 0, "", false
+	}
+	for {
+		if !f.cap.Read(m) || m.Empty() {
+			return 0, "", false
+		}
+		f.pos++
+		if (f.pos-1)%f.step == 0 {
+			at := time.Duration(float64(f.pos-1) / f.fps * float64(time.Second))
+			return at, fmt.Sprintf("frame %d", f.pos-1), true
+		}
+	}
+}
+
+func (f *frameSource) Close() {
+	if f.cap != nil {
+		f.cap.Close()
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"IA1_EV2025_Proyecto2/internal/admin"
+	"IA1_EV2025_Proyecto2/internal/app"
+	"IA1_EV2025_Proyecto2/internal/auth"
+	"IA1_EV2025_Proyecto2/internal/config"
+	"IA1_EV2025_Proyecto2/internal/logs"
+	"IA1_EV2025_Proyecto2/internal/store"
+	"IA1_EV2025_Proyecto2/internal/telegram"
+)
+
+// runLive implementa "smartslide [run] [flags]": la captura en vivo con el
+// panel y el bot.
+func runLive(args []string) int {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	flags := addConfigFlags(fs)
+	flags.field(fs, "camera", "camera_index", "índice de la cámara")
+	flags.field(fs, "addr", "admin_http_addr", "dirección del panel")
+	flags.field(fs, "output-dir", "output_dir", "carpeta de salida")
+	flags.field(fs, "log-level", "log_level", "nivel de log")
+	fs.Func("telegram-bot-token-file", "archivo con el token del bot (telegram_bot_token)", func(v string) error {
+		return flags.overrides.SetFile("telegram_bot_token", v, "flag:-telegram-bot-token-file")
+	})
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "uso: smartslide [run] [flags]\n\nFlags:\n")
+		fs.PrintDefaults()
+		fmt.Fprint(fs.Output(), "\n"+envHelp)
+	}
+	if err := fs.Parse(args); errors.Is(err, flag.ErrHelp) {
+		return 0
+	} else if err != nil {
+		return 2
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "argumento inesperado %q\n", fs.Arg(0))
+		return 2
+	}
+	cfgPath := flags.path
+	loaded, err := flags.load()
+	if err != nil {
+		fatal(slog.Default(), "config", err)
+	}
+	cfg := loaded.Config
+
+	level, err := logs.ParseLevel(cfg.LogLevel)
+	if err != nil {
+		fatal(slog.Default(), "config: log_level", err)
+	}
+	logBuf := logs.Setup(os.Stderr, level, cfg.LogBufferSize)
+	log := logs.For("main")
+	log.Info("config loaded", "path", cfgPath)
+	for _, field := range loaded.Sources.Overridden() {
+		log.Info("config override", "field", field, "source", loaded.Sources[field])
+	}
+
+	// CREAR DIRECTORIOS NECESARIOS
+	if err := os.MkdirAll(cfg.OutputDir, 0755); err != nil {
+		fatal(log, "no se pudo crear directorio de salida", err)
+	}
+
+	// Crear también el directorio para métricas
+	metricsDir := cfg.OutputDir
+	if err := os.MkdirAll(metricsDir, 0755); err != nil {
+		fatal(log, "no se pudo crear directorio de métricas", err)
+	}
+
+	st := app.NewState()
+
+	db, err := store.Open(cfg.DatabasePath)
+	if err != nil {
+		fatal(log, "store", err)
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+
+	// métricas de versiones anteriores (se importan una sola vez)
+	legacy := filepath.Join(cfg.OutputDir, "metrics.jsonl")
+	if _, err := os.Stat(legacy); err == nil {
+		stats, err := db.ImportMetricsFile(legacy)
+		if err != nil {
+			log.Error("import legacy metrics", "path", legacy, "err", err)
+		} else if !stats.Skipped {
+			log.Info("imported legacy metrics", "path", legacy, "metrics", stats.Metrics, "slides", stats.Slides, "sessions", stats.Sessions)
+		}
+	}
+
+	bot, err := telegram.NewWithEndpoint(cfg.TelegramBotToken, cfg.TelegramAPIEndpoint)
+	if err != nil {
+		fatal(log, "telegram", err)
+	}
+
+	idx, err := app.LoadIndex(db)
+	if err != nil {
+		fatal(log, "search", err)
+	}
+
+	runner := app.NewRunner(cfgPath, flags.overrides, loaded, st, db, bot)
+	runner.Index = idx
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sup := app.NewSupervisor(ctx, runner)
+
+	adm := &admin.Server{
+		State:   st,
+		GetCfg:  func() config.Config { return runner.GetConfig() },
+		Sources: runner.ConfigSources,
+		SetCfg:  runner.UpdateConfig,
+		Control: sup,
+		Index:   idx,
+		Store:   db,
+		Logs:    logBuf,
+		Events:  runner.Events,
+		Preview: runner.Preview,
+		Gallery: runner,
+		Auth:    auth.New(func() config.AdminAuth { return runner.GetConfig().AdminAuth }),
+		History: runner.History,
+		Log:     logs.For("admin"),
+	}
+	if !adm.Auth.Enabled() {
+		adm.Log.Warn("admin API without authentication; add admin_auth users or tokens")
+	}
+	if cfg.AdminTLS.Enabled {
+		fp, created, err := admin.EnsureCertificate(cfg.AdminTLS.CertFile, cfg.AdminTLS.KeyFile, cfg.AdminTLS.Hosts)
+		if err != nil {
+			fatal(log, "admin tls", err)
+		}
+		adm.Log.Info("tls certificate", "cert", cfg.AdminTLS.CertFile, "sha256", fp, "generated", created)
+	}
+
+	// Admin server
+	go func() {
+		var err error
+		if cfg.AdminTLS.Enabled {
+			adm.Log.Info("listening", "addr", cfg.AdminHTTPAddr, "tls", true)
+			err = http.ListenAndServeTLS(cfg.AdminHTTPAddr, cfg.AdminTLS.CertFile, cfg.AdminTLS.KeyFile, adm.Routes())
+		} else {
+			adm.Log.Info("listening", "addr", cfg.AdminHTTPAddr)
+			err = http.ListenAndServe(cfg.AdminHTTPAddr, adm.Routes())
+		}
+		if err != nil {
+			adm.Log.Error("server stopped", "err", err)
+		}
+	}()
+
+	// Señales para cerrar ordenado
+	go func() {
+		ch := make(chan os.Signal, 1)
+		signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM)
+		<-ch
+		log.Info("signal received, stopping...")
+		cancel()
+	}()
+
+	// SIGHUP o un cambio en el archivo recargan la configuración
+	go func() {
+		ch := make(chan os.Signal, 1)
+		signal.Notify(ch, syscall.SIGHUP)
+		for range ch {
+			log.Info("SIGHUP received, reloading config")
+			if err := runner.Reload(); err != nil {
+				log.Error("reload config", "err", err)
+			}
+		}
+	}()
+	go runner.WatchConfig(ctx)
+
+	// Comandos del bot
+	if len(cfg.TelegramAllowedUsers) > 0 {
+		go bot.Listen(ctx, cfg.TelegramAllowedUsers, runner.HandleCommand)
+	}
+
+	// sin horario la captura arranca sola; /control/stop la detiene sin
+	// cerrar el proceso
+	log.Info("SmartSlide starting...")
+	go app.NewScheduler(sup).Run(ctx)
+	if len(cfg.Schedule) > 0 || cfg.ScheduleICS != "" {
+		log.Info("capture follows the schedule", "classes", len(cfg.Schedule), "ics", cfg.ScheduleICS)
+	} else if _, err := sup.Start(); err != nil {
+		log.Error("start capture", "err", err)
+	}
+	<-ctx.Done()
+	sup.Shutdown()
+	log.Info("SmartSlide stopped.")
+	return 0
+}
+
+func fatal(log *slog.Logger, msg string, err error) {
+	log.Error(msg, "err", err)
+	os.Exit(1)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"IA1_EV2025_Proyecto2/internal/app"
+)
+
+// runSchedule implementa "smartslide schedule": lee el horario de la
+// configuración y el .ics (o el de -ics, para probar uno antes de
+// configurarlo) y lista las clases de los próximos días.
+func runSchedule(args []string) int {
+	fs := flag.NewFlagSet("schedule", flag.ExitOnError)
+	flags := addConfigFlags(fs)
+	flags.field(fs, "ics", "schedule_ics", "calendario .ics")
+	days := fs.Int("days", 7, "días que se muestran")
+	asJSON := fs.Bool("json", false, "las clases en JSON")
+	_ = fs.Parse(args)
+	if fs.NArg() > 0 || *days < 1 {
+		fmt.Fprintln(os.Stderr, "uso: smartslide schedule [-config ruta] [-ics archivo.ics] [-days 7] [-json]")
+		return 2
+	}
+	cfg, ok := loadConfig(flags)
+	if !ok {
+		return 1
+	}
+	sched, skipped, err := app.LoadSchedule(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "schedule: %v\n", err)
+		return 1
+	}
+	for _, err := range skipped {
+		fmt.Fprintf(os.Stderr, "aviso: %v\n", err)
+	}
+
+	now := time.Now()
+	slots := sched.Slots(now, now.AddDate(0, 0, *days))
+	if *asJSON {
+		data, _ := json.MarshalIndent(slots, "", "  ")
+		fmt.Println(string(data))
+		return 0
+	}
+	if sched.Empty() {
+		fmt.Fprintln(os.Stderr, "no hay horario: agregar schedule o schedule_ics a la configuración")
+		return 0
+	}
+	fmt.Printf("%d clases semanales, %d eventos del calendario\n", len(sched.Weekly), len(sched.Events))
+	if len(slots) == 0 {
+		fmt.Printf("ninguna clase en los próximos %d días\n", *days)
+		return 0
+	}
+	fmt.Print(app.FormatSlots(slots, now))
+	return 0
+}
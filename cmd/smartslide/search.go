@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"IA1_EV2025_Proyecto2/internal/app"
+	"IA1_EV2025_Proyecto2/internal/search"
+	"IA1_EV2025_Proyecto2/internal/store"
+)
+
+// runSearch implementa "smartslide search [-config ruta] [-limit N] [-json] término...".
+func runSearch(args []string) int {
+	fs := flag.NewFlagSet("search", flag.ExitOnError)
+	flags := addConfigFlags(fs)
+	limit := fs.Int("limit", 10, "máximo de resultados")
+	asJSON := fs.Bool("json", false, "salida en JSON")
+	_ = fs.Parse(args)
+
+	query := strings.Join(fs.Args(), " ")
+	if query == "" {
+		fmt.Fprintln(os.Stderr, "uso: smartslide search [-config ruta] [-limit N] [-json] término...")
+		return 2
+	}
+
+	cfg, ok := loadConfig(flags)
+	if !ok {
+		return 1
+	}
+	// si smartslide está corriendo la base está bloqueada: usar GET /search
+	db, err := store.OpenReadOnly(cfg.DatabasePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "store: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+	idx, err := app.LoadIndex(db)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "search: %v\n", err)
+		return 1
+	}
+
+	results := idx.Search(query, *limit)
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(results)
+		return 0
+	}
+	if len(results) == 0 {
+		fmt.Printf("Sin resultados para %q.\n", query)
+		return 0
+	}
+	for _, res := range results {
+		d := res.Doc
+		fmt.Printf("%s  %s  %s\n", d.ID, d.CapturedAt.Format("2006-01-02 15:04"), d.SessionTitle)
+		for _, h := range res.Highlights {
+			fmt.Printf("    %s\n", search.PlainHighlight(h, "\x1b[1m", "\x1b[0m"))
+		}
+		thumb := d.Thumb
+		if thumb == "" {
+			thumb = d.Path
+		}
+		fmt.Printf("    %s\n", thumb)
+	}
+	return 0
+}
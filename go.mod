@@ -5,5 +5,8 @@ go 1.22
 require (
 	github.com/go-telegram-bot-api/telegram-bot-api/v5 v5.5.1
 	github.com/otiai10/gosseract/v2 v2.4.1
+	go.etcd.io/bbolt v1.3.10
 	gocv.io/x/gocv v0.37.0
 )
+
+require golang.org/x/sys v0.9.0 // indirect
@@ -0,0 +1,155 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"IA1_EV2025_Proyecto2/internal/auth"
+)
+
+func (s *Server) authRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /auth/login", s.handleLogin)
+	mux.HandleFunc("POST /auth/logout", s.handleLogout)
+	mux.HandleFunc("GET /auth/me", s.handleMe)
+}
+
+// handleLogin atiende POST /auth/login con {"username", "password"} y devuelve
+// un token de sesión para usar como "Authorization: Bearer".
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	if s.Auth == nil || !s.Auth.Enabled() {
+		http.Error(w, "autenticación desactivada", http.StatusNotFound)
+		return
+	}
+	var body struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	token, id, expires, err := s.Auth.Login(body.Username, body.Password)
+	if errors.Is(err, auth.ErrInvalidCredentials) {
+		s.logger().Warn("login failed", "user", body.Username, "remote", r.RemoteAddr)
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.logger().Info("login", "user", id.Name, "role", id.Role, "remote", r.RemoteAddr)
+	writeJSON(w, map[string]any{"token": token, "expires": expires, "user": id})
+}
+
+// handleLogout atiende POST /auth/logout: cierra la sesión del Bearer.
+func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
+	if s.Auth != nil {
+		if h := r.Header.Get("Authorization"); len(h) > 7 && strings.EqualFold(h[:7], "bearer ") {
+			s.Auth.Logout(strings.TrimSpace(h[7:]))
+		}
+	}
+	writeJSON(w, map[string]any{"ok": true})
+}
+
+// handleMe atiende GET /auth/me: quién es el cliente y si hay autenticación.
+func (s *Server) handleMe(w http.ResponseWriter, r *http.Request) {
+	id, _ := auth.FromContext(r.Context())
+	writeJSON(w, map[string]any{"auth": s.authEnabled(), "user": id})
+}
+
+func (s *Server) authEnabled() bool {
+	return s.Auth != nil && s.Auth.Enabled()
+}
+
+// requiredRole es el rol que pide la petición: admin para todo lo que modifica
+// algo y para /control/* (también acepta GET); read para el resto.
+func requiredRole(r *http.Request) auth.Role {
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/control/"):
+		return auth.RoleAdmin
+	case strings.HasPrefix(r.URL.Path, "/auth/"):
+		return auth.RoleRead
+	case r.Method == http.MethodGet, r.Method == http.MethodHead:
+		return auth.RoleRead
+	}
+	return auth.RoleAdmin
+}
+
+// requireAuth exige credenciales con el rol de requiredRole. Sin credenciales
+// configuradas deja pasar todo como admin.
+func (s *Server) requireAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.authEnabled() {
+			ctx := auth.WithIdentity(r.Context(), auth.Identity{Role: auth.RoleAdmin, Method: "none"})
+			next.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
+		if r.URL.Path == "/auth/login" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		id, ok := s.Auth.Request(r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="smartslide"`)
+			http.Error(w, "credenciales requeridas", http.StatusUnauthorized)
+			return
+		}
+		if need := requiredRole(r); !id.Role.Allows(need) {
+			s.logger().Warn("forbidden", "user", id.Name, "role", id.Role, "method", r.Method, "path", r.URL.Path)
+			http.Error(w, "se requiere rol "+string(need), http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(auth.WithIdentity(r.Context(), id)))
+	})
+}
+
+// cors responde a los orígenes de admin_cors_origins. Las peticiones que
+// modifican algo desde otro origen no permitido se rechazan aunque el
+// navegador no haga preflight (formularios), para que una página cualquiera
+// de la red no pueda usar la sesión Basic guardada en el navegador.
+func (s *Server) cors(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Origin")
+		origin := r.Header.Get("Origin")
+		allowed := origin == "" || sameOrigin(r, origin)
+		if origin != "" && !allowed {
+			for _, o := range s.GetCfg().AdminCORSOrigins {
+				if o == "*" || strings.EqualFold(strings.TrimSuffix(o, "/"), origin) {
+					allowed = true
+					break
+				}
+			}
+			if allowed {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+				w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+				w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Last-Event-ID")
+				w.Header().Set("Access-Control-Max-Age", "600")
+			}
+		}
+
+		if r.Method == http.MethodOptions {
+			if !allowed {
+				http.Error(w, "origen no permitido", http.StatusForbidden)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		if !allowed && r.Method != http.MethodGet && r.Method != http.MethodHead {
+			s.logger().Warn("origin refused", "origin", origin, "method", r.Method, "path", r.URL.Path)
+			http.Error(w, "origen no permitido", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func sameOrigin(r *http.Request, origin string) bool {
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
@@ -0,0 +1,104 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"IA1_EV2025_Proyecto2/internal/auth"
+	"IA1_EV2025_Proyecto2/internal/config"
+)
+
+// maxConfigBody limita el cuerpo de POST y PATCH /config.
+const maxConfigBody = 1 << 20
+
+// handleConfig atiende /config: GET devuelve la configuración sin secretos,
+// POST la reemplaza entera y PATCH aplica un JSON merge patch (RFC 7396). En
+// POST y PATCH los secretos que llegan como config.RedactedValue conservan su
+// valor; si la configuración no es válida responde 422 con los errores por
+// campo.
+func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, s.GetCfg().Redacted())
+	case http.MethodPost:
+		var c config.Config
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxConfigBody)).Decode(&c); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		c.RestoreSecrets(s.GetCfg())
+		if err := s.SetCfg(c, source(r)); err != nil {
+			writeConfigError(w, err)
+			return
+		}
+		writeJSON(w, map[string]any{"ok": true})
+	case http.MethodPatch:
+		patch, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxConfigBody))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		cur := s.GetCfg()
+		c, err := config.MergePatch(cur, patch)
+		if err != nil {
+			writeConfigError(w, err)
+			return
+		}
+		c.RestoreSecrets(cur)
+		if err := s.SetCfg(c, source(r)); err != nil {
+			writeConfigError(w, err)
+			return
+		}
+		writeJSON(w, s.GetCfg().Redacted())
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleConfigSchema atiende GET /config/schema: el JSON Schema de la
+// configuración para armar los formularios del panel.
+func (s *Server) handleConfigSchema(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Content-Type", "application/schema+json")
+	_ = json.NewEncoder(w).Encode(config.Schema())
+}
+
+// handleConfigSources atiende GET /config/sources: de dónde sale cada valor
+// ("default", "file", "env:SMARTSLIDE_...", "flag:-...") y la lista de los
+// fijados por entorno o flags, que no se pueden cambiar desde el panel.
+func (s *Server) handleConfigSources(w http.ResponseWriter, r *http.Request) {
+	if s.Sources == nil {
+		http.Error(w, "no disponible", http.StatusNotFound)
+		return
+	}
+	src := s.Sources()
+	overridden := src.Overridden()
+	if overridden == nil {
+		overridden = []string{}
+	}
+	writeJSON(w, map[string]any{"sources": src, "overridden": overridden})
+}
+
+// writeConfigError responde 422 con {"error", "fields"} si la configuración no
+// es válida y 400 con el texto del error en otro caso.
+func writeConfigError(w http.ResponseWriter, err error) {
+	var verr config.ValidationError
+	if !errors.As(err, &verr) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	_ = json.NewEncoder(w).Encode(map[string]any{"error": "config inválida", "fields": verr})
+}
+
+// source es el origen de un cambio para el historial: "panel" o
+// "panel (usuario)" con autenticación.
+func source(r *http.Request) string {
+	if id, ok := auth.FromContext(r.Context()); ok && id.Name != "" {
+		return "panel (" + id.Name + ")"
+	}
+	return "panel"
+}
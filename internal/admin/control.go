@@ -0,0 +1,43 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"IA1_EV2025_Proyecto2/internal/app"
+)
+
+func (s *Server) controlRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /control/start", s.control((*app.Supervisor).Start))
+	mux.HandleFunc("POST /control/pause", s.control((*app.Supervisor).Pause))
+	mux.HandleFunc("POST /control/stop", s.control((*app.Supervisor).Stop))
+}
+
+// control responde con el cambio de estado hecho ({"ok", "from", "to",
+// "session"}), 409 con el estado actual si no está permitido o 503 si el
+// proceso se está cerrando. No espera a que la sesión arranque o termine: eso
+// llega por /status y /events.
+func (s *Server) control(op func(*app.Supervisor) (app.Transition, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.Control == nil {
+			http.Error(w, "control no disponible", http.StatusServiceUnavailable)
+			return
+		}
+		t, err := op(s.Control)
+		var terr *app.TransitionError
+		switch {
+		case errors.As(err, &terr):
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusConflict)
+			_ = json.NewEncoder(w).Encode(map[string]any{"ok": false, "error": err.Error(), "status": terr.From})
+		case errors.Is(err, app.ErrShuttingDown):
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		default:
+			s.Log.Info("control", "from", string(t.From), "to", string(t.To), "session", t.Session)
+			writeJSON(w, map[string]any{"ok": true, "from": t.From, "to": t.To, "session": t.Session})
+		}
+	}
+}
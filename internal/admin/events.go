@@ -0,0 +1,74 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"IA1_EV2025_Proyecto2/internal/events"
+)
+
+// handleEvents atiende GET /events?types=frame,slide_sent (vacío = todos): un
+// evento SSE por cada evento del pipeline, con el tipo como nombre del evento
+// y el ID del bus como id. Al reconectar con Last-Event-ID se reenvían los
+// eventos guardados posteriores (los frames no se guardan).
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if s.Events == nil {
+		http.Error(w, "eventos desactivados", http.StatusNotFound)
+		return
+	}
+	var types []events.Type
+	for _, t := range strings.Split(r.URL.Query().Get("types"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			types = append(types, events.Type(t))
+		}
+	}
+	wanted := map[events.Type]bool{}
+	for _, t := range types {
+		wanted[t] = true
+	}
+
+	ch, cancel := s.Events.Subscribe(types...)
+	defer cancel()
+
+	stream, err := newSSE(w)
+	if err != nil {
+		return
+	}
+	var last int64
+	if id, err := strconv.ParseInt(r.Header.Get("Last-Event-ID"), 10, 64); err == nil {
+		for _, e := range s.Events.Since(id) {
+			if len(wanted) > 0 && !wanted[e.Type] {
+				continue
+			}
+			if stream.send(strconv.FormatInt(e.ID, 10), string(e.Type), e) != nil {
+				return
+			}
+			last = e.ID
+		}
+	}
+
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e, ok := <-ch:
+			if !ok {
+				return
+			}
+			if e.ID <= last {
+				continue
+			}
+			if stream.send(strconv.FormatInt(e.ID, 10), string(e.Type), e) != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if stream.ping() != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,326 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"IA1_EV2025_Proyecto2/internal/app"
+	"IA1_EV2025_Proyecto2/internal/ocr"
+	"IA1_EV2025_Proyecto2/internal/paths"
+	"IA1_EV2025_Proyecto2/internal/store"
+)
+
+// Gallery son las operaciones sobre diapositivas que necesitan al Runner.
+type Gallery interface {
+	DeleteSlide(id string) error
+	ResendSlide(id string, chatIDs []int64) (map[int64]int, error)
+	Thumbnail(id string) (string, error)
+}
+
+// defaultGalleryPage es el tamaño de página de la galería sin limit.
+const defaultGalleryPage = 50
+
+type sessionItem struct {
+	store.Session
+	Slides    int    `json:"slides"`
+	Thumbnail string `json:"thumbnail,omitempty"` // de la última diapositiva
+}
+
+// slideItem es una diapositiva en los listados; el detalle está en slideDetail.
+type slideItem struct {
+	ID         string    `json:"id"`
+	Session    string    `json:"session"`
+	CapturedAt time.Time `json:"captured_at"`
+	Title      string    `json:"title"`
+	Score      float64   `json:"score"`
+	Builds     int       `json:"builds,omitempty"`
+	Sent       int       `json:"sent"`             // chats con mensaje
+	Errors     int       `json:"errors,omitempty"` // chats con error
+	Image      string    `json:"image"`
+	Raw        string    `json:"raw"`
+	Thumbnail  string    `json:"thumbnail"`
+}
+
+type slideDetail struct {
+	slideItem
+	Summary     ocr.Summary              `json:"summary"`
+	OCRText     string                   `json:"ocr_text"`
+	OCRMillis   int64                    `json:"ocr_ms"`
+	OCRError    string                   `json:"ocr_error,omitempty"`
+	Caption     string                   `json:"caption"`
+	Attachments []string                 `json:"attachments,omitempty"` // nombres de archivo
+	Delivery    map[int64]store.Delivery `json:"delivery"`
+}
+
+func (s *Server) galleryRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /gallery/sessions", s.handleGallerySessions)
+	mux.HandleFunc("GET /gallery/sessions/{id}", s.handleGallerySession)
+	mux.HandleFunc("GET /gallery/slides/{id}", s.handleGallerySlide)
+	mux.HandleFunc("GET /gallery/slides/{id}/{variant}", s.handleGalleryImage)
+	mux.HandleFunc("DELETE /gallery/slides/{id}", s.handleGalleryDelete)
+	mux.HandleFunc("POST /gallery/slides/{id}/resend", s.handleGalleryResend)
+}
+
+// handleGallerySessions atiende GET /gallery/sessions?offset=&limit=, la más
+// reciente primero.
+func (s *Server) handleGallerySessions(w http.ResponseWriter, r *http.Request) {
+	offset, limit := pageParams(r)
+	sessions, err := s.Store.Sessions()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	stats, err := s.Store.SessionStats()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	items := []sessionItem{}
+	for _, sess := range page(sessions, offset, limit) {
+		it := sessionItem{Session: sess, Slides: stats[sess.ID].Slides}
+		if last := stats[sess.ID].LastSlide; last != "" {
+			it.Thumbnail = slideURL(last, "thumb")
+		}
+		items = append(items, it)
+	}
+	writeJSON(w, map[string]any{
+		"total":    len(sessions),
+		"offset":   offset,
+		"limit":    limit,
+		"sessions": items,
+	})
+}
+
+// handleGallerySession atiende GET /gallery/sessions/{id}?offset=&limit=: la
+// sesión y sus diapositivas en orden cronológico.
+func (s *Server) handleGallerySession(w http.ResponseWriter, r *http.Request) {
+	offset, limit := pageParams(r)
+	sess, ok, err := s.Store.Session(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		http.Error(w, "sesión no encontrada", http.StatusNotFound)
+		return
+	}
+	slides, err := s.Store.Slides(store.SlideQuery{Session: sess.ID})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	items := []slideItem{}
+	for _, sl := range page(slides, offset, limit) {
+		items = append(items, newSlideItem(sl))
+	}
+	writeJSON(w, map[string]any{
+		"session": sess,
+		"total":   len(slides),
+		"offset":  offset,
+		"limit":   limit,
+		"slides":  items,
+	})
+}
+
+// handleGallerySlide atiende GET /gallery/slides/{id}: resumen, texto OCR,
+// caption y estado de envío.
+func (s *Server) handleGallerySlide(w http.ResponseWriter, r *http.Request) {
+	sl, ok := s.slide(w, r)
+	if !ok {
+		return
+	}
+	d := slideDetail{
+		slideItem: newSlideItem(sl),
+		Summary:   sl.Summary,
+		OCRText:   sl.Summary.RawText,
+		OCRMillis: sl.OCRMillis,
+		OCRError:  sl.OCRError,
+		Caption:   sl.Caption,
+		Delivery:  sl.Delivery,
+	}
+	if d.Delivery == nil {
+		d.Delivery = map[int64]store.Delivery{}
+	}
+	for _, a := range sl.Attachments {
+		d.Attachments = append(d.Attachments, filepath.Base(a))
+	}
+	writeJSON(w, d)
+}
+
+// handleGalleryImage atiende GET /gallery/slides/{id}/{image|raw|thumb}. Las
+// rutas salen de la base y se sirven solo si están dentro de output_dir.
+func (s *Server) handleGalleryImage(w http.ResponseWriter, r *http.Request) {
+	sl, ok := s.slide(w, r)
+	if !ok {
+		return
+	}
+	var path string
+	switch r.PathValue("variant") {
+	case "image":
+		path = sl.Path
+	case "raw":
+		path = sl.RawPath
+	case "thumb":
+		if s.Gallery == nil {
+			path = sl.Thumb
+			break
+		}
+		p, err := s.Gallery.Thumbnail(sl.ID)
+		if err != nil {
+			s.logger().Warn("thumbnail", "slide", sl.ID, "err", err)
+			http.NotFound(w, r)
+			return
+		}
+		path = p
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	s.serveOutputFile(w, r, path)
+}
+
+// handleGalleryDelete atiende DELETE /gallery/slides/{id}.
+func (s *Server) handleGalleryDelete(w http.ResponseWriter, r *http.Request) {
+	if s.Gallery == nil {
+		http.Error(w, "galería de solo lectura", http.StatusNotImplemented)
+		return
+	}
+	err := s.Gallery.DeleteSlide(r.PathValue("id"))
+	if errors.Is(err, app.ErrSlideNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, map[string]any{"ok": true})
+}
+
+// handleGalleryResend atiende POST /gallery/slides/{id}/resend con
+// {"chat_ids": [...]} opcional (vacío = todos los destinos).
+func (s *Server) handleGalleryResend(w http.ResponseWriter, r *http.Request) {
+	if s.Gallery == nil {
+		http.Error(w, "galería de solo lectura", http.StatusNotImplemented)
+		return
+	}
+	var body struct {
+		ChatIDs []int64 `json:"chat_ids"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if v := r.URL.Query().Get("chat_id"); v != "" {
+		chat, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			http.Error(w, "chat_id inválido", http.StatusBadRequest)
+			return
+		}
+		body.ChatIDs = append(body.ChatIDs, chat)
+	}
+
+	sent, err := s.Gallery.ResendSlide(r.PathValue("id"), body.ChatIDs)
+	switch {
+	case errors.Is(err, app.ErrSlideNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case err != nil && len(sent) == 0:
+		http.Error(w, err.Error(), http.StatusBadGateway)
+	case err != nil:
+		// envío parcial
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusMultiStatus)
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": false, "messages": sent, "error": err.Error()})
+	default:
+		writeJSON(w, map[string]any{"ok": true, "messages": sent})
+	}
+}
+
+func (s *Server) slide(w http.ResponseWriter, r *http.Request) (store.Slide, bool) {
+	sl, ok, err := s.Store.Slide(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return sl, false
+	}
+	if !ok {
+		http.Error(w, app.ErrSlideNotFound.Error(), http.StatusNotFound)
+		return sl, false
+	}
+	return sl, true
+}
+
+// serveOutputFile sirve path solo si está dentro de output_dir.
+func (s *Server) serveOutputFile(w http.ResponseWriter, r *http.Request, path string) {
+	p, err := paths.Within(s.GetCfg().OutputDir, path)
+	if errors.Is(err, paths.ErrOutside) {
+		s.logger().Warn("refused file outside output_dir", "path", path)
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	if _, err := os.Stat(p); err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Cache-Control", "private, max-age=3600")
+	http.ServeFile(w, r, p)
+}
+
+func newSlideItem(sl store.Slide) slideItem {
+	it := slideItem{
+		ID:         sl.ID,
+		Session:    sl.Session,
+		CapturedAt: sl.CapturedAt,
+		Title:      sl.Summary.Title,
+		Score:      sl.Score,
+		Builds:     sl.Builds,
+		Image:      slideURL(sl.ID, "image"),
+		Raw:        slideURL(sl.ID, "raw"),
+		Thumbnail:  slideURL(sl.ID, "thumb"),
+	}
+	for _, d := range sl.Delivery {
+		if d.MessageID != 0 {
+			it.Sent++
+		}
+		if d.Error != "" {
+			it.Errors++
+		}
+	}
+	return it
+}
+
+func slideURL(id, variant string) string {
+	return "/gallery/slides/" + url.PathEscape(id) + "/" + variant
+}
+
+// pageParams lee offset y limit (por defecto defaultGalleryPage, máximo maxPageSize).
+func pageParams(r *http.Request) (offset, limit int) {
+	offset, _ = strconv.Atoi(r.URL.Query().Get("offset"))
+	limit, _ = strconv.Atoi(r.URL.Query().Get("limit"))
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 || limit > maxPageSize {
+		limit = defaultGalleryPage
+	}
+	return offset, limit
+}
+
+func page[T any](items []T, offset, limit int) []T {
+	if offset >= len(items) {
+		return nil
+	}
+	return items[offset:min(offset+limit, len(items))]
+}
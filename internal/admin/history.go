@@ -0,0 +1,115 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"IA1_EV2025_Proyecto2/internal/config"
+)
+
+func (s *Server) historyRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /config/history", s.handleHistory)
+	mux.HandleFunc("GET /config/history/{version}", s.handleHistoryVersion)
+	mux.HandleFunc("GET /config/history/{version}/diff", s.handleHistoryDiff)
+	mux.HandleFunc("POST /config/history/{version}/rollback", s.handleHistoryRollback)
+}
+
+// handleHistory atiende GET /config/history?offset=&limit=: las versiones
+// guardadas, la más reciente primero.
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	if s.History == nil {
+		http.Error(w, "historial desactivado", http.StatusNotFound)
+		return
+	}
+	offset, limit := pageParams(r)
+	versions, err := s.History.List()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	items := page(versions, offset, limit)
+	if items == nil {
+		items = []config.Version{}
+	}
+	writeJSON(w, map[string]any{
+		"total":    len(versions),
+		"offset":   offset,
+		"limit":    limit,
+		"versions": items,
+	})
+}
+
+// handleHistoryVersion atiende GET /config/history/{version}: la versión con
+// su configuración sin secretos.
+func (s *Server) handleHistoryVersion(w http.ResponseWriter, r *http.Request) {
+	v, ok := s.version(w, r.PathValue("version"))
+	if !ok {
+		return
+	}
+	c := v.Config.Redacted()
+	v.Config = &c
+	writeJSON(w, v)
+}
+
+// handleHistoryDiff atiende GET /config/history/{version}/diff?to=N: qué
+// cambia de la versión a la versión N, o a la configuración actual sin to.
+func (s *Server) handleHistoryDiff(w http.ResponseWriter, r *http.Request) {
+	from, ok := s.version(w, r.PathValue("version"))
+	if !ok {
+		return
+	}
+	to := s.GetCfg()
+	toVersion := 0
+	if q := r.URL.Query().Get("to"); q != "" {
+		v, ok := s.version(w, q)
+		if !ok {
+			return
+		}
+		to, toVersion = *v.Config, v.Version
+	}
+	changes := config.Diff(*from.Config, to)
+	if changes == nil {
+		changes = []config.Change{}
+	}
+	writeJSON(w, map[string]any{"from": from.Version, "to": toVersion, "changes": changes})
+}
+
+// handleHistoryRollback atiende POST /config/history/{version}/rollback: vuelve
+// a aplicar esa versión entera, lo que a su vez queda como una versión nueva.
+func (s *Server) handleHistoryRollback(w http.ResponseWriter, r *http.Request) {
+	v, ok := s.version(w, r.PathValue("version"))
+	if !ok {
+		return
+	}
+	src := source(r) + ", rollback a v" + strconv.Itoa(v.Version)
+	if err := s.SetCfg(*v.Config, src); err != nil {
+		writeConfigError(w, err)
+		return
+	}
+	s.logger().Info("config rolled back", "version", v.Version, "source", source(r))
+	writeJSON(w, s.GetCfg().Redacted())
+}
+
+// version busca la versión del parámetro y responde el error si no puede.
+func (s *Server) version(w http.ResponseWriter, param string) (config.Version, bool) {
+	if s.History == nil {
+		http.Error(w, "historial desactivado", http.StatusNotFound)
+		return config.Version{}, false
+	}
+	n, err := strconv.Atoi(param)
+	if err != nil || n <= 0 {
+		http.Error(w, "versión inválida", http.StatusBadRequest)
+		return config.Version{}, false
+	}
+	v, err := s.History.Get(n)
+	if errors.Is(err, config.ErrVersionNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return config.Version{}, false
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return config.Version{}, false
+	}
+	return v, true
+}
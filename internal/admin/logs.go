@@ -0,0 +1,192 @@
+package admin
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"IA1_EV2025_Proyecto2/internal/logs"
+)
+
+// defaultLogPage es la cantidad de registros de /logs sin limit.
+const defaultLogPage = 200
+
+// handleLogs atiende GET /logs?level=WARN&source=telegram&q=texto&before=ID&limit=N,
+// el más reciente primero; la página siguiente se pide con before = el último
+// ID recibido y lo nuevo con after = el primero. DELETE vacía el buffer.
+func (s *Server) handleLogs(w http.ResponseWriter, r *http.Request) {
+	if s.Logs == nil {
+		http.Error(w, "logs desactivados", http.StatusNotFound)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		q, err := logQuery(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if q.Limit <= 0 {
+			q.Limit = defaultLogPage
+		}
+		q.Limit = min(q.Limit, maxPageSize)
+		writeJSON(w, s.Logs.Entries(q))
+	case http.MethodDelete:
+		s.Logs.Clear()
+		s.logger().Info("logs cleared", "remote", r.RemoteAddr)
+		writeJSON(w, map[string]any{"ok": true})
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleLogStream atiende GET /logs/stream: un evento "log" por registro, con
+// los mismos filtros que /logs. Al reconectar, el navegador manda
+// Last-Event-ID y se reenvía lo que siga en el buffer desde ese ID; con
+// tail=N se empieza por los últimos N.
+func (s *Server) handleLogStream(w http.ResponseWriter, r *http.Request) {
+	if s.Logs == nil {
+		http.Error(w, "logs desactivados", http.StatusNotFound)
+		return
+	}
+	q, err := logQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if id := r.Header.Get("Last-Event-ID"); id != "" {
+		q.After, _ = strconv.ParseInt(id, 10, 64)
+	}
+	tail, _ := strconv.Atoi(r.URL.Query().Get("tail"))
+
+	// suscribirse antes de leer el buffer para no perder nada entre medio
+	ch, cancel := s.Logs.Subscribe()
+	defer cancel()
+
+	var backlog []logs.Entry
+	if q.After > 0 || tail > 0 {
+		bq := q
+		bq.Limit = tail
+		if q.After > 0 {
+			bq.Limit = 0
+		}
+		backlog = s.Logs.Entries(bq)
+	}
+
+	stream, err := newSSE(w)
+	if err != nil {
+		return
+	}
+	var last int64
+	for i := len(backlog) - 1; i >= 0; i-- {
+		e := backlog[i]
+		if stream.send(strconv.FormatInt(e.ID, 10), "log", e) != nil {
+			return
+		}
+		last = e.ID
+	}
+
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e, ok := <-ch:
+			if !ok {
+				return
+			}
+			if e.ID <= last || !q.Matches(e) {
+				continue
+			}
+			if stream.send(strconv.FormatInt(e.ID, 10), "log", e) != nil {
+				return
+			}
+			last = e.ID
+		case <-keepAlive.C:
+			if stream.ping() != nil {
+				return
+			}
+		}
+	}
+}
+
+func logQuery(r *http.Request) (logs.Query, error) {
+	v := r.URL.Query()
+	q := logs.Query{
+		Level:  v.Get("level"),
+		Source: v.Get("source"),
+		Text:   v.Get("q"),
+	}
+	if q.Level != "" {
+		if _, err := logs.ParseLevel(q.Level); err != nil {
+			return q, err
+		}
+	}
+	for name, dst := range map[string]*int64{"before": &q.Before, "after": &q.After} {
+		if s := v.Get(name); s != "" {
+			n, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				return q, err
+			}
+			*dst = n
+		}
+	}
+	q.Limit, _ = strconv.Atoi(v.Get("limit"))
+	return q, nil
+}
+
+func (s *Server) logger() *slog.Logger {
+	if s.Log != nil {
+		return s.Log
+	}
+	return slog.Default()
+}
+
+// statusWriter guarda el código de respuesta para logRequests. Unwrap permite
+// que http.ResponseController llegue a Flush (SSE).
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *statusWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+// logRequests registra cada petición: en DEBUG las correctas (el panel
+// consulta /status cada pocos segundos), en WARN las 4xx y en ERROR las 5xx.
+func (s *Server) logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w}
+		next.ServeHTTP(sw, r)
+		if sw.status == 0 {
+			sw.status = http.StatusOK
+		}
+
+		level := slog.LevelDebug
+		switch {
+		case sw.status >= 500:
+			level = slog.LevelError
+		case sw.status >= 400:
+			level = slog.LevelWarn
+		}
+		s.logger().Log(r.Context(), level, "request",
+			"method", r.Method, "path", r.URL.Path, "status", sw.status,
+			"ms", time.Since(start).Milliseconds(), "remote", r.RemoteAddr)
+	})
+}
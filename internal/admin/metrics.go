@@ -0,0 +1,90 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"IA1_EV2025_Proyecto2/internal/metrics"
+	"IA1_EV2025_Proyecto2/internal/store"
+)
+
+// maxPageSize limita /metrics/records.
+const maxPageSize = 1000
+
+// handleMetrics responde GET /metrics?from=&to=&session= con los agregados.
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	q, err := metricQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	records, err := s.Store.Metrics(q)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, metrics.Aggregate(records, time.Now()))
+}
+
+// handleMetricRecords responde GET /metrics/records?from=&to=&session=&offset=&limit=
+// con los registros, el más reciente primero.
+func (s *Server) handleMetricRecords(w http.ResponseWriter, r *http.Request) {
+	q, err := metricQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 || limit > maxPageSize {
+		limit = 100
+	}
+
+	records, err := s.Store.Metrics(q)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	total := len(records)
+
+	page := []metrics.Record{}
+	for i := total - 1 - offset; i >= 0 && len(page) < limit; i-- {
+		page = append(page, records[i])
+	}
+	writeJSON(w, map[string]any{
+		"total":   total,
+		"offset":  offset,
+		"limit":   limit,
+		"records": page,
+	})
+}
+
+// metricQuery lee from/to (RFC 3339 o una duración hacia atrás, p. ej. "24h")
+// y session.
+func metricQuery(r *http.Request) (store.MetricQuery, error) {
+	q := store.MetricQuery{Session: r.URL.Query().Get("session")}
+	var err error
+	if q.Since, err = parseTime(r.URL.Query().Get("from")); err != nil {
+		return q, errors.New("from inválido")
+	}
+	if q.Until, err = parseTime(r.URL.Query().Get("to")); err != nil {
+		return q, errors.New("to inválido")
+	}
+	return q, nil
+}
+
+func parseTime(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(strings.TrimPrefix(v, "-")); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
@@ -0,0 +1,124 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"IA1_EV2025_Proyecto2/internal/capture"
+)
+
+const (
+	previewWidth  = 640 // ancho por defecto de /preview.mjpeg
+	previewMaxFPS = 15
+	mjpegBoundary = "smartslideframe"
+)
+
+// previewOptions lee ?width=N&quality=N&overlay=roi,screen,diff.
+func previewOptions(r *http.Request, width int) capture.PreviewOptions {
+	q := r.URL.Query()
+	opts := capture.PreviewOptions{Width: width}
+	if w, err := strconv.Atoi(q.Get("width")); err == nil && w >= 0 {
+		opts.Width = w
+	}
+	opts.Quality, _ = strconv.Atoi(q.Get("quality"))
+	for _, o := range strings.Split(q.Get("overlay"), ",") {
+		switch strings.TrimSpace(o) {
+		case "roi":
+			opts.ROI = true
+		case "screen":
+			opts.Screen = true
+		case "diff":
+			opts.Diff = true
+		case "all":
+			opts.ROI, opts.Screen, opts.Diff = true, true, true
+		}
+	}
+	return opts
+}
+
+// handleSnapshot atiende GET /snapshot.jpg: el último frame leído, en tamaño
+// original salvo que se pida width.
+func (s *Server) handleSnapshot(w http.ResponseWriter, r *http.Request) {
+	if s.Preview == nil {
+		http.Error(w, "vista previa desactivada", http.StatusNotFound)
+		return
+	}
+	f, err := s.Preview.Render(previewOptions(r, 0))
+	if errors.Is(err, capture.ErrNoFrame) {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "image/jpeg")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Last-Modified", f.At.UTC().Format(http.TimeFormat))
+	w.Header().Set("X-Frame-Seq", strconv.FormatUint(f.Seq, 10))
+	_, _ = w.Write(f.JPEG)
+}
+
+// handlePreview atiende GET /preview.mjpeg?fps=N: multipart/x-mixed-replace
+// con cada frame nuevo, que un <img> muestra directamente. Mientras haya
+// clientes, el Runner lee frames también en pausa.
+func (s *Server) handlePreview(w http.ResponseWriter, r *http.Request) {
+	if s.Preview == nil {
+		http.Error(w, "vista previa desactivada", http.StatusNotFound)
+		return
+	}
+	opts := previewOptions(r, previewWidth)
+	fps, _ := strconv.Atoi(r.URL.Query().Get("fps"))
+	if fps <= 0 || fps > previewMaxFPS {
+		fps = 5
+	}
+	interval := time.Second / time.Duration(fps)
+
+	stop := s.Preview.Watch()
+	defer stop()
+
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{})
+	w.Header().Set("Content-Type", "multipart/x-mixed-replace; boundary="+mjpegBoundary)
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+
+	ctx := r.Context()
+	var seq uint64
+	var last time.Time
+	for {
+		if !s.Preview.Wait(ctx, seq) {
+			return
+		}
+		if wait := interval - time.Since(last); wait > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+		}
+		f, err := s.Preview.Render(opts)
+		if err != nil {
+			s.logger().Warn("preview", "err", err)
+			return
+		}
+		seq, last = f.Seq, time.Now()
+
+		if _, err := fmt.Fprintf(w, "--%s\r\nContent-Type: image/jpeg\r\nContent-Length: %d\r\n\r\n", mjpegBoundary, len(f.JPEG)); err != nil {
+			return
+		}
+		if _, err := w.Write(f.JPEG); err != nil {
+			return
+		}
+		if _, err := fmt.Fprint(w, "\r\n"); err != nil {
+			return
+		}
+		if rc.Flush() != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"maps"
+	"net/http"
+
+	"IA1_EV2025_Proyecto2/internal/config"
+)
+
+func (s *Server) profileRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /config/profiles", s.handleProfiles)
+	mux.HandleFunc("PUT /config/profiles/{name}", s.handleProfilePut)
+	mux.HandleFunc("DELETE /config/profiles/{name}", s.handleProfileDelete)
+	mux.HandleFunc("POST /config/profiles/{name}/apply", s.handleProfileApply)
+}
+
+// handleProfiles atiende GET /config/profiles: el perfil activo y los cambios
+// que aplica cada uno.
+func (s *Server) handleProfiles(w http.ResponseWriter, r *http.Request) {
+	c := s.GetCfg()
+	profiles := c.Profiles
+	if profiles == nil {
+		profiles = map[string]json.RawMessage{}
+	}
+	writeJSON(w, map[string]any{"active": c.Profile, "profiles": profiles})
+}
+
+// handleProfilePut atiende PUT /config/profiles/{name} con el merge patch del
+// perfil (p. ej. {"camera_index": 1, "roi": {...}}). Si es el perfil activo
+// se vuelve a aplicar.
+func (s *Server) handleProfilePut(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxConfigBody))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	patch, err := config.CompactProfile(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	name := r.PathValue("name")
+	c := s.GetCfg()
+	c.Profiles = maps.Clone(c.Profiles)
+	if c.Profiles == nil {
+		c.Profiles = map[string]json.RawMessage{}
+	}
+	c.Profiles[name] = patch
+	if c.Profile == name {
+		if c, err = c.WithProfile(name); err != nil {
+			writeConfigError(w, err)
+			return
+		}
+	}
+	if err := s.SetCfg(c, source(r)); err != nil {
+		writeConfigError(w, err)
+		return
+	}
+	writeJSON(w, map[string]any{"ok": true})
+}
+
+// handleProfileDelete atiende DELETE /config/profiles/{name}. Borrar el perfil
+// activo no deshace sus cambios: solo deja de haber perfil activo.
+func (s *Server) handleProfileDelete(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	c := s.GetCfg()
+	if _, ok := c.Profiles[name]; !ok {
+		http.Error(w, config.ErrProfileNotFound.Error(), http.StatusNotFound)
+		return
+	}
+	c.Profiles = maps.Clone(c.Profiles)
+	delete(c.Profiles, name)
+	if c.Profile == name {
+		c.Profile, c.ProfileBase = "", nil
+	}
+	if err := s.SetCfg(c, source(r)); err != nil {
+		writeConfigError(w, err)
+		return
+	}
+	writeJSON(w, map[string]any{"ok": true})
+}
+
+// handleProfileApply atiende POST /config/profiles/{name}/apply: aplica el
+// perfil sobre la configuración actual y devuelve el resultado sin secretos.
+func (s *Server) handleProfileApply(w http.ResponseWriter, r *http.Request) {
+	c, err := s.GetCfg().WithProfile(r.PathValue("name"))
+	if errors.Is(err, config.ErrProfileNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		writeConfigError(w, err)
+		return
+	}
+	if err := s.SetCfg(c, source(r)); err != nil {
+		writeConfigError(w, err)
+		return
+	}
+	s.logger().Info("profile applied", "profile", c.Profile, "source", source(r))
+	writeJSON(w, s.GetCfg().Redacted())
+}
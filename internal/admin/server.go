@@ -2,32 +2,51 @@ package admin
 
 import (
 	"encoding/json"
+	"log/slog"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
 	"IA1_EV2025_Proyecto2/internal/app"
+	"IA1_EV2025_Proyecto2/internal/auth"
+	"IA1_EV2025_Proyecto2/internal/capture"
 	"IA1_EV2025_Proyecto2/internal/config"
+	"IA1_EV2025_Proyecto2/internal/events"
+	"IA1_EV2025_Proyecto2/internal/logs"
+	"IA1_EV2025_Proyecto2/internal/metrics"
+	"IA1_EV2025_Proyecto2/internal/search"
+	"IA1_EV2025_Proyecto2/internal/store"
 )
 
 type Server struct {
 	State   *app.State
 	GetCfg  func() config.Config
-	SetCfg  func(config.Config) error
-	Control chan<- app.ControlState
+	SetCfg  func(c config.Config, source string) error // source: quién hizo el cambio, para el historial
+	Sources func() config.Sources                      // nil = /config/sources desactivado
+	Control *app.Supervisor
+	Index   *search.Index // nil = /search desactivado
+	Store   *store.Store
+	Logs    *logs.Buffer // nil = /logs desactivado
+	Events  *events.Bus  // nil = /events desactivado
+	Preview *capture.Preview
+	Gallery Gallery             // nil = galería de solo lectura
+	Auth    *auth.Authenticator // nil = sin autenticación
+	History *config.History     // nil = sin historial de configuración
+	Log     *slog.Logger
 }
 
-func corsMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
-		}
-
-		next.ServeHTTP(w, r)
-	})
+// searchHit es un resultado de /search; las imágenes se piden por ID.
+type searchHit struct {
+	ID           string    `json:"id"`
+	Session      string    `json:"session"`
+	SessionTitle string    `json:"session_title,omitempty"`
+	CapturedAt   time.Time `json:"captured_at"`
+	Title        string    `json:"title"`
+	Score        float64   `json:"score"`
+	Highlights   []string  `json:"highlights"`
+	Thumbnail    string    `json:"thumbnail,omitempty"`
+	Image        string    `json:"image"`
 }
 
 func (s *Server) Routes() http.Handler {
@@ -37,18 +56,46 @@ func (s *Server) Routes() http.Handler {
 		writeJSON(w, s.State.Snapshot())
 	})
 
-	mux.HandleFunc("/config", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/config", s.handleConfig)
+	mux.HandleFunc("GET /config/schema", s.handleConfigSchema)
+	mux.HandleFunc("GET /config/sources", s.handleConfigSources)
+	s.profileRoutes(mux)
+	s.historyRoutes(mux)
+
+	mux.HandleFunc("/subscriptions", func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodGet:
-			writeJSON(w, s.GetCfg())
+			writeJSON(w, s.GetCfg().Targets())
 		case http.MethodPost:
-			var c config.Config
-			if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+			var d config.Destination
+			if err := json.NewDecoder(r.Body).Decode(&d); err != nil {
 				http.Error(w, err.Error(), http.StatusBadRequest)
 				return
 			}
-			if err := s.SetCfg(c); err != nil {
-				http.Error(w, err.Error(), http.StatusBadRequest)
+			if d.ChatID == 0 {
+				http.Error(w, "chat_id requerido", http.StatusBadRequest)
+				return
+			}
+			c := s.GetCfg()
+			c.AddDestination(d)
+			if err := s.SetCfg(c, source(r)); err != nil {
+				writeConfigError(w, err)
+				return
+			}
+			writeJSON(w, map[string]any{"ok": true})
+		case http.MethodDelete:
+			chatID, err := strconv.ParseInt(r.URL.Query().Get("chat_id"), 10, 64)
+			if err != nil {
+				http.Error(w, "chat_id inválido", http.StatusBadRequest)
+				return
+			}
+			c := s.GetCfg()
+			if !c.RemoveDestination(chatID) {
+				http.Error(w, "suscripción no encontrada", http.StatusNotFound)
+				return
+			}
+			if err := s.SetCfg(c, source(r)); err != nil {
+				writeConfigError(w, err)
 				return
 			}
 			writeJSON(w, map[string]any{"ok": true})
@@ -57,20 +104,107 @@ func (s *Server) Routes() http.Handler {
 		}
 	})
 
-	mux.HandleFunc("/control/start", func(w http.ResponseWriter, r *http.Request) {
-		s.Control <- app.StateRunning
-		writeJSON(w, map[string]any{"ok": true})
+	mux.HandleFunc("/metrics", s.handleMetrics)
+	mux.HandleFunc("/metrics/records", s.handleMetricRecords)
+	mux.HandleFunc("/metrics/prometheus", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		metrics.WritePrometheus(w)
+	})
+
+	s.authRoutes(mux)
+	s.galleryRoutes(mux)
+	mux.HandleFunc("/events", s.handleEvents)
+	mux.HandleFunc("/preview.mjpeg", s.handlePreview)
+	mux.HandleFunc("/snapshot.jpg", s.handleSnapshot)
+	mux.HandleFunc("/logs", s.handleLogs)
+	mux.HandleFunc("/logs/stream", s.handleLogStream)
+
+	mux.HandleFunc("/sessions", func(w http.ResponseWriter, r *http.Request) {
+		sessions, err := s.Store.Sessions()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if sessions == nil {
+			sessions = []store.Session{}
+		}
+		writeJSON(w, sessions)
+	})
+
+	// /slides?session=ID&limit=N
+	mux.HandleFunc("/slides", func(w http.ResponseWriter, r *http.Request) {
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		slides, err := s.Store.Slides(store.SlideQuery{
+			Session: r.URL.Query().Get("session"),
+			Limit:   limit,
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if slides == nil {
+			slides = []store.Slide{}
+		}
+		writeJSON(w, slides)
 	})
-	mux.HandleFunc("/control/pause", func(w http.ResponseWriter, r *http.Request) {
-		s.Control <- app.StatePaused
-		writeJSON(w, map[string]any{"ok": true})
+
+	mux.HandleFunc("/search", func(w http.ResponseWriter, r *http.Request) {
+		if s.Index == nil {
+			http.Error(w, "búsqueda desactivada", http.StatusNotFound)
+			return
+		}
+		q := r.URL.Query().Get("q")
+		if q == "" {
+			http.Error(w, "q requerido", http.StatusBadRequest)
+			return
+		}
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		if limit <= 0 {
+			limit = 20
+		}
+
+		hits := []searchHit{}
+		for _, res := range s.Index.Search(q, limit) {
+			d := res.Doc
+			h := searchHit{
+				ID:           d.ID,
+				Session:      d.Session,
+				SessionTitle: d.SessionTitle,
+				CapturedAt:   d.CapturedAt,
+				Title:        d.Summary.Title,
+				Score:        res.Score,
+				Highlights:   res.Highlights,
+				Image:        "/search/image?id=" + url.QueryEscape(d.ID),
+			}
+			if d.Thumb != "" {
+				h.Thumbnail = "/search/image?thumb=1&id=" + url.QueryEscape(d.ID)
+			}
+			hits = append(hits, h)
+		}
+		writeJSON(w, map[string]any{"query": q, "results": hits})
 	})
-	mux.HandleFunc("/control/stop", func(w http.ResponseWriter, r *http.Request) {
-		s.Control <- app.StateStopped
-		writeJSON(w, map[string]any{"ok": true})
+
+	// las rutas salen del índice, nunca de la petición
+	mux.HandleFunc("/search/image", func(w http.ResponseWriter, r *http.Request) {
+		if s.Index == nil {
+			http.NotFound(w, r)
+			return
+		}
+		d, ok := s.Index.Get(r.URL.Query().Get("id"))
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		path := d.Path
+		if r.URL.Query().Get("thumb") != "" && d.Thumb != "" {
+			path = d.Thumb
+		}
+		s.serveOutputFile(w, r, path)
 	})
 
-	return corsMiddleware(mux)
+	s.controlRoutes(mux)
+
+	return s.cors(s.logRequests(s.requireAuth(mux)))
 }
 
 func writeJSON(w http.ResponseWriter, v any) {
@@ -0,0 +1,57 @@
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// sseKeepAlive es cada cuánto se envía un comentario para que los proxies no
+// cierren una conexión SSE sin eventos.
+const sseKeepAlive = 15 * time.Second
+
+// sseStream escribe eventos Server-Sent Events.
+type sseStream struct {
+	w  http.ResponseWriter
+	rc *http.ResponseController
+}
+
+// newSSE envía las cabeceras del stream.
+func newSSE(w http.ResponseWriter) (*sseStream, error) {
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // nginx
+	w.WriteHeader(http.StatusOK)
+	// sin plazo de escritura: la conexión queda abierta
+	_ = rc.SetWriteDeadline(time.Time{})
+	return &sseStream{w: w, rc: rc}, rc.Flush()
+}
+
+// send escribe un evento con v en JSON; id vacío = sin id.
+func (s *sseStream) send(id, event string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if id != "" {
+		fmt.Fprintf(s.w, "id: %s\n", id)
+	}
+	if event != "" {
+		fmt.Fprintf(s.w, "event: %s\n", event)
+	}
+	if _, err := fmt.Fprintf(s.w, "data: %s\n\n", data); err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
+
+// ping envía un comentario, que los clientes ignoran.
+func (s *sseStream) ping() error {
+	if _, err := fmt.Fprint(s.w, ": ping\n\n"); err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
@@ -0,0 +1,298 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"IA1_EV2025_Proyecto2/internal/config"
+	"IA1_EV2025_Proyecto2/internal/ocr"
+	"IA1_EV2025_Proyecto2/internal/schedule"
+	"IA1_EV2025_Proyecto2/internal/search"
+	"IA1_EV2025_Proyecto2/internal/telegram"
+)
+
+// HandleCommand responde a los comandos del bot de Telegram usando el mismo
+// Supervisor y el mismo State que el panel de administración.
+func (r *Runner) HandleCommand(cmd telegram.Command) telegram.Reply {
+	switch cmd.Name {
+	case "status":
+		st := r.State.Snapshot()
+		return telegram.Reply{Text: formatStatus(&st)}
+
+	case "start", "pause", "stop":
+		return r.control(cmd.Name)
+
+	case "last":
+		last := r.Slides.Session(r.State.Snapshot().SessionID, 1)
+		if len(last) == 0 {
+			return telegram.Reply{Text: "Aún no hay diapositivas en la sesión."}
+		}
+		return telegram.Reply{Photo: last[0].Path, Text: last[0].Caption}
+
+	case "slides":
+		n := 5
+		if cmd.Args != "" {
+			v, err := strconv.Atoi(cmd.Args)
+			if err != nil || v <= 0 {
+				return telegram.Reply{Text: "Uso: /slides N"}
+			}
+			n = v
+		}
+		slides := r.Slides.Session(r.State.Snapshot().SessionID, n)
+		if len(slides) == 0 {
+			return telegram.Reply{Text: "Aún no hay diapositivas en la sesión."}
+		}
+		return telegram.Reply{Text: formatSlideList(slides)}
+
+	case "search":
+		if cmd.Args == "" {
+			return telegram.Reply{Text: "Uso: /search término"}
+		}
+		if r.Index != nil {
+			return telegram.Reply{Text: formatResults(cmd.Args, r.Index.Search(cmd.Args, 10))}
+		}
+		found := r.Slides.Search(cmd.Args)
+		if len(found) == 0 {
+			return telegram.Reply{Text: fmt.Sprintf("Sin resultados para %q.", cmd.Args)}
+		}
+		if len(found) > 10 {
+			found = found[:10]
+		}
+		return telegram.Reply{Text: formatSlideList(found)}
+
+	case "export":
+		path, err := r.exportNotes()
+		if err != nil {
+			return telegram.Reply{Text: "Error al exportar: " + err.Error()}
+		}
+		return telegram.Reply{Document: path, Text: "Notas de la sesión"}
+
+	case "subscribe":
+		name := cmd.ChatTitle
+		if name == "" {
+			name = strconv.FormatInt(cmd.ChatID, 10)
+		}
+		added, err := r.Subscribe(config.Destination{Name: name, ChatID: cmd.ChatID})
+		if err != nil {
+			return telegram.Reply{Text: "Error: " + err.Error()}
+		}
+		if !added {
+			return telegram.Reply{Text: "Este chat ya está suscrito."}
+		}
+		return telegram.Reply{Text: "Suscrito: las diapositivas llegarán a este chat."}
+
+	case "unsubscribe":
+		removed, err := r.Unsubscribe(cmd.ChatID)
+		if err != nil {
+			return telegram.Reply{Text: "Error: " + err.Error()}
+		}
+		if !removed {
+			return telegram.Reply{Text: "Este chat no estaba suscrito."}
+		}
+		return telegram.Reply{Text: "Suscripción cancelada."}
+
+	case "profile":
+		cfg := r.GetConfig()
+		if cmd.Args == "" {
+			return telegram.Reply{Text: formatProfiles(cfg)}
+		}
+		if err := r.ApplyProfile(cmd.Args, "bot"); err != nil {
+			return telegram.Reply{Text: "Error: " + err.Error()}
+		}
+		return telegram.Reply{Text: fmt.Sprintf("Perfil %q aplicado.", cmd.Args)}
+
+	default:
+		return telegram.Reply{Text: "Comandos: /status /start /pause /stop /last /slides N /search término /export /subscribe /unsubscribe /profile [nombre]"}
+	}
+}
+
+// Subscribe agrega el chat a los destinos (si no estaba) y guarda la configuración.
+func (r *Runner) Subscribe(d config.Destination) (bool, error) {
+	cfg := r.GetConfig()
+	for _, x := range cfg.Targets() {
+		if x.ChatID == d.ChatID {
+			return false, nil
+		}
+	}
+	cfg.AddDestination(d)
+	return true, r.UpdateConfig(cfg, "bot")
+}
+
+// Unsubscribe quita el chat de los destinos y guarda la configuración.
+func (r *Runner) Unsubscribe(chatID int64) (bool, error) {
+	cfg := r.GetConfig()
+	if !cfg.RemoveDestination(chatID) {
+		return false, nil
+	}
+	return true, r.UpdateConfig(cfg, "bot")
+}
+
+// ApplyProfile aplica el perfil name sobre la configuración actual y la guarda.
+func (r *Runner) ApplyProfile(name, source string) error {
+	cfg, err := r.GetConfig().WithProfile(name)
+	if err != nil {
+		return err
+	}
+	return r.UpdateConfig(cfg, source)
+}
+
+// control pasa la orden al Supervisor y responde con el cambio hecho o con el
+// estado que lo impide.
+func (r *Runner) control(name string) telegram.Reply {
+	if r.sup == nil {
+		return telegram.Reply{Text: "Control no disponible."}
+	}
+	var t Transition
+	var err error
+	switch name {
+	case "start":
+		t, err = r.sup.Start()
+	case "pause":
+		t, err = r.sup.Pause()
+	case "stop":
+		t, err = r.sup.Stop()
+	}
+	var terr *TransitionError
+	switch {
+	case errors.As(err, &terr):
+		return telegram.Reply{Text: fmt.Sprintf("No se puede ahora: la captura está %s.", stateNames[terr.From])}
+	case err != nil:
+		return telegram.Reply{Text: "Error: " + err.Error()}
+	}
+	switch t.To {
+	case StateStarting:
+		return telegram.Reply{Text: "Iniciando la captura (sesión " + t.Session + ")."}
+	case StateRunning:
+		return telegram.Reply{Text: "Captura en marcha."}
+	case StatePaused:
+		return telegram.Reply{Text: "Captura en pausa."}
+	case StateIdle:
+		return telegram.Reply{Text: "Captura detenida."}
+	}
+	return telegram.Reply{Text: "Deteniendo la captura."}
+}
+
+// stateNames son los estados para los mensajes del bot.
+var stateNames = map[ControlState]string{
+	StateIdle:     "detenida",
+	StateStarting: "iniciándose",
+	StateRunning:  "en marcha",
+	StatePaused:   "en pausa",
+	StateStopping: "deteniéndose",
+	StateError:    "detenida por un error",
+}
+
+func formatProfiles(cfg config.Config) string {
+	names := cfg.ProfileNames()
+	if len(names) == 0 {
+		return "No hay perfiles configurados."
+	}
+	var b strings.Builder
+	b.WriteString("Perfiles:\n")
+	for _, name := range names {
+		mark := "  "
+		if name == cfg.Profile {
+			mark = "▶ "
+		}
+		b.WriteString(mark + name + "\n")
+	}
+	b.WriteString("Uso: /profile nombre")
+	return b.String()
+}
+
+func formatStatus(st *State) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Estado: %s (%s)\n", st.Status, stateNames[st.Status])
+	if st.SessionID != "" && st.Status != StateIdle {
+		fmt.Fprintf(&b, "Sesión: %s", st.SessionTitle)
+		if st.SessionSource == "horario" {
+			b.WriteString(" (horario)")
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "Diapositivas: %d\n", st.SlidesCaptured)
+	if !st.StartedAt.IsZero() {
+		fmt.Fprintf(&b, "Inicio: %s\n", st.StartedAt.Format("15:04:05"))
+	}
+	if !st.LastSlideAt.IsZero() {
+		fmt.Fprintf(&b, "Última: %s\n", st.LastSlideAt.Format("15:04:05"))
+	}
+	if st.LastError != "" {
+		fmt.Fprintf(&b, "Último error: %s\n", st.LastError)
+	}
+	if len(st.Upcoming) > 0 {
+		b.WriteString("Próximas clases:\n")
+		b.WriteString(FormatSlots(st.Upcoming, time.Now()))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// FormatSlots lista las clases, una por línea: "lun 20/10 08:00–09:40 título".
+func FormatSlots(slots []schedule.Slot, now time.Time) string {
+	var b strings.Builder
+	for _, sl := range slots {
+		fmt.Fprintf(&b, "  %s %s %s–%s", schedule.DayName(sl.Start), sl.Start.Format("02/01"), sl.Start.Format("15:04"), sl.End.Format("15:04"))
+		if sl.Title != "" {
+			b.WriteString(" " + sl.Title)
+		}
+		if !sl.Start.After(now) {
+			b.WriteString(" (ahora)")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func formatSlideList(slides []Slide) string {
+	var b strings.Builder
+	for _, s := range slides {
+		title := s.Summary.Title
+		if title == "" {
+			title = "(sin título)"
+		}
+		fmt.Fprintf(&b, "%s  %s\n", s.CapturedAt.Format("15:04:05"), title)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func formatResults(query string, results []search.Result) string {
+	if len(results) == 0 {
+		return fmt.Sprintf("Sin resultados para %q.", query)
+	}
+	var b strings.Builder
+	for _, res := range results {
+		d := res.Doc
+		fmt.Fprintf(&b, "%s  %s\n", d.CapturedAt.Format("02/01 15:04"), d.SessionTitle)
+		for _, h := range res.Highlights {
+			fmt.Fprintf(&b, "  %s\n", search.PlainHighlight(h, "«", "»"))
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// exportNotes escribe las notas Markdown de las diapositivas de la sesión
+// actual (o de la última, si no hay ninguna en curso).
+func (r *Runner) exportNotes() (string, error) {
+	slides := r.Slides.Session(r.State.Snapshot().SessionID, 0)
+	if len(slides) == 0 {
+		return "", fmt.Errorf("no hay diapositivas")
+	}
+
+	var b strings.Builder
+	for _, s := range slides {
+		b.WriteString(ocr.BuildNotes(s.Summary))
+		b.WriteString("\n---\n\n")
+	}
+
+	name := fmt.Sprintf("notes_%s.md", time.Now().Format("20060102_150405"))
+	path := filepath.Join(r.GetConfig().OutputDir, name)
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,485 @@
+package app
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"IA1_EV2025_Proyecto2/internal/config"
+	"IA1_EV2025_Proyecto2/internal/metrics"
+	"IA1_EV2025_Proyecto2/internal/ocr"
+	"IA1_EV2025_Proyecto2/internal/telegram"
+)
+
+// delivery reparte las diapositivas entre los destinos (sinks). Recuerda la
+// última para reconocer revelaciones incrementales (bullets que aparecen uno a
+// uno) y actualizar lo ya publicado en vez de enviar otra diapositiva.
+type delivery struct {
+	bot    *telegram.Client
+	slides *SlideLog
+	log    *slog.Logger
+
+	mode      string // "edit", "diff" u "off"
+	albumSize int
+	albumWait time.Duration
+	sendDocs  bool
+	maxChars  int
+	course    string
+
+	captionTemplate string
+	captionFormat   string
+	followup        bool // enviar lo que no cabe en el caption como respuesta
+
+	sinks []*sink
+	only  []int64 // chats de la sesión (ver SessionOptions); vacío = todos
+	last  *Slide
+}
+
+// sink es un chat de destino con sus filtros, su plantilla y su álbum pendiente.
+type sink struct {
+	dest   config.Destination
+	tmpl   *template.Template
+	format string
+
+	pending      []*Slide
+	pendingSince time.Time
+}
+
+func newDelivery(bot *telegram.Client, slides *SlideLog, cfg config.Config, only []int64, log *slog.Logger) *delivery {
+	d := &delivery{bot: bot, slides: slides, only: only, log: log}
+	d.configure(cfg)
+	return d
+}
+
+// configure aplica cfg sin perder la última diapositiva (las revelaciones
+// siguen editando lo publicado) ni los álbumes pendientes, salvo que cambie el
+// tamaño del álbum: entonces se envían antes.
+func (d *delivery) configure(cfg config.Config) {
+	albumSize := min(cfg.AlbumSize, telegram.MaxAlbumSize)
+	if d.albumSize != 0 && albumSize != d.albumSize {
+		if err := d.flush(); err != nil {
+			d.log.Error("flush album", "err", err)
+		}
+	}
+	d.mode = cfg.BuildMode
+	d.albumSize = albumSize
+	d.albumWait = time.Duration(cfg.AlbumMaxWaitSeconds) * time.Second
+	d.sendDocs = cfg.SendTableDocuments
+	d.maxChars = cfg.MaxCaptionChars
+	d.course = cfg.CourseName
+	d.captionTemplate = cfg.CaptionTemplate
+	d.captionFormat = cfg.CaptionFormat
+	d.followup = cfg.CaptionOverflow == "followup"
+	targets := cfg.Targets()
+	if len(d.only) > 0 {
+		targets = slices.DeleteFunc(slices.Clone(targets), func(t config.Destination) bool {
+			return !slices.Contains(d.only, t.ChatID)
+		})
+	}
+	d.setDestinations(targets)
+}
+
+// setDestinations reemplaza los destinos conservando los álbumes pendientes de
+// los chats que siguen configurados.
+func (d *delivery) setDestinations(dests []config.Destination) {
+	old := map[int64]*sink{}
+	for _, s := range d.sinks {
+		old[s.dest.ChatID] = s
+	}
+
+	sinks := make([]*sink, 0, len(dests))
+	for _, dest := range dests {
+		s := old[dest.ChatID]
+		if s == nil {
+			s = &sink{}
+		}
+		delete(old, dest.ChatID)
+
+		s.dest = dest
+		s.format = dest.CaptionFormat
+		if s.format == "" {
+			s.format = d.captionFormat
+		}
+		s.tmpl = nil
+		text := dest.CaptionTemplate
+		if text == "" {
+			text = d.captionTemplate
+		}
+		if text != "" {
+			t, err := ocr.ParseCaptionTemplate(dest.Name, text)
+			if err != nil {
+				d.log.Warn("caption template", "destination", dest.Name, "err", err)
+			} else {
+				s.tmpl = t
+			}
+		}
+		sinks = append(sinks, s)
+	}
+
+	// chats eliminados: enviar lo que tuvieran pendiente
+	for _, s := range old {
+		if err := d.flushSink(s); err != nil {
+			d.log.Error("flush removed destination", "chat", s.dest.ChatID, "err", err)
+		}
+	}
+	d.sinks = sinks
+}
+
+// reveal es una revelación incremental de la última diapositiva.
+type reveal struct {
+	prev  Slide    // copia de la anterior, antes de actualizarla
+	added []string // líneas nuevas
+}
+
+// reveals indica si una diapositiva con summary sería una revelación de la
+// anterior; devuelve nil si no lo es.
+func (d *delivery) reveals(summary ocr.Summary) *reveal {
+	if d.mode == "off" || d.last == nil {
+		return nil
+	}
+	added, ok := ocr.DetectBuild(d.last.Summary, summary)
+	if !ok {
+		return nil
+	}
+	return &reveal{prev: d.slides.Get(d.last), added: added}
+}
+
+// send publica s o, si rv no es nil (ver reveals), actualiza con s lo ya
+// publicado de la anterior; s no se registra entonces.
+func (d *delivery) send(s *Slide, rv *reveal) error {
+	if rv != nil {
+		return d.updateLast(s, rv)
+	}
+
+	s.Messages = map[int64]int{}
+	s.AttachmentMsgs = map[int64][]int{}
+	s.Errors = map[int64]string{}
+	d.slides.Add(s)
+	d.last = s
+
+	var errs []string
+	for _, sk := range d.sinks {
+		if !sk.accepts(s) {
+			continue
+		}
+		err := d.sendTo(sk, s)
+		d.setError(s, sk.dest.ChatID, err)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("chat %d: %v", sk.dest.ChatID, err))
+		}
+	}
+	return joinErrs(errs)
+}
+
+func (d *delivery) sendTo(sk *sink, s *Slide) error {
+	if d.albumSize > 1 {
+		if len(sk.pending) == 0 {
+			sk.pendingSince = time.Now()
+		}
+		sk.pending = append(sk.pending, s)
+		d.updateQueueDepth()
+		if len(sk.pending) >= d.albumSize {
+			return d.flushSink(sk)
+		}
+		return nil
+	}
+
+	c := d.caption(sk, s)
+	id, err := d.bot.SendPhotoWithCaption(sk.dest.ChatID, s.Path, c.Text, c.ParseMode)
+	if err != nil {
+		return err
+	}
+	d.slides.Update(s, func(s *Slide) { s.Messages[sk.dest.ChatID] = id })
+	if err := d.sendOverflow(sk, id, c); err != nil {
+		return err
+	}
+	return d.sendAttachments(sk, s)
+}
+
+// sendOverflow envía como respuesta el texto que no cupo en el caption.
+func (d *delivery) sendOverflow(sk *sink, msgID int, c ocr.Caption) error {
+	if !d.followup || c.Overflow == "" {
+		return nil
+	}
+	for _, part := range ocr.SplitMessage(c.Overflow) {
+		if _, err := d.bot.ReplyText(sk.dest.ChatID, msgID, part, c.ParseMode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateLast pasa el contenido de s a la última diapositiva y la actualiza en
+// los chats donde se publicó (o falló, o espera en un álbum) la original: los
+// filtros ya se aplicaron a la original y no se vuelven a aplicar al
+// contenido nuevo.
+func (d *delivery) updateLast(s *Slide, rv *reveal) error {
+	last := d.last
+	d.slides.Update(last, func(l *Slide) { copyContent(l, s) })
+
+	var errs []string
+	for _, sk := range d.sinks {
+		chat := sk.dest.ChatID
+		_, sent := rv.prev.Messages[chat]
+		_, failed := rv.prev.Errors[chat]
+		if !sent && !failed && !sk.isPending(last) {
+			continue // la original no era para este chat
+		}
+		err := d.updateIn(sk, last, rv.added)
+		d.setError(last, sk.dest.ChatID, err)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("chat %d: %v", sk.dest.ChatID, err))
+		}
+	}
+	return joinErrs(errs)
+}
+
+func (d *delivery) setError(s *Slide, chatID int64, err error) {
+	if err != nil {
+		d.log.Warn("send failed", "slide", s.ID, "chat", chatID, "err", err)
+	}
+	d.slides.Update(s, func(s *Slide) {
+		if err != nil {
+			s.Errors[chatID] = err.Error()
+		} else {
+			delete(s.Errors, chatID)
+		}
+	})
+}
+
+func (d *delivery) updateIn(sk *sink, last *Slide, added []string) error {
+	msgID, sent := d.messageIn(last, sk.dest.ChatID)
+	if !sent {
+		// pendiente en el álbum (ya tiene el contenido nuevo) o antes filtrada
+		if !sk.isPending(last) {
+			return d.sendTo(sk, last)
+		}
+		return nil
+	}
+
+	var err error
+	if d.mode == "diff" {
+		_, err = d.bot.ReplyText(sk.dest.ChatID, msgID, buildDiffText(added), "")
+	} else {
+		c := d.caption(sk, last)
+		err = d.bot.EditPhoto(sk.dest.ChatID, msgID, last.Path, c.Text, c.ParseMode)
+	}
+	if err != nil {
+		// si no se pudo editar (mensaje antiguo o borrado) se envía como nueva
+		return d.sendTo(sk, last)
+	}
+	return d.sendAttachments(sk, last)
+}
+
+func (d *delivery) messageIn(s *Slide, chatID int64) (int, bool) {
+	var id int
+	var ok bool
+	d.slides.Update(s, func(s *Slide) { id, ok = s.Messages[chatID] })
+	return id, ok
+}
+
+func copyContent(dst, src *Slide) {
+	dst.RawPath = src.RawPath
+	dst.Path = src.Path
+	dst.Caption = src.Caption
+	dst.Summary = src.Summary
+	dst.Score = src.Score
+	dst.Attachments = src.Attachments
+	dst.Thumb = src.Thumb
+	dst.OCRMillis = src.OCRMillis
+	dst.OCRError = src.OCRError
+	dst.Builds++
+}
+
+// flush envía los álbumes pendientes de todos los destinos.
+func (d *delivery) flush() error {
+	var errs []string
+	for _, sk := range d.sinks {
+		if err := d.flushSink(sk); err != nil {
+			errs = append(errs, fmt.Sprintf("chat %d: %v", sk.dest.ChatID, err))
+		}
+	}
+	return joinErrs(errs)
+}
+
+// flushIfStale envía los álbumes que llevan más de albumWait esperando.
+func (d *delivery) flushIfStale() error {
+	var errs []string
+	for _, sk := range d.sinks {
+		if len(sk.pending) == 0 || time.Since(sk.pendingSince) < d.albumWait {
+			continue
+		}
+		if err := d.flushSink(sk); err != nil {
+			errs = append(errs, fmt.Sprintf("chat %d: %v", sk.dest.ChatID, err))
+		}
+	}
+	return joinErrs(errs)
+}
+
+func (d *delivery) flushSink(sk *sink) error {
+	if len(sk.pending) == 0 {
+		return nil
+	}
+	pending := sk.pending
+	sk.pending = nil
+	d.updateQueueDepth()
+
+	photos := make([]telegram.Photo, len(pending))
+	captions := make([]ocr.Caption, len(pending))
+	for i, s := range pending {
+		captions[i] = d.caption(sk, s)
+		photos[i] = telegram.Photo{Path: s.Path, Caption: captions[i].Text, ParseMode: captions[i].ParseMode}
+	}
+
+	ids, err := d.bot.SendAlbum(sk.dest.ChatID, photos)
+	for _, s := range pending {
+		d.setError(s, sk.dest.ChatID, err)
+	}
+	if err != nil {
+		return err
+	}
+	for i, s := range pending {
+		if i < len(ids) {
+			id := ids[i]
+			d.slides.Update(s, func(s *Slide) { s.Messages[sk.dest.ChatID] = id })
+			if err := d.sendOverflow(sk, id, captions[i]); err != nil {
+				return err
+			}
+		}
+		if err := d.sendAttachments(sk, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *delivery) updateQueueDepth() {
+	n := 0
+	for _, sk := range d.sinks {
+		n += len(sk.pending)
+	}
+	metrics.QueueDepth.Set(float64(n))
+}
+
+// sendAttachments envía los adjuntos como respuestas al mensaje de la diapositiva.
+func (d *delivery) sendAttachments(sk *sink, s *Slide) error {
+	if !d.sendDocs {
+		return nil
+	}
+	chatID := sk.dest.ChatID
+	msgID, ok := d.messageIn(s, chatID)
+	if !ok {
+		return nil
+	}
+
+	var sentCount int
+	d.slides.Update(s, func(s *Slide) { sentCount = len(s.AttachmentMsgs[chatID]) })
+
+	for i := sentCount; i < len(s.Attachments); i++ {
+		caption := fmt.Sprintf("%s: %s", labelTable(sk.dest.Language), filepath.Base(s.Attachments[i]))
+		id, err := d.bot.SendDocument(chatID, s.Attachments[i], caption, msgID)
+		if err != nil {
+			return err
+		}
+		d.slides.Update(s, func(s *Slide) { s.AttachmentMsgs[chatID] = append(s.AttachmentMsgs[chatID], id) })
+	}
+	return nil
+}
+
+func (d *delivery) caption(sk *sink, s *Slide) ocr.Caption {
+	data := ocr.NewCaptionData(s.Summary, s.Score)
+	data.Course = d.course
+	data.Session = s.SessionTitle
+	if data.Session == "" {
+		data.Session = s.Session
+	}
+	data.Time = s.CapturedAt
+
+	opts := ocr.CaptionOptions{
+		Template: sk.tmpl,
+		Format:   sk.format,
+		Lang:     sk.dest.Language,
+		MaxChars: d.maxChars,
+	}
+	c, err := ocr.RenderCaption(data, opts)
+	if err != nil {
+		d.log.Warn("caption template", "destination", sk.dest.Name, "err", err)
+		// plantilla rota: caption por defecto
+		opts.Template = nil
+		if c, err = ocr.RenderCaption(data, opts); err != nil {
+			return ocr.Caption{Text: s.Caption}
+		}
+	}
+	return c
+}
+
+// accepts aplica los filtros del destino.
+func (sk *sink) accepts(s *Slide) bool {
+	d := sk.dest
+	if s.Score < d.MinChangeScore {
+		return false
+	}
+	if len(d.Sessions) > 0 && !containsFold(d.Sessions, s.Session) && !containsFold(d.Sessions, s.SessionTitle) {
+		return false
+	}
+	if len(d.Keywords) > 0 {
+		text := strings.ToLower(s.Summary.Title + "\n" + s.Summary.RawText + "\n" + strings.Join(s.Summary.Keywords, " "))
+		found := false
+		for _, k := range d.Keywords {
+			if k != "" && strings.Contains(text, strings.ToLower(k)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (sk *sink) isPending(s *Slide) bool {
+	for _, p := range sk.pending {
+		if p == s {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(list []string, v string) bool {
+	for _, x := range list {
+		if strings.EqualFold(x, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func labelTable(lang string) string {
+	if lang == "en" {
+		return "Table"
+	}
+	return "Tabla"
+}
+
+func joinErrs(errs []string) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s", strings.Join(errs, "; "))
+}
+
+func buildDiffText(added []string) string {
+	var b strings.Builder
+	for _, l := range added {
+		b.WriteString("➕ ")
+		b.WriteString(l)
+		b.WriteString("\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
@@ -0,0 +1,62 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"IA1_EV2025_Proyecto2/internal/ocr"
+	"IA1_EV2025_Proyecto2/internal/store"
+)
+
+// SessionNotes arma las notas en Markdown de una sesión guardada: un
+// encabezado con la sesión y, por diapositiva, la hora, la imagen y las notas
+// de ocr.BuildNotes. Las imágenes se enlazan relativas a dir (vacío = rutas
+// tal como están en la base).
+func SessionNotes(sess store.Session, slides []store.Slide, dir string) string {
+	var b strings.Builder
+	title := sess.Title
+	if title == "" {
+		title = "Sesión " + sess.ID
+	}
+	b.WriteString("# " + title + "\n\n")
+	if !sess.StartedAt.IsZero() {
+		fmt.Fprintf(&b, "%s", sess.StartedAt.Format("2006-01-02 15:04"))
+		if !sess.EndedAt.IsZero() {
+			fmt.Fprintf(&b, " – %s", sess.EndedAt.Format("15:04"))
+		}
+		b.WriteString(" · ")
+	}
+	fmt.Fprintf(&b, "%d diapositivas\n\n", len(slides))
+
+	for _, sl := range slides {
+		notes := ocr.BuildNotes(sl.Summary)
+		// el título de cada diapositiva queda un nivel debajo del de la sesión
+		notes = "## " + sl.CapturedAt.Format("15:04:05") + " · " + strings.TrimPrefix(notes, "# ")
+		head, rest, _ := strings.Cut(notes, "\n")
+		b.WriteString(head + "\n\n")
+		if sl.Path != "" {
+			fmt.Fprintf(&b, "![%s](%s)\n", sl.ID, relPath(dir, sl.Path))
+		}
+		b.WriteString(rest)
+		b.WriteString("\n---\n\n")
+	}
+	return b.String()
+}
+
+func relPath(dir, path string) string {
+	if dir == "" {
+		return filepath.ToSlash(path)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	if d, err := filepath.Abs(dir); err == nil {
+		dir = d
+	}
+	if rel, err := filepath.Rel(dir, abs); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(abs)
+}
@@ -0,0 +1,174 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gocv.io/x/gocv"
+
+	"IA1_EV2025_Proyecto2/internal/paths"
+	"IA1_EV2025_Proyecto2/internal/store"
+)
+
+// ErrSlideNotFound indica un ID de diapositiva que no está en la base.
+var ErrSlideNotFound = errors.New("diapositiva no encontrada")
+
+// slideFiles son los archivos de la diapositiva dentro de output_dir.
+func slideFiles(sl store.Slide, outputDir string) []string {
+	files := []string{sl.RawPath, sl.Path, sl.Thumb}
+	files = append(files, sl.Attachments...)
+	files = append(files, filepath.Join(outputDir, fmt.Sprintf("slide_%s_notes.md", sl.ID)))
+
+	var out []string
+	seen := map[string]bool{}
+	for _, f := range files {
+		p, err := paths.Within(outputDir, f)
+		if err != nil || seen[p] {
+			continue
+		}
+		seen[p] = true
+		out = append(out, p)
+	}
+	return out
+}
+
+// DeleteSlide borra la diapositiva de la base, del índice de búsqueda y de la
+// sesión en curso, y sus archivos de output_dir. Los mensajes ya enviados a
+// Telegram no se tocan.
+func (r *Runner) DeleteSlide(id string) error {
+	sl, ok, err := r.Store.Slide(id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrSlideNotFound
+	}
+	if err := r.Store.DeleteSlide(id); err != nil {
+		return err
+	}
+	if r.Index != nil {
+		r.Index.Remove(id)
+	}
+	r.Slides.Remove(id)
+
+	for _, f := range slideFiles(sl, r.GetConfig().OutputDir) {
+		if err := os.Remove(f); err != nil && !errors.Is(err, os.ErrNotExist) {
+			r.log.Warn("delete slide file", "slide", id, "path", f, "err", err)
+		}
+	}
+	r.log.Info("slide deleted", "slide", id, "session", sl.Session)
+	return nil
+}
+
+// ResendSlide vuelve a enviar la diapositiva, con su caption en texto plano, a
+// chatIDs (vacío = todos los destinos configurados, sin filtros). Devuelve el
+// ID del mensaje nuevo por chat; los envíos que fallan quedan en el error.
+func (r *Runner) ResendSlide(id string, chatIDs []int64) (map[int64]int, error) {
+	sl, ok, err := r.Store.Slide(id)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrSlideNotFound
+	}
+	cfg := r.GetConfig()
+	if len(chatIDs) == 0 {
+		for _, d := range cfg.Targets() {
+			chatIDs = append(chatIDs, d.ChatID)
+		}
+	}
+	if len(chatIDs) == 0 {
+		return nil, errors.New("no hay destinos configurados")
+	}
+	photo := sl.Path
+	if _, err := os.Stat(photo); err != nil {
+		photo = sl.RawPath
+	}
+	photo, err = paths.Within(cfg.OutputDir, photo)
+	if err != nil {
+		return nil, err
+	}
+
+	sent := map[int64]int{}
+	var errs []string
+	for _, chat := range chatIDs {
+		msgID, err := r.Bot.SendPhotoWithCaption(chat, photo, sl.Caption, "")
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%d: %v", chat, err))
+			r.log.Warn("resend failed", "slide", id, "chat", chat, "err", err)
+			continue
+		}
+		sent[chat] = msgID
+	}
+
+	if len(sent) > 0 {
+		// los mensajes nuevos pasan a ser los que se editan en una revelación
+		if live := r.Slides.Find(id); live != nil {
+			r.Slides.Update(live, func(s *Slide) {
+				for chat, msgID := range sent {
+					s.Messages[chat] = msgID
+					delete(s.Errors, chat)
+				}
+			})
+		}
+		err := r.Store.UpdateSlide(id, func(s *store.Slide) {
+			if s.Delivery == nil {
+				s.Delivery = map[int64]store.Delivery{}
+			}
+			for chat, msgID := range sent {
+				s.Delivery[chat] = store.Delivery{MessageID: msgID}
+			}
+		})
+		if err != nil {
+			r.log.Error("store slide", "slide", id, "err", err)
+		}
+		r.log.Info("slide resent", "slide", id, "chats", len(sent))
+	}
+	if len(errs) > 0 {
+		return sent, errors.New(strings.Join(errs, "; "))
+	}
+	return sent, nil
+}
+
+// Thumbnail devuelve la miniatura de la diapositiva y la genera si falta (p. ej.
+// en las importadas de metrics.jsonl).
+func (r *Runner) Thumbnail(id string) (string, error) {
+	sl, ok, err := r.Store.Slide(id)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", ErrSlideNotFound
+	}
+	outputDir := r.GetConfig().OutputDir
+	if p, err := paths.Within(outputDir, sl.Thumb); err == nil {
+		if _, err := os.Stat(p); err == nil {
+			return p, nil
+		}
+	}
+
+	src := sl.RawPath
+	if _, err := os.Stat(src); err != nil {
+		src = sl.Path
+	}
+	src, err = paths.Within(outputDir, src)
+	if err != nil {
+		return "", err
+	}
+	img := gocv.IMRead(src, gocv.IMReadColor)
+	defer img.Close()
+	if img.Empty() {
+		return "", fmt.Errorf("no se pudo leer %s", filepath.Base(src))
+	}
+	thumb := writeThumbnail(img, filepath.Join(outputDir, fmt.Sprintf("slide_%s_thumb.jpg", sl.ID)))
+	if thumb == "" {
+		return "", errors.New("no se pudo generar la miniatura")
+	}
+	if err := r.Store.UpdateSlide(id, func(s *store.Slide) { s.Thumb = thumb }); err != nil {
+		r.log.Warn("store slide", "slide", id, "err", err)
+	}
+	return paths.Within(outputDir, thumb)
+}
@@ -0,0 +1,182 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"IA1_EV2025_Proyecto2/internal/capture"
+	"IA1_EV2025_Proyecto2/internal/config"
+	"IA1_EV2025_Proyecto2/internal/ocr"
+)
+
+// pipeline son las piezas del loop de captura que salen de la configuración.
+// Solo las usa la goroutine de Run; reconfigure las rehace cuando la
+// configuración cambia en caliente.
+type pipeline struct {
+	cfg        config.Config
+	cam        *capture.Camera
+	det        *capture.Detector
+	tess       *ocr.Client
+	corrector  *ocr.Corrector
+	summarizer ocr.Summarizer
+	deliv      *delivery
+	ticker     *time.Ticker
+}
+
+// openPipeline abre la cámara y arma detector, OCR, resumen y envío (solo a
+// chats, si no está vacío). Si algo falla cierra lo que ya había abierto.
+func (r *Runner) openPipeline(ctx context.Context, cfg config.Config, chats []int64) (*pipeline, error) {
+	p := &pipeline{cfg: cfg}
+	var err error
+	if p.cam, err = capture.OpenCamera(cfg.CameraIndex); err != nil {
+		r.fail("open camera", err)
+		return nil, err
+	}
+	p.det = NewDetector(cfg)
+	r.Preview.SetROI(p.det.ROI)
+
+	if p.tess, err = ocr.NewClient(cfg.TesseractLang); err != nil {
+		r.fail("tesseract", err)
+		p.Close()
+		return nil, err
+	}
+	if p.corrector, err = NewCorrector(cfg); err != nil {
+		r.fail("ocr corrector", err)
+		p.Close()
+		return nil, err
+	}
+	p.summarizer = NewSummarizer(cfg)
+	// al detener la sesión no se sigue esperando para reintentar un envío
+	p.deliv = newDelivery(r.Bot.WithContext(ctx), r.Slides, cfg, chats, r.log)
+	p.ticker = time.NewTicker(frameInterval(cfg))
+	return p, nil
+}
+
+// Close libera cámara, detector y OCR. Los álbumes pendientes los envía Run.
+func (p *pipeline) Close() {
+	if p.ticker != nil {
+		p.ticker.Stop()
+	}
+	if p.tess != nil {
+		p.tess.Close()
+	}
+	if p.det != nil {
+		p.det.Close()
+	}
+	if p.cam != nil {
+		p.cam.Close()
+	}
+}
+
+// reconfigure aplica next rehaciendo solo lo que cambió. Si una pieza nueva no
+// se puede armar (p. ej. la cámara nueva no abre) queda la anterior, con su
+// configuración, y el problema vuelve en el error; lo demás se aplica igual.
+// Devuelve true si cambió la cámara: el frame anterior ya no sirve para
+// comparar.
+func (r *Runner) reconfigure(p *pipeline, next config.Config) (bool, error) {
+	old := p.cfg
+	var changed []string
+	var errs []error
+	camChanged := false
+
+	if next.OutputDir != old.OutputDir {
+		if err := os.MkdirAll(next.OutputDir, 0755); err != nil {
+			errs = append(errs, fmt.Errorf("output_dir: %w", err))
+			next.OutputDir = old.OutputDir
+		} else {
+			changed = append(changed, "output_dir")
+		}
+	}
+
+	if next.CameraIndex != old.CameraIndex {
+		cam, err := capture.OpenCamera(next.CameraIndex)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("camera_index: %w", err))
+			next.CameraIndex = old.CameraIndex
+		} else {
+			p.cam.Close()
+			p.cam = cam
+			camChanged = true
+			changed = append(changed, "camera")
+		}
+	}
+
+	if next.Sensitivity != old.Sensitivity || next.MinSecondsBetweenSlides != old.MinSecondsBetweenSlides || next.ROI != old.ROI {
+		p.det.Close()
+		p.det = NewDetector(next)
+		r.Preview.SetROI(p.det.ROI)
+		changed = append(changed, "detector")
+	}
+
+	if next.CaptureFPS != old.CaptureFPS {
+		p.ticker.Reset(frameInterval(next))
+		changed = append(changed, "capture_fps")
+	}
+
+	if next.TesseractLang != old.TesseractLang {
+		tess, err := ocr.NewClient(next.TesseractLang)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("tesseract_lang: %w", err))
+			next.TesseractLang = old.TesseractLang
+		} else {
+			p.tess.Close()
+			p.tess = tess
+			changed = append(changed, "ocr")
+		}
+	}
+
+	if next.EnableOCRCorrection != old.EnableOCRCorrection || next.TesseractLang != old.TesseractLang ||
+		!slices.Equal(next.Glossary, old.Glossary) || !slices.Equal(next.DictionaryFiles, old.DictionaryFiles) {
+		if c, err := NewCorrector(next); err != nil {
+			errs = append(errs, fmt.Errorf("ocr corrector: %w", err))
+			next.EnableOCRCorrection = old.EnableOCRCorrection
+			next.Glossary, next.DictionaryFiles = old.Glossary, old.DictionaryFiles
+		} else {
+			p.corrector = c
+			changed = append(changed, "ocr corrector")
+		}
+	}
+
+	if next.SummarizerBackend != old.SummarizerBackend || next.LLMEndpoint != old.LLMEndpoint ||
+		next.LLMModel != old.LLMModel || next.LLMAPIKey != old.LLMAPIKey || next.LLMTimeoutSeconds != old.LLMTimeoutSeconds {
+		p.summarizer = NewSummarizer(next)
+		changed = append(changed, "summarizer")
+	}
+
+	// el envío es barato de reconfigurar y toca muchos campos: siempre
+	p.deliv.configure(next)
+
+	p.cfg = next
+	if len(changed) > 0 {
+		r.log.Info("pipeline reconfigured", "changed", changed)
+	}
+	return camChanged, errors.Join(errs...)
+}
+
+// NewDetector arma el detector de cambios con la sensibilidad, la pausa mínima
+// y la ROI de cfg.
+func NewDetector(cfg config.Config) *capture.Detector {
+	det := capture.NewDetector(
+		cfg.Sensitivity,
+		time.Duration(cfg.MinSecondsBetweenSlides)*time.Second,
+	)
+	det.ROI = capture.ROI(cfg.ROI)
+	return det
+}
+
+// NewCorrector arma el corrector del OCR con el idioma y el glosario de cfg.
+// Devuelve nil si la corrección está desactivada.
+func NewCorrector(cfg config.Config) (*ocr.Corrector, error) {
+	if !cfg.EnableOCRCorrection {
+		return nil, nil
+	}
+	return ocr.NewCorrector(cfg.TesseractLang, cfg.Glossary, cfg.DictionaryFiles)
+}
+
+func frameInterval(cfg config.Config) time.Duration {
+	return time.Second / time.Duration(max(cfg.CaptureFPS, 1))
+}
@@ -2,16 +2,26 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"image"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
+	"sync"
 	"time"
 
 	"IA1_EV2025_Proyecto2/internal/annotate"
 	"IA1_EV2025_Proyecto2/internal/capture"
 	"IA1_EV2025_Proyecto2/internal/config"
+	"IA1_EV2025_Proyecto2/internal/events"
+	"IA1_EV2025_Proyecto2/internal/logs"
 	"IA1_EV2025_Proyecto2/internal/metrics"
 	"IA1_EV2025_Proyecto2/internal/ocr"
+	"IA1_EV2025_Proyecto2/internal/paths"
+	"IA1_EV2025_Proyecto2/internal/search"
+	"IA1_EV2025_Proyecto2/internal/store"
 	"IA1_EV2025_Proyecto2/internal/telegram"
 
 	"gocv.io/x/gocv"
@@ -20,165 +30,647 @@ import (
 type Runner struct {
 	CfgPath string
 
-	cfg   config.Config
-	State *State
+	// Overrides son los valores del entorno y los flags: se aplican sobre el
+	// archivo en cada carga y no se guardan en él
+	Overrides config.Overrides
 
-	Metrics *metrics.Writer
-	Bot     *telegram.Client
+	mu      sync.RWMutex  // protege cfg, file, sources y cfgHash
+	cfg     config.Config // la efectiva
+	file    config.Config // la del archivo, sin Overrides
+	sources config.Sources
+	cfgHash [32]byte // del archivo tal como se leyó o guardó (ver WatchConfig)
+	State   *State
 
-	ctrlCh chan ControlState
+	Store   *store.Store
+	Bot     *telegram.Client
+	Slides  *SlideLog
+	Index   *search.Index // nil = no se indexa
+	Events  *events.Bus
+	Preview *capture.Preview // último frame, para la vista previa del panel
+	History *config.History  // configuraciones guardadas, para volver atrás
+
+	log   *slog.Logger
+	sup   *Supervisor   // atiende /start, /pause y /stop del bot
+	cfgCh chan struct{} // avisa al loop de captura que cambió cfg
 }
 
-func NewRunner(cfgPath string, cfg config.Config, st *State, mw *metrics.Writer, bot *telegram.Client) *Runner {
+// NewRunner arma el Runner con la configuración cargada de cfgPath con o (ver
+// config.LoadLayers).
+func NewRunner(cfgPath string, o config.Overrides, l config.Loaded, st *State, db *store.Store, bot *telegram.Client) *Runner {
+	cfg := l.Config
 	return &Runner{
-		CfgPath: cfgPath,
-		cfg:     cfg,
-		State:   st,
-		Metrics: mw,
-		Bot:     bot,
-		ctrlCh:  make(chan ControlState, 10),
+		CfgPath:   cfgPath,
+		Overrides: o,
+		cfg:       cfg,
+		file:      l.File,
+		sources:   l.Sources,
+		cfgHash:   l.Hash,
+		State:     st,
+		Store:     db,
+		Bot:       bot,
+		Slides:    NewSlideLog(),
+		Events:    events.NewBus(eventHistory),
+		Preview:   capture.NewPreview(),
+		History:   config.NewHistory(filepath.Join(filepath.Dir(cfgPath), "history"), cfg.ConfigHistorySize),
+		log:       logs.For("app"),
+		cfgCh:     make(chan struct{}, 1),
 	}
 }
 
-func (r *Runner) ControlChan() chan<- ControlState { return r.ctrlCh }
+func (r *Runner) GetConfig() config.Config {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cfg
+}
 
-func (r *Runner) GetConfig() config.Config { return r.cfg }
+// UpdateConfig valida cfg, la guarda en CfgPath y en el historial y la aplica
+// al loop de captura en curso. source dice de dónde viene el cambio ("bot",
+// "panel (ana)", ...) para el historial. Los campos fijados por el entorno o
+// los flags conservan su valor y no se escriben en el archivo.
+func (r *Runner) UpdateConfig(cfg config.Config, source string) error {
+	r.mu.Lock()
+	saved := cfg
+	ignored := r.Overrides.Keep(&saved, r.cfg, r.file)
+	eff := saved
+	if err := r.Overrides.Apply(&eff, nil); err != nil {
+		r.mu.Unlock()
+		return err
+	}
+	if err := eff.Validate(); err != nil {
+		r.mu.Unlock()
+		return err
+	}
+	saved.ApplyDefaults()
+	eff.ApplyDefaults()
 
-func (r *Runner) UpdateConfig(cfg config.Config) error {
 	// Persistir a disco
-	if err := config.Save(r.CfgPath, cfg); err != nil {
+	if err := config.Save(r.CfgPath, saved); err != nil {
+		r.mu.Unlock()
 		return err
 	}
-	r.cfg = cfg
+	old, oldFile := r.cfg, r.file
+	r.cfg, r.file = eff, saved
+	// Save escribe todos los campos: lo que no tiene override sale del archivo
+	sources := config.Sources{}
+	for path, src := range r.sources {
+		if src == config.SourceDefault {
+			src = config.SourceFile
+		}
+		sources[path] = src
+	}
+	r.sources = sources
+	r.cfgHash, _ = hashFile(r.CfgPath)
+	r.mu.Unlock()
+
+	if len(ignored) > 0 {
+		r.log.Warn("config fields fixed by environment or flags, change ignored", "fields", ignored)
+	}
+	r.record(oldFile, saved, source)
+	r.applyConfig(old, eff)
 	return nil
 }
 
-func (r *Runner) Run(ctx context.Context) error {
-	if err := os.MkdirAll(r.cfg.OutputDir, 0755); err != nil {
-		return err
+// ConfigSources dice de dónde salió cada valor de la configuración efectiva.
+func (r *Runner) ConfigSources() config.Sources {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.sources
+}
+
+// record guarda cfg (la del archivo, sin overrides) en el historial. La
+// primera vez guarda antes la configuración con la que arrancó, para poder
+// volver a ella; si no cambió nada no guarda. Un error acá no deshace el
+// cambio: solo se avisa.
+func (r *Runner) record(old, cfg config.Config, source string) {
+	r.History.SetMax(cfg.ConfigHistorySize)
+	if len(config.Diff(old, cfg)) == 0 {
+		return
+	}
+	if r.History.Len() == 0 {
+		if _, err := r.History.Add(old, "inicial"); err != nil {
+			r.log.Warn("config history", "err", err)
+			return
+		}
+	}
+	v, err := r.History.Add(cfg, source)
+	if err != nil {
+		r.log.Warn("config history", "err", err)
+		return
+	}
+	r.log.Info("config saved", "version", v.Version, "source", source, "profile", cfg.Profile)
+}
+
+// applyConfig avisa al loop de captura, que rehace lo necesario con la
+// configuración vigente (ver reconfigure), y aplica lo que no depende de él.
+// Avisa de los campos de cfg que solo cambian al reiniciar. El loop no recibe
+// cfg sino que la vuelve a leer: con dos cambios a la vez siempre termina en
+// el último.
+func (r *Runner) applyConfig(old, cfg config.Config) {
+	if level, err := logs.ParseLevel(r.GetConfig().LogLevel); err == nil {
+		logs.SetLevel(level)
+	}
+	if fields := restartFields(old, cfg); len(fields) > 0 {
+		r.log.Warn("config changes need a restart", "fields", fields)
+	}
+	// si ya había un aviso pendiente alcanza con ese
+	select {
+	case r.cfgCh <- struct{}{}:
+	default:
 	}
+}
 
-	r.State.SetStatus(StateRunning)
+// restartFields son los campos cambiados que no se aplican en caliente.
+func restartFields(old, cfg config.Config) []string {
+	var out []string
+	add := func(changed bool, field string) {
+		if changed {
+			out = append(out, field)
+		}
+	}
+	add(old.TelegramBotToken != cfg.TelegramBotToken, "telegram_bot_token")
+	add(old.TelegramAPIEndpoint != cfg.TelegramAPIEndpoint, "telegram_api_endpoint")
+	add(!slices.Equal(old.TelegramAllowedUsers, cfg.TelegramAllowedUsers), "telegram_allowed_users")
+	add(old.DatabasePath != cfg.DatabasePath, "database_path")
+	add(old.AdminHTTPAddr != cfg.AdminHTTPAddr, "admin_http_addr")
+	add(old.AdminTLS.Enabled != cfg.AdminTLS.Enabled || old.AdminTLS.CertFile != cfg.AdminTLS.CertFile ||
+		old.AdminTLS.KeyFile != cfg.AdminTLS.KeyFile, "admin_tls")
+	add(old.LogBufferSize != cfg.LogBufferSize, "log_buffer_size")
+	return out
+}
 
-	cam, err := capture.OpenCamera(r.cfg.CameraIndex)
-	if err != nil {
-		r.State.SetError(err.Error())
+// Run es una sesión de captura, abierta por Supervisor.Start: abre la cámara
+// y el OCR, pasa a running y procesa frames hasta que se cancela ctx. Un error
+// al abrir termina la sesión.
+func (r *Runner) Run(ctx context.Context) error {
+	// un cambio que llegó sin sesión ya está en cfg
+	select {
+	case <-r.cfgCh:
+	default:
+	}
+	cfg := r.GetConfig()
+	if err := os.MkdirAll(cfg.OutputDir, 0755); err != nil {
 		return err
 	}
-	defer cam.Close()
 
-	det := capture.NewDetector(
-		r.cfg.Sensitivity,
-		time.Duration(r.cfg.MinSecondsBetweenSlides)*time.Second,
-	)
+	st := r.State.Snapshot()
+	sessionID := st.SessionID
+	session := store.Session{ID: sessionID, Title: st.SessionTitle, StartedAt: st.StartedAt}
+	if err := r.Store.PutSession(session); err != nil {
+		r.log.Error("store session", "session", sessionID, "err", err)
+	}
+	r.log.Info("session started", "session", sessionID, "course", st.SessionTitle, "source", st.SessionSource)
+	defer func() {
+		session.EndedAt = time.Now()
+		if err := r.Store.PutSession(session); err != nil {
+			r.log.Error("store session", "session", sessionID, "err", err)
+		}
+		r.log.Info("session ended", "session", sessionID, "slides", r.State.Snapshot().SlidesCaptured)
+	}()
 
-	tess, err := ocr.NewClient(r.cfg.TesseractLang)
+	p, err := r.openPipeline(ctx, cfg, st.SessionChats)
 	if err != nil {
-		r.State.SetError(err.Error())
 		return err
 	}
-	defer tess.Close()
-
-	ticker := time.NewTicker(time.Second / time.Duration(r.cfg.CaptureFPS))
-	defer ticker.Stop()
+	defer p.Close()
+	// no dejar un álbum a medias al salir
+	defer func() {
+		if err := p.deliv.flush(); err != nil {
+			r.fail("flush album", err)
+		}
+	}()
+	// si ya la están deteniendo no pasa a running: ctx está cancelado
+	_, _ = r.transition(StateRunning)
+	live := &liveSession{ID: sessionID, Title: st.SessionTitle}
 
 	prev := gocv.NewMat()
-	defer prev.Close()
+	// prev se reemplaza si cambia la cámara
+	defer func() { prev.Close() }()
 
 	for {
 		select {
 		case <-ctx.Done():
-			r.State.SetStatus(StateStopped)
 			return nil
 
-		case st := <-r.ctrlCh:
-			switch st {
-			case StatePaused:
-				r.State.SetStatus(StatePaused)
-			case StateRunning:
-				r.State.SetStatus(StateRunning)
-			case StateStopped:
-				r.State.SetStatus(StateStopped)
-				return nil
+		case <-r.cfgCh:
+			camChanged, err := r.reconfigure(p, r.GetConfig())
+			if err != nil {
+				r.fail("apply config", err)
+			}
+			if camChanged {
+				prev.Close()
+				prev = gocv.NewMat()
 			}
 
-		case <-ticker.C:
+		case <-p.ticker.C:
+			if err := p.deliv.flushIfStale(); err != nil {
+				r.fail("flush album", err)
+			}
 			if r.State.Snapshot().Status != StateRunning {
+				// en pausa solo se leen frames si alguien mira la vista previa
+				if r.Preview.Watched() {
+					frame := gocv.NewMat()
+					if p.cam.Read(&frame) && !frame.Empty() {
+						r.Preview.Update(frame, nil)
+					}
+					frame.Close()
+				}
 				continue
 			}
 
 			frame := gocv.NewMat()
-			if ok := cam.Read(&frame); !ok || frame.Empty() {
+			if ok := p.cam.Read(&frame); !ok || frame.Empty() {
 				frame.Close()
 				continue
 			}
 
 			// Primer frame: solo set prev
 			if prev.Empty() {
+				r.Preview.Update(frame, nil)
 				frame.CopyTo(&prev)
 				frame.Close()
 				continue
 			}
 
-			changed, score := det.IsNewSlide(prev, frame)
+			cooldown := p.det.Cooldown()
+			changed, score := p.det.IsNewSlide(prev, frame)
+			r.Preview.Update(frame, p.det)
+			r.publish(events.FrameScored, events.FrameData{
+				Score:     score,
+				Threshold: p.cfg.Sensitivity,
+				Changed:   changed,
+				Cooldown:  cooldown,
+			})
 			if !changed {
 				frame.Close()
 				continue
 			}
 
 			start := time.Now()
+			text, ocrMs, ocrErr := p.tess.ExtractText(frame)
+			r.processSlide(ctx, p, live, capturedFrame{
+				Frame: frame, Score: score, At: start,
+				Text: text, OCRMillis: ocrMs, OCRErr: ocrErr,
+			})
+
+			// actualizar prev
+			frame.CopyTo(&prev)
+			frame.Close()
+		}
+	}
+}
 
-			ts := time.Now().Format("20060102_150405")
-			rawPath := filepath.Join(r.cfg.OutputDir, fmt.Sprintf("slide_%s_raw.jpg", ts))
-			_ = gocv.IMWrite(rawPath, frame)
+// liveSession es lo que el loop de captura recuerda de la sesión en curso.
+type liveSession struct {
+	ID, Title string
+	history   []ocr.Summary // resúmenes anteriores, de contexto para el LLM
+}
 
-			// OCR
-			text, ocrMs, ocrErr := tess.ExtractText(frame)
-			if ocrErr != nil {
-				r.State.SetError(ocrErr.Error())
-			}
+// capturedFrame es un frame que el detector marcó como diapositiva nueva, con
+// el texto que le sacó el OCR.
+type capturedFrame struct {
+	Frame     gocv.Mat
+	Score     float64
+	At        time.Time
+	Text      string
+	OCRMillis int64
+	OCRErr    error
+}
 
-			summary := ocr.Summarize(text)
+// processSlide corrige y resume el texto de f, guarda sus archivos, la envía
+// (o actualiza la anterior si es una revelación incremental) y la registra en
+// la base, el índice, los eventos y las métricas.
+func (r *Runner) processSlide(ctx context.Context, p *pipeline, live *liveSession, f capturedFrame) {
+	frame, score, start := f.Frame, f.Score, f.At
+	text, ocrMs, ocrErr := f.Text, f.OCRMillis, f.OCRErr
+	if p.corrector != nil {
+		text = p.corrector.Correct(text)
+	}
+	summary := p.summarizer.Summarize(ctx, text, live.history)
+
+	// una revelación incremental conserva el ID (y los nombres de
+	// archivo) de la diapositiva que actualiza
+	ts := start.Format("20060102_150405")
+	rv := p.deliv.reveals(summary)
+	build := rv != nil
+	if build {
+		ts = rv.prev.ID
+	}
+	if ocrErr != nil {
+		r.fail("ocr", ocrErr, "slide", ts)
+	}
 
-			finalPath := rawPath
-			if r.cfg.EnableAnnotation {
-				ann := annotate.Annotate(frame, summary.Keywords)
-				annotatedPath := filepath.Join(r.cfg.OutputDir, fmt.Sprintf("slide_%s_annotated.jpg", ts))
-				_ = gocv.IMWrite(annotatedPath, ann)
-				ann.Close()
-				finalPath = annotatedPath
-			}
+	rawPath := filepath.Join(p.cfg.OutputDir, fmt.Sprintf("slide_%s_raw.jpg", ts))
+	_ = gocv.IMWrite(rawPath, frame)
+	r.publish(events.SlideDetected, events.SlideData{Slide: ts, Score: score, RawPath: rawPath, Build: build})
+	ocrDone := events.OCRData{Slide: ts, Chars: len(text), Millis: ocrMs, Title: summary.Title}
+	if ocrErr != nil {
+		ocrDone.Error = ocrErr.Error()
+	}
+	r.publish(events.OCRDone, ocrDone)
+	if n := p.cfg.LLMContextSlides; n > 0 {
+		live.history = append(live.history, summary)
+		if len(live.history) > n {
+			live.history = live.history[len(live.history)-n:]
+		}
+	}
+
+	var attachments []string
+	if len(summary.Regions) > 0 {
+		attachments = r.writeRegionFiles(p.cfg.OutputDir, ts, summary)
+	}
+
+	finalPath := rawPath
+	if p.cfg.EnableAnnotation {
+		ann := annotate.Annotate(frame, summary.Keywords)
+		annotatedPath := filepath.Join(p.cfg.OutputDir, fmt.Sprintf("slide_%s_annotated.jpg", ts))
+		_ = gocv.IMWrite(annotatedPath, ann)
+		ann.Close()
+		finalPath = annotatedPath
+	}
+
+	caption := ocr.BuildCaption(summary, p.cfg.MaxCaptionChars, score)
+	slide := &Slide{
+		ID:           ts,
+		CapturedAt:   start,
+		RawPath:      rawPath,
+		Path:         finalPath,
+		Caption:      caption,
+		Summary:      summary,
+		Score:        score,
+		Session:      live.ID,
+		SessionTitle: live.Title,
+		Attachments:  attachments,
+		Thumb:        writeThumbnail(frame, filepath.Join(p.cfg.OutputDir, fmt.Sprintf("slide_%s_thumb.jpg", ts))),
+		OCRMillis:    ocrMs,
+	}
+	if ocrErr != nil {
+		slide.OCRError = ocrErr.Error()
+	}
+	sendStart := time.Now()
+	sendErr := p.deliv.send(slide, rv)
+	sendMs := time.Since(sendStart).Milliseconds()
+	if sendErr != nil {
+		r.fail("send slide", sendErr, "slide", ts)
+	}
+	if build {
+		removeSuperseded(rv.prev, slide, p.cfg.OutputDir, r.log)
+	}
+	// en una revelación deliv.last ya tiene el contenido nuevo
+	saved := r.saveSlide(p.deliv.last)
+	r.publish(events.SlideSent, sentData(saved, build, sendMs))
+
+	totalMs := time.Since(start).Milliseconds()
+
+	// una revelación incremental actualiza la diapositiva anterior
+	r.log.Info("slide captured", "slide", slide.ID, "score", score, "build", build,
+		"ocr_ms", ocrMs, "send_ms", sendMs, "total_ms", totalMs)
+	if !build {
+		r.State.MarkSlideCaptured()
+		metrics.SlidesSent.Inc()
+	} else {
+		metrics.Builds.Inc()
+	}
+	metrics.Processing.ObserveSince(start)
+	rec := metrics.Record{
+		TimeISO:      time.Now().Format(time.RFC3339),
+		Course:       p.cfg.CourseName,
+		Session:      live.ID,
+		SlidePath:    finalPath,
+		RawPath:      rawPath,
+		ChangeScore:  score,
+		OCRMillis:    ocrMs,
+		TotalMillis:  totalMs,
+		SendMillis:   sendMs,
+		TextChars:    len(text),
+		CaptionChars: len(caption),
+		SendOK:       sendErr == nil,
+		OCROK:        ocrErr == nil,
+		Build:        build,
+		MessageIDs:   r.Slides.Get(p.deliv.last).Messages,
+		Regions:      ocr.RegionKinds(summary.Regions),
+		Error:        pickErr(ocrErr, sendErr),
+	}
+	if err := r.Store.AddMetric(rec); err != nil {
+		r.log.Error("store metric", "err", err)
+	}
+}
+
+// fail registra err y lo deja como último error del estado.
+func (r *Runner) fail(msg string, err error, args ...any) {
+	r.log.Error(msg, append(args, "err", err)...)
+	r.State.SetError(err.Error())
+	data := events.ErrorData{Message: msg, Error: err.Error()}
+	for i := 0; i+1 < len(args); i += 2 {
+		if args[i] == "slide" {
+			data.Slide, _ = args[i+1].(string)
+		}
+	}
+	r.publish(events.Error, data)
+}
+
+// transition cambia el estado si está permitido (ver State.Transition) y lo
+// publica.
+func (r *Runner) transition(to ControlState) (Transition, error) {
+	from, err := r.State.Transition(to)
+	if err != nil {
+		return Transition{}, err
+	}
+	return r.stateChanged(from, to), nil
+}
+
+// stateChanged registra y publica un cambio de estado ya hecho.
+func (r *Runner) stateChanged(from, to ControlState) Transition {
+	t := Transition{From: from, To: to, Session: r.State.Snapshot().SessionID}
+	r.log.Info("state changed", "from", string(from), "to", string(to), "session", t.Session)
+	r.publish(events.StateChanged, events.StateData{Status: string(to), Previous: string(from)})
+	return t
+}
+
+// eventHistory es cuántos eventos (sin contar frames) se reenvían al reconectar.
+const eventHistory = 200
 
-			caption := ocr.BuildCaption(summary, r.cfg.MaxCaptionChars, score)
-			sendErr := r.Bot.SendPhotoWithCaption(finalPath, caption)
-			if sendErr != nil {
-				r.State.SetError(sendErr.Error())
+func (r *Runner) publish(t events.Type, data any) {
+	r.Events.Publish(t, r.State.Snapshot().SessionID, data)
+}
+
+func sentData(s store.Slide, build bool, ms int64) events.SentData {
+	d := events.SentData{Slide: s.ID, Build: build, Millis: ms}
+	for chat, dl := range s.Delivery {
+		if dl.MessageID != 0 {
+			if d.Messages == nil {
+				d.Messages = map[int64]int{}
+			}
+			d.Messages[chat] = dl.MessageID
+		}
+		if dl.Error != "" {
+			if d.Errors == nil {
+				d.Errors = map[int64]string{}
 			}
+			d.Errors[chat] = dl.Error
+		}
+	}
+	return d
+}
 
-			totalMs := time.Since(start).Milliseconds()
-
-			r.State.MarkSlideCaptured()
-			r.Metrics.Write(metrics.Record{
-				TimeISO:      time.Now().Format(time.RFC3339),
-				SlidePath:    finalPath,
-				RawPath:      rawPath,
-				ChangeScore:  score,
-				OCRMillis:    ocrMs,
-				TotalMillis:  totalMs,
-				TextChars:    len(text),
-				CaptionChars: len(caption),
-				SendOK:       sendErr == nil,
-				OCROK:        ocrErr == nil,
-				Error:        pickErr(ocrErr, sendErr),
-			})
+// writeRegionFiles guarda las notas en Markdown y cada tabla como CSV junto a
+// la imagen. Devuelve las rutas de los CSV.
+func (r *Runner) writeRegionFiles(dir, ts string, summary ocr.Summary) []string {
+	notesPath := filepath.Join(dir, fmt.Sprintf("slide_%s_notes.md", ts))
+	_ = os.WriteFile(notesPath, []byte(ocr.BuildNotes(summary)), 0644)
+
+	var csvPaths []string
+	n := 0
+	for _, reg := range summary.Regions {
+		if reg.Kind != ocr.RegionTable {
+			continue
+		}
+		n++
+		csvPath := filepath.Join(dir, fmt.Sprintf("slide_%s_table%d.csv", ts, n))
+		if err := os.WriteFile(csvPath, []byte(reg.CSV()), 0644); err == nil {
+			csvPaths = append(csvPaths, csvPath)
+		}
+	}
+	return csvPaths
+}
 
-			// actualizar prev
-			frame.CopyTo(&prev)
-			frame.Close()
+// removeSuperseded borra los archivos de old que la revelación cur ya no usa
+// (p. ej. una tabla que dejó de estar, o las notas si ya no hay regiones); los
+// del mismo nombre ya se sobrescribieron.
+func removeSuperseded(old Slide, cur *Slide, outputDir string, log *slog.Logger) {
+	keep := map[string]bool{}
+	for _, f := range slideFiles(storeSlide(cur), outputDir) {
+		keep[f] = true
+	}
+	if len(cur.Summary.Regions) == 0 {
+		notes, _ := paths.Within(outputDir, filepath.Join(outputDir, fmt.Sprintf("slide_%s_notes.md", cur.ID)))
+		delete(keep, notes)
+	}
+	for _, f := range slideFiles(storeSlide(&old), outputDir) {
+		if keep[f] {
+			continue
+		}
+		if err := os.Remove(f); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Warn("remove superseded file", "slide", old.ID, "path", f, "err", err)
 		}
 	}
 }
 
+// thumbWidth es el ancho de las miniaturas de búsqueda.
+const thumbWidth = 320
+
+func writeThumbnail(frame gocv.Mat, path string) string {
+	if frame.Cols() == 0 {
+		return ""
+	}
+	thumb := gocv.NewMat()
+	defer thumb.Close()
+	h := frame.Rows() * thumbWidth / frame.Cols()
+	gocv.Resize(frame, &thumb, image.Pt(thumbWidth, h), 0, 0, gocv.InterpolationArea)
+	if !gocv.IMWrite(path, thumb) {
+		return ""
+	}
+	return path
+}
+
+// saveSlide guarda la diapositiva en la base y la agrega al índice de búsqueda.
+// Devuelve lo guardado.
+func (r *Runner) saveSlide(s *Slide) store.Slide {
+	if s == nil {
+		return store.Slide{}
+	}
+	var rec store.Slide
+	r.Slides.Update(s, func(s *Slide) { rec = storeSlide(s) })
+	if err := r.Store.PutSlide(rec); err != nil {
+		r.log.Error("store slide", "slide", rec.ID, "err", err)
+	}
+	if r.Index != nil {
+		r.Index.Add(searchDoc(rec, s.SessionTitle))
+	}
+	return rec
+}
+
+func storeSlide(s *Slide) store.Slide {
+	rec := store.Slide{
+		ID:          s.ID,
+		Session:     s.Session,
+		CapturedAt:  s.CapturedAt,
+		RawPath:     s.RawPath,
+		Path:        s.Path,
+		Thumb:       s.Thumb,
+		Attachments: s.Attachments,
+		Score:       s.Score,
+		OCRMillis:   s.OCRMillis,
+		OCRError:    s.OCRError,
+		Summary:     s.Summary,
+		Caption:     s.Caption,
+		Builds:      s.Builds,
+		Delivery:    map[int64]store.Delivery{},
+	}
+	for chat, id := range s.Messages {
+		d := rec.Delivery[chat]
+		d.MessageID = id
+		rec.Delivery[chat] = d
+	}
+	for chat, ids := range s.AttachmentMsgs {
+		d := rec.Delivery[chat]
+		d.Attachments = append([]int(nil), ids...)
+		rec.Delivery[chat] = d
+	}
+	for chat, e := range s.Errors {
+		d := rec.Delivery[chat]
+		d.Error = e
+		rec.Delivery[chat] = d
+	}
+	return rec
+}
+
+func searchDoc(s store.Slide, sessionTitle string) search.Doc {
+	return search.Doc{
+		ID:           s.ID,
+		Session:      s.Session,
+		SessionTitle: sessionTitle,
+		CapturedAt:   s.CapturedAt,
+		Path:         s.Path,
+		RawPath:      s.RawPath,
+		Thumb:        s.Thumb,
+		Summary:      s.Summary,
+	}
+}
+
+// LoadIndex arma el índice de búsqueda con las diapositivas de la base.
+func LoadIndex(db *store.Store) (*search.Index, error) {
+	sessions, err := db.Sessions()
+	if err != nil {
+		return nil, err
+	}
+	titles := map[string]string{}
+	for _, s := range sessions {
+		titles[s.ID] = s.Title
+	}
+	slides, err := db.Slides(store.SlideQuery{})
+	if err != nil {
+		return nil, err
+	}
+	idx := search.New()
+	for _, s := range slides {
+		idx.Add(searchDoc(s, titles[s.Session]))
+	}
+	return idx, nil
+}
+
+// NewSummarizer devuelve el resumidor de summarizer_backend.
+func NewSummarizer(cfg config.Config) ocr.Summarizer {
+	if cfg.SummarizerBackend == "llm" {
+		return ocr.NewLLMSummarizer(
+			cfg.LLMEndpoint,
+			cfg.LLMModel,
+			cfg.LLMAPIKey,
+			time.Duration(cfg.LLMTimeoutSeconds)*time.Second,
+		)
+	}
+	return ocr.RuleSummarizer{}
+}
+
 func pickErr(a, b error) string {
 	if a != nil {
 		return a.Error()
@@ -0,0 +1,363 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gocv.io/x/gocv"
+
+	"IA1_EV2025_Proyecto2/internal/config"
+	"IA1_EV2025_Proyecto2/internal/events"
+	"IA1_EV2025_Proyecto2/internal/ocr"
+	"IA1_EV2025_Proyecto2/internal/store"
+	"IA1_EV2025_Proyecto2/internal/telegram"
+	"IA1_EV2025_Proyecto2/internal/telegram/fakebot"
+)
+
+const testChat int64 = -100123
+
+// testCapture arma un Runner con base, carpeta de salida y bot apuntando a
+// fakebot, y el pipeline de una sesión en curso sin cámara ni Tesseract: los
+// frames llegan con su texto OCR ya puesto.
+type testCapture struct {
+	t    *testing.T
+	r    *Runner
+	p    *pipeline
+	live *liveSession
+	bot  *fakebot.Server
+	at   time.Time
+}
+
+func newTestCapture(t *testing.T, tweak func(*config.Config)) *testCapture {
+	t.Helper()
+	dir := t.TempDir()
+	cfg := config.Config{TelegramChatID: testChat, OutputDir: dir, EnableAnnotation: true}
+	cfg.ApplyDefaults()
+	if tweak != nil {
+		tweak(&cfg)
+	}
+
+	srv := fakebot.Start()
+	t.Cleanup(srv.Close)
+	bot, err := telegram.NewWithEndpoint("123:TEST", srv.Endpoint())
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.Reset()
+
+	db, err := store.Open(filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	r := NewRunner(filepath.Join(dir, "config.json"), config.Overrides{}, config.Loaded{Config: cfg, File: cfg}, NewState(), db, bot)
+	sessionID := r.State.StartSession(SessionOptions{Title: "IA 1", Source: "manual"})
+	p := &pipeline{
+		cfg:        cfg,
+		summarizer: ocr.RuleSummarizer{},
+		deliv:      newDelivery(bot, r.Slides, cfg, nil, r.log),
+	}
+	return &testCapture{
+		t:    t,
+		r:    r,
+		p:    p,
+		live: &liveSession{ID: sessionID, Title: "IA 1"},
+		bot:  srv,
+		at:   time.Date(2026, 10, 19, 8, 0, 0, 0, time.Local),
+	}
+}
+
+// frame pasa por el pipeline un frame con ese texto OCR, como si el detector
+// lo hubiera marcado como diapositiva nueva.
+func (c *testCapture) frame(text string) {
+	c.t.Helper()
+	c.frameScore(text, 0.4)
+}
+
+// frameScore es frame con otro cambio respecto al frame anterior.
+func (c *testCapture) frameScore(text string, score float64) {
+	c.t.Helper()
+	f := gocv.NewMatWithSize(120, 160, gocv.MatTypeCV8UC3)
+	defer f.Close()
+	c.at = c.at.Add(5 * time.Second)
+	c.r.processSlide(context.Background(), c.p, c.live, capturedFrame{
+		Frame: f, Score: score, At: c.at, Text: text, OCRMillis: 12,
+	})
+}
+
+func (c *testCapture) slides() []Slide {
+	return c.r.Slides.Last(0)
+}
+
+func (c *testCapture) stored(id string) store.Slide {
+	c.t.Helper()
+	sl, ok, err := c.r.Store.Slide(id)
+	if err != nil || !ok {
+		c.t.Fatalf("diapositiva %s no guardada (err %v)", id, err)
+	}
+	return sl
+}
+
+const (
+	slideRedes   = "Redes neuronales\nEl perceptrón combina entradas con pesos\nLa activación decide la salida"
+	slideRedes2  = slideRedes + "\nSe entrena con descenso por gradiente"
+	slideArboles = "Árboles de decisión\nCada nodo divide los datos\nLas hojas dan la clase"
+	slideBayes   = "Clasificador bayesiano\nSupone independencia entre atributos\nEs rápido de entrenar"
+)
+
+func TestCaptureSendsPhoto(t *testing.T) {
+	c := newTestCapture(t, nil)
+	c.frame(slideRedes)
+
+	calls := c.bot.Calls("sendPhoto")
+	if len(calls) != 1 {
+		t.Fatalf("sendPhoto: %d llamadas", len(calls))
+	}
+	sent := calls[0]
+	if sent.ChatID != testChat || len(sent.Files) != 1 {
+		t.Errorf("sendPhoto = %+v", sent)
+	}
+	if caption := sent.Param("caption"); !strings.Contains(caption, "Redes neuronales") {
+		t.Errorf("caption = %q", caption)
+	}
+
+	slides := c.slides()
+	if len(slides) != 1 {
+		t.Fatalf("%d diapositivas registradas", len(slides))
+	}
+	sl := slides[0]
+	if sl.Messages[testChat] != sent.MessageIDs[0] || len(sl.Errors) != 0 {
+		t.Errorf("mensajes = %v, errores = %v", sl.Messages, sl.Errors)
+	}
+	for _, f := range []string{sl.RawPath, sl.Path} {
+		if _, err := os.Stat(f); err != nil {
+			t.Errorf("falta %s: %v", f, err)
+		}
+	}
+	if got := c.stored(sl.ID); got.Session != c.live.ID || got.Delivery[testChat].MessageID != sent.MessageIDs[0] {
+		t.Errorf("guardada = %+v", got)
+	}
+	if n := c.r.State.Snapshot().SlidesCaptured; n != 1 {
+		t.Errorf("SlidesCaptured = %d", n)
+	}
+}
+
+func TestCaptureBuildEditsMessage(t *testing.T) {
+	c := newTestCapture(t, nil)
+	c.frame(slideRedes)
+	c.frame(slideRedes2)
+
+	photos := c.bot.Calls("sendPhoto")
+	edits := c.bot.Calls("editMessageMedia")
+	if len(photos) != 1 || len(edits) != 1 {
+		t.Fatalf("sendPhoto: %d, editMessageMedia: %d", len(photos), len(edits))
+	}
+	if edits[0].Param("message_id") == "" || edits[0].MessageIDs[0] != photos[0].MessageIDs[0] {
+		t.Errorf("se editó %v, se envió %v", edits[0].MessageIDs, photos[0].MessageIDs)
+	}
+
+	slides := c.slides()
+	if len(slides) != 1 {
+		t.Fatalf("la revelación agregó una diapositiva: %d", len(slides))
+	}
+	sl := slides[0]
+	if sl.Builds != 1 || !strings.Contains(sl.Summary.RawText, "descenso por gradiente") {
+		t.Errorf("Builds = %d, texto = %q", sl.Builds, sl.Summary.RawText)
+	}
+	if got := c.stored(sl.ID); got.Builds != 1 {
+		t.Errorf("guardada con Builds = %d", got.Builds)
+	}
+	if n := c.r.State.Snapshot().SlidesCaptured; n != 1 {
+		t.Errorf("SlidesCaptured = %d", n)
+	}
+
+	// los eventos de la revelación usan el ID de la diapositiva que actualiza
+	var detected, sent []string
+	for _, ev := range c.r.Events.Since(0) {
+		switch d := ev.Data.(type) {
+		case events.SlideData:
+			detected = append(detected, d.Slide)
+		case events.SentData:
+			sent = append(sent, d.Slide)
+		}
+	}
+	want := []string{sl.ID, sl.ID}
+	if strings.Join(detected, ",") != strings.Join(want, ",") || strings.Join(sent, ",") != strings.Join(want, ",") {
+		t.Errorf("slide_detected %v, slide_sent %v; se esperaba %s en todos", detected, sent, sl.ID)
+	}
+}
+
+// Una revelación se actualiza donde se publicó la original aunque el contenido
+// nuevo ya no pase los filtros, y no llega a chats que la original no pasó.
+func TestCaptureBuildFollowsOriginal(t *testing.T) {
+	const chatA, chatB int64 = -1001, -1002
+	c := newTestCapture(t, func(cfg *config.Config) {
+		cfg.Destinations = []config.Destination{
+			{Name: "a", ChatID: chatA, MinChangeScore: 0.3},
+			{Name: "b", ChatID: chatB, Keywords: []string{"gradiente"}},
+		}
+	})
+	c.frame(slideRedes)
+	c.frameScore(slideRedes2, 0.1)
+
+	photos := c.bot.Calls("sendPhoto")
+	if len(photos) != 1 || photos[0].ChatID != chatA {
+		t.Fatalf("sendPhoto = %+v", photos)
+	}
+	edits := c.bot.Calls("editMessageMedia")
+	if len(edits) != 1 || edits[0].ChatID != chatA {
+		t.Errorf("editMessageMedia = %+v", edits)
+	}
+	sl := c.slides()[0]
+	if _, ok := sl.Messages[chatB]; ok || len(sl.Errors) != 0 {
+		t.Errorf("mensajes = %v, errores = %v", sl.Messages, sl.Errors)
+	}
+}
+
+func TestCaptureBuildDiff(t *testing.T) {
+	c := newTestCapture(t, func(cfg *config.Config) { cfg.BuildMode = "diff" })
+	c.frame(slideRedes)
+	c.frame(slideRedes2)
+
+	replies := c.bot.Calls("sendMessage")
+	if len(replies) != 1 || len(c.bot.Calls("editMessageMedia")) != 0 {
+		t.Fatalf("sendMessage: %d", len(replies))
+	}
+	if text := replies[0].Param("text"); !strings.Contains(text, "descenso por gradiente") {
+		t.Errorf("respuesta = %q", text)
+	}
+	if replies[0].Param("reply_to_message_id") == "" {
+		t.Error("las líneas nuevas no responden al mensaje de la diapositiva")
+	}
+}
+
+func TestCaptureAlbum(t *testing.T) {
+	c := newTestCapture(t, func(cfg *config.Config) { cfg.AlbumSize = 3 })
+	c.frame(slideRedes)
+	c.frame(slideArboles)
+	if n := len(c.bot.Calls()); n != 0 {
+		t.Fatalf("se envió antes de completar el álbum: %d llamadas", n)
+	}
+	c.frame(slideBayes)
+
+	albums := c.bot.Calls("sendMediaGroup")
+	if len(albums) != 1 || len(albums[0].Files) != 3 {
+		t.Fatalf("sendMediaGroup = %+v", albums)
+	}
+	for _, sl := range c.slides() {
+		if sl.Messages[testChat] == 0 {
+			t.Errorf("%s sin mensaje", sl.ID)
+		}
+	}
+
+	// lo pendiente al cerrar la sesión se envía igual
+	c.frame(slideRedes)
+	if err := c.p.deliv.flush(); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(c.bot.Calls("sendPhoto")); n != 1 {
+		t.Errorf("el álbum de una foto no se envió: sendPhoto %d", n)
+	}
+}
+
+func TestCaptureRetries(t *testing.T) {
+	c := newTestCapture(t, nil)
+	c.bot.FailNext("sendPhoto", http.StatusTooManyRequests, 1)
+	c.frame(slideRedes)
+
+	if n := len(c.bot.Calls("sendPhoto")); n != 2 {
+		t.Fatalf("sendPhoto: %d llamadas, se esperaba un reintento", n)
+	}
+	sl := c.slides()[0]
+	if sl.Messages[testChat] == 0 || len(sl.Errors) != 0 {
+		t.Errorf("mensajes = %v, errores = %v", sl.Messages, sl.Errors)
+	}
+
+	// un error que no se reintenta queda en la diapositiva y en el estado
+	c.bot.FailNext("sendPhoto", http.StatusBadRequest, 0)
+	c.frame(slideArboles)
+	last := c.slides()[1]
+	if last.Errors[testChat] == "" || last.Messages[testChat] != 0 {
+		t.Errorf("mensajes = %v, errores = %v", last.Messages, last.Errors)
+	}
+	if got := c.stored(last.ID); got.Delivery[testChat].Error == "" {
+		t.Errorf("guardada sin el error: %+v", got.Delivery)
+	}
+	if c.r.State.Snapshot().LastError == "" {
+		t.Error("el error no llegó al estado")
+	}
+}
+
+// Reenviar desde el panel mientras la captura sigue no debe leer y escribir los
+// mensajes de la diapositiva a la vez (go test -race).
+func TestResendDuringCapture(t *testing.T) {
+	c := newTestCapture(t, nil)
+	c.frame(slideRedes)
+	id := c.slides()[0].ID
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 5; i++ {
+			if _, err := c.r.ResendSlide(id, nil); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	c.frame(slideRedes2)
+	c.frame(slideRedes2 + "\nCon regularización se evita el sobreajuste")
+	<-done
+
+	if n := len(c.bot.Calls("sendPhoto")); n != 6 {
+		t.Errorf("sendPhoto: %d llamadas", n)
+	}
+}
+
+// /last, /slides y /export solo ven la sesión actual.
+func TestCommandsCurrentSession(t *testing.T) {
+	c := newTestCapture(t, nil)
+	c.frame(slideRedes)
+	c.frame(slideArboles)
+
+	c.live.ID = c.r.State.StartSession(SessionOptions{Title: "IA 2", Source: "manual"})
+	c.frame(slideBayes)
+
+	last := c.r.HandleCommand(telegram.Command{Name: "last"})
+	if !strings.Contains(last.Text, "bayesiano") {
+		t.Errorf("/last = %q", last.Text)
+	}
+	list := c.r.HandleCommand(telegram.Command{Name: "slides", Args: "5"})
+	if !strings.Contains(list.Text, "bayesiano") || strings.Contains(list.Text, "Redes") {
+		t.Errorf("/slides mezcla sesiones: %q", list.Text)
+	}
+	exp := c.r.HandleCommand(telegram.Command{Name: "export"})
+	b, err := os.ReadFile(exp.Document)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if notes := string(b); !strings.Contains(notes, "bayesiano") || strings.Contains(notes, "Redes") {
+		t.Errorf("/export mezcla sesiones:\n%s", notes)
+	}
+}
+
+func TestSlideLogMax(t *testing.T) {
+	l := NewSlideLog()
+	l.max = 3
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		l.Add(&Slide{ID: id, Session: "s"})
+	}
+	var ids []string
+	for _, s := range l.Session("s", 0) {
+		ids = append(ids, s.ID)
+	}
+	if strings.Join(ids, "") != "cde" || l.Find("a") != nil {
+		t.Errorf("quedaron %v", ids)
+	}
+}
@@ -0,0 +1,152 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"time"
+
+	"IA1_EV2025_Proyecto2/internal/config"
+	"IA1_EV2025_Proyecto2/internal/logs"
+	"IA1_EV2025_Proyecto2/internal/schedule"
+)
+
+// scheduleTick es cada cuánto el planificador revisa el horario.
+const scheduleTick = 15 * time.Second
+
+// upcomingSlots son las clases que se muestran en /status.
+const upcomingSlots = 5
+
+// Scheduler inicia y detiene la captura según el horario (schedule y
+// schedule_ics). Solo detiene las sesiones que inició él: una clase detenida a
+// mano no se vuelve a iniciar y una sesión manual no se corta al empezar ni al
+// terminar una clase.
+type Scheduler struct {
+	sup *Supervisor
+	r   *Runner
+	log *slog.Logger
+
+	// el .ics se vuelve a leer si cambia la ruta o el archivo
+	icsPath string
+	icsMod  time.Time
+	icsErr  string
+	events  []schedule.Event
+
+	handled  string    // Key de la última clase atendida
+	owned    string    // sesión que inició el horario
+	ownedEnd time.Time // y cuándo termina su clase
+}
+
+func NewScheduler(sup *Supervisor) *Scheduler {
+	return &Scheduler{sup: sup, r: sup.r, log: logs.For("schedule")}
+}
+
+// LoadSchedule arma el horario de cfg leyendo schedule_ics, si hay. skipped
+// son los eventos del calendario que se omitieron (ver schedule.ParseICS).
+func LoadSchedule(cfg config.Config) (sched schedule.Schedule, skipped []error, err error) {
+	sched = schedule.Schedule{Weekly: cfg.WeeklySchedule()}
+	if cfg.ScheduleICS == "" {
+		return sched, nil, nil
+	}
+	sched.Events, skipped, err = schedule.LoadICS(cfg.ScheduleICS)
+	return sched, skipped, err
+}
+
+// Run revisa el horario hasta que se cancela ctx.
+func (s *Scheduler) Run(ctx context.Context) {
+	t := time.NewTicker(scheduleTick)
+	defer t.Stop()
+	for {
+		s.tick(time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func (s *Scheduler) tick(now time.Time) {
+	cfg := s.r.GetConfig()
+	sched := schedule.Schedule{Weekly: cfg.WeeklySchedule(), Events: s.loadICS(cfg.ScheduleICS)}
+	s.r.State.SetUpcoming(sched.Upcoming(now, upcomingSlots))
+	st := s.r.State.Snapshot()
+
+	if s.owned != "" {
+		active := st.SessionID == s.owned && (st.Status == StateStarting || st.Status == StateRunning || st.Status == StatePaused)
+		switch {
+		case !active:
+			// la detuvieron a mano o terminó con error
+			s.owned = ""
+		case !now.Before(s.ownedEnd):
+			s.log.Info("scheduled session ended", "session", s.owned)
+			if _, err := s.sup.Stop(); err != nil {
+				s.log.Warn("stop scheduled session", "session", s.owned, "err", err)
+			}
+			s.owned = ""
+			return
+		default:
+			return
+		}
+	}
+
+	cur, ok := sched.Current(now)
+	if !ok || cur.Key() == s.handled {
+		return
+	}
+	if st.Status == StateStopping {
+		// la anterior todavía está cerrando: se reintenta en el próximo tick
+		return
+	}
+	s.handled = cur.Key()
+	if st.Status != StateIdle && st.Status != StateError {
+		s.log.Info("scheduled session skipped, capture already active", "title", cur.Title, "status", string(st.Status))
+		return
+	}
+
+	o := SessionOptions{Title: cur.Title, ChatIDs: cur.ChatIDs, Profile: cur.Profile, Source: "horario"}
+	// en el .ics el perfil es el lugar, si hay un perfil con ese nombre
+	if _, ok := cfg.Profiles[cur.Location]; ok && o.Profile == "" {
+		o.Profile = cur.Location
+	}
+	t, err := s.sup.StartSession(o)
+	if err != nil {
+		s.r.fail("start scheduled session", err)
+		return
+	}
+	s.owned, s.ownedEnd = t.Session, cur.End
+	s.log.Info("scheduled session started", "session", t.Session, "title", cur.Title, "until", cur.End.Format("15:04"), "source", cur.Source)
+}
+
+// loadICS devuelve los eventos de path, leyéndolo de nuevo si cambió. Si no se
+// puede leer se conservan los anteriores y se avisa una vez.
+func (s *Scheduler) loadICS(path string) []schedule.Event {
+	if path != s.icsPath {
+		s.icsPath, s.icsMod, s.icsErr, s.events = path, time.Time{}, "", nil
+	}
+	if path == "" {
+		return nil
+	}
+	fi, err := os.Stat(path)
+	if err == nil && fi.ModTime().Equal(s.icsMod) {
+		return s.events
+	}
+	var events []schedule.Event
+	var skipped []error
+	if err == nil {
+		events, skipped, err = schedule.LoadICS(path)
+	}
+	if err != nil {
+		if err.Error() != s.icsErr {
+			s.icsErr = err.Error()
+			s.log.Warn("schedule ics", "path", path, "err", err)
+		}
+		return s.events
+	}
+	s.icsMod, s.icsErr, s.events = fi.ModTime(), "", events
+	for _, err := range skipped {
+		s.log.Warn("schedule ics event skipped", "path", path, "err", err)
+	}
+	s.log.Info("schedule ics loaded", "path", path, "events", len(events), "skipped", len(skipped))
+	return events
+}
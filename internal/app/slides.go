@@ -0,0 +1,184 @@
+package app
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"IA1_EV2025_Proyecto2/internal/ocr"
+)
+
+// Slide es una diapositiva capturada y lo que se publicó de ella en Telegram.
+type Slide struct {
+	ID         string // timestamp usado en los nombres de archivo
+	CapturedAt time.Time
+	RawPath    string
+	Path       string // imagen enviada (anotada o cruda)
+	Caption    string
+	Summary    ocr.Summary
+
+	Score        float64 // cambio respecto al frame anterior (0..1)
+	Session      string  // ID de la sesión
+	SessionTitle string
+	Attachments  []string // CSV de tablas, etc.
+	Thumb        string
+
+	OCRMillis int64
+	OCRError  string
+	Builds    int // revelaciones incrementales recibidas
+
+	// Mensajes publicados por chat; una diapositiva sin entrada para un chat aún
+	// no se envió allí (filtrada o pendiente en un álbum)
+	Messages       map[int64]int
+	AttachmentMsgs map[int64][]int  // adjuntos, en respuesta al mensaje de la diapositiva
+	Errors         map[int64]string // último error de envío por chat
+}
+
+func (s *Slide) copy() Slide {
+	c := *s
+	c.Messages = make(map[int64]int, len(s.Messages))
+	for k, v := range s.Messages {
+		c.Messages[k] = v
+	}
+	c.AttachmentMsgs = make(map[int64][]int, len(s.AttachmentMsgs))
+	for k, v := range s.AttachmentMsgs {
+		c.AttachmentMsgs[k] = append([]int(nil), v...)
+	}
+	c.Errors = make(map[int64]string, len(s.Errors))
+	for k, v := range s.Errors {
+		c.Errors[k] = v
+	}
+	return c
+}
+
+// maxSlideLog es cuántas diapositivas guarda SlideLog; las anteriores siguen en
+// la base.
+const maxSlideLog = 500
+
+// SlideLog guarda las últimas diapositivas capturadas desde que arrancó el
+// programa, de todas las sesiones.
+type SlideLog struct {
+	mu     sync.RWMutex
+	slides []*Slide
+	max    int
+}
+
+func NewSlideLog() *SlideLog {
+	return &SlideLog{max: maxSlideLog}
+}
+
+// Add registra s y descarta la más antigua si se pasa del máximo.
+func (l *SlideLog) Add(s *Slide) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.slides = append(l.slides, s)
+	if extra := len(l.slides) - l.max; extra > 0 {
+		clear(l.slides[:extra])
+		l.slides = l.slides[extra:]
+	}
+}
+
+// Update modifica una diapositiva ya registrada sin carreras con los lectores.
+func (l *SlideLog) Update(s *Slide, fn func(*Slide)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fn(s)
+}
+
+// Get devuelve una copia de s, que ya está registrada: sus mapas se pueden
+// leer sin el lock aunque el envío o el panel la sigan modificando.
+func (l *SlideLog) Get(s *Slide) Slide {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return s.copy()
+}
+
+// Last devuelve copias de las últimas n diapositivas (la más reciente al final).
+func (l *SlideLog) Last(n int) []Slide {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if n <= 0 || n > len(l.slides) {
+		n = len(l.slides)
+	}
+	out := make([]Slide, 0, n)
+	for _, s := range l.slides[len(l.slides)-n:] {
+		out = append(out, s.copy())
+	}
+	return out
+}
+
+// Session devuelve copias de las últimas n diapositivas de la sesión session
+// (todas si n <= 0), la más reciente al final.
+func (l *SlideLog) Session(session string, n int) []Slide {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	var out []Slide
+	for i := len(l.slides) - 1; i >= 0 && (n <= 0 || len(out) < n); i-- {
+		if s := l.slides[i]; s.Session == session {
+			out = append(out, s.copy())
+		}
+	}
+	slices.Reverse(out)
+	return out
+}
+
+// Find devuelve la diapositiva con ese ID o nil.
+func (l *SlideLog) Find(id string) *Slide {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	for i := len(l.slides) - 1; i >= 0; i-- {
+		if l.slides[i].ID == id {
+			return l.slides[i]
+		}
+	}
+	return nil
+}
+
+// Remove quita la diapositiva con ese ID; devuelve false si no estaba.
+func (l *SlideLog) Remove(id string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i, s := range l.slides {
+		if s.ID == id {
+			l.slides = append(l.slides[:i], l.slides[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (l *SlideLog) Len() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return len(l.slides)
+}
+
+// Search devuelve las diapositivas cuyo título, resumen o texto OCR contiene
+// term (sin distinguir mayúsculas), la más reciente primero.
+func (l *SlideLog) Search(term string) []Slide {
+	term = strings.ToLower(strings.TrimSpace(term))
+	if term == "" {
+		return nil
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	var out []Slide
+	for i := len(l.slides) - 1; i >= 0; i-- {
+		s := l.slides[i]
+		haystack := strings.ToLower(strings.Join([]string{
+			s.Summary.Title,
+			strings.Join(s.Summary.Bullets, "\n"),
+			strings.Join(s.Summary.Keywords, " "),
+			s.Summary.RawText,
+		}, "\n"))
+		if strings.Contains(haystack, term) {
+			out = append(out, s.copy())
+		}
+	}
+	return out
+}
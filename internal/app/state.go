@@ -1,30 +1,63 @@
 package app
 
 import (
+	"fmt"
+	"slices"
+	"strings"
 	"sync"
 	"time"
+
+	"IA1_EV2025_Proyecto2/internal/schedule"
 )
 
 type ControlState string
 
+// Ciclo de vida de la captura (ver Supervisor).
 const (
-	StateStopped ControlState = "stopped"
-	StateRunning ControlState = "running"
-	StatePaused  ControlState = "paused"
+	StateIdle     ControlState = "idle"     // sin sesión
+	StateStarting ControlState = "starting" // abriendo cámara, OCR, ...
+	StateRunning  ControlState = "running"
+	StatePaused   ControlState = "paused"
+	StateStopping ControlState = "stopping" // cerrando la sesión
+	StateError    ControlState = "error"    // la sesión terminó con error (ver LastError)
 )
 
+// transitions son los cambios de estado permitidos.
+var transitions = map[ControlState][]ControlState{
+	StateIdle:     {StateStarting},
+	StateStarting: {StateRunning, StateStopping, StateError},
+	StateRunning:  {StatePaused, StateStopping, StateError},
+	StatePaused:   {StateRunning, StateStopping, StateError},
+	StateStopping: {StateIdle, StateError},
+	StateError:    {StateStarting, StateIdle},
+}
+
+// TransitionError es un cambio de estado no permitido.
+type TransitionError struct {
+	From, To ControlState
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("no se puede pasar de %s a %s", e.From, e.To)
+}
+
 type State struct {
 	mu sync.RWMutex
 
 	Status         ControlState
+	SessionID      string // una sesión es una ejecución de captura
+	SessionTitle   string
+	SessionSource  string  // quién la inició: "manual" o "horario"
+	SessionChats   []int64 // solo estos destinos; vacío = todos
 	StartedAt      time.Time
 	LastSlideAt    time.Time
 	SlidesCaptured int
 	LastError      string
+	Upcoming       []schedule.Slot // próximas clases del horario (ver Scheduler)
 }
 
 func NewState() *State {
-	return &State{Status: StateStopped}
+	return &State{Status: StateIdle}
 }
 
 func (s *State) Snapshot() State {
@@ -33,21 +66,60 @@ func (s *State) Snapshot() State {
 
 	return State{
 		Status:         s.Status,
+		SessionID:      s.SessionID,
+		SessionTitle:   s.SessionTitle,
+		SessionSource:  s.SessionSource,
+		SessionChats:   s.SessionChats,
 		StartedAt:      s.StartedAt,
 		LastSlideAt:    s.LastSlideAt,
 		SlidesCaptured: s.SlidesCaptured,
 		LastError:      s.LastError,
+		Upcoming:       s.Upcoming,
 	}
 }
 
-func (s *State) SetStatus(st ControlState) {
+// Transition pasa al estado to si está permitido desde el actual y devuelve
+// el anterior.
+func (s *State) Transition(to ControlState) (ControlState, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	s.Status = st
-	if st == StateRunning && s.StartedAt.IsZero() {
-		s.StartedAt = time.Now()
+	from := s.Status
+	if !slices.Contains(transitions[from], to) {
+		return from, &TransitionError{From: from, To: to}
 	}
+	s.Status = to
+	return from, nil
+}
+
+// StartSession abre una sesión nueva con o y reinicia los contadores y el
+// último error.
+func (s *State) StartSession(o SessionOptions) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	id := now.Format("20060102_150405")
+	if strings.HasPrefix(s.SessionID, id) {
+		// detenida y vuelta a iniciar en el mismo segundo
+		id = now.Format("20060102_150405.000")
+	}
+	s.SessionID = id
+	s.SessionTitle = o.Title
+	s.SessionSource = o.Source
+	s.SessionChats = o.ChatIDs
+	s.StartedAt = now
+	s.SlidesCaptured = 0
+	s.LastSlideAt = time.Time{}
+	s.LastError = ""
+	return s.SessionID
+}
+
+// SetUpcoming reemplaza las próximas clases.
+func (s *State) SetUpcoming(slots []schedule.Slot) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Upcoming = slots
 }
 
 func (s *State) SetError(err string) {
@@ -0,0 +1,158 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"IA1_EV2025_Proyecto2/internal/logs"
+)
+
+// ErrShuttingDown indica que el proceso se está cerrando y no acepta órdenes.
+var ErrShuttingDown = errors.New("smartslide se está cerrando")
+
+// Supervisor es dueño del ciclo de vida de la captura: corre cada sesión
+// (Runner.Run) en su propia goroutine, valida los cambios de estado y permite
+// detener y volver a iniciar sin reiniciar el proceso. Las órdenes no esperan
+// a la sesión: devuelven enseguida el cambio hecho.
+type Supervisor struct {
+	r   *Runner
+	ctx context.Context // del proceso
+
+	mu     sync.Mutex         // serializa las órdenes
+	cancel context.CancelFunc // de la sesión en curso
+	done   chan struct{}      // se cierra al terminar la sesión en curso
+	log    *slog.Logger
+}
+
+// Transition es el resultado de una orden.
+type Transition struct {
+	From    ControlState `json:"from"`
+	To      ControlState `json:"to"`
+	Session string       `json:"session,omitempty"`
+}
+
+// SessionOptions ajustan una sesión; los campos vacíos usan la configuración.
+type SessionOptions struct {
+	Title   string  // vacío = course_name
+	ChatIDs []int64 // solo estos destinos; vacío = todos
+	Profile string  // perfil que se aplica antes de abrir la sesión
+	Source  string  // quién la inicia: "manual" (defecto) o "horario"
+}
+
+// NewSupervisor crea el supervisor de r, que además atiende los comandos
+// /start, /pause y /stop del bot. Las sesiones terminan al cancelarse ctx.
+func NewSupervisor(ctx context.Context, r *Runner) *Supervisor {
+	s := &Supervisor{r: r, ctx: ctx, log: logs.For("supervisor")}
+	r.sup = s
+	return s
+}
+
+// Start abre una sesión nueva (desde idle o error) o reanuda la pausada.
+func (s *Supervisor) Start() (Transition, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.r.State.Snapshot().Status == StatePaused {
+		return s.r.transition(StateRunning)
+	}
+	return s.open(SessionOptions{})
+}
+
+// StartSession abre una sesión nueva con o, desde idle o error.
+func (s *Supervisor) StartSession(o SessionOptions) (Transition, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.open(o)
+}
+
+// open abre la sesión; la anterior ya terminó (está en idle o error).
+func (s *Supervisor) open(o SessionOptions) (Transition, error) {
+	if s.ctx.Err() != nil {
+		return Transition{}, ErrShuttingDown
+	}
+	if st := s.r.State.Snapshot().Status; st != StateIdle && st != StateError {
+		return Transition{}, &TransitionError{From: st, To: StateStarting}
+	}
+	if o.Profile != "" && o.Profile != s.r.GetConfig().Profile {
+		if err := s.r.ApplyProfile(o.Profile, o.Source); err != nil {
+			return Transition{}, fmt.Errorf("perfil %s: %w", o.Profile, err)
+		}
+	}
+	if o.Title == "" {
+		o.Title = s.r.GetConfig().CourseName
+	}
+	if o.Source == "" {
+		o.Source = "manual"
+	}
+
+	from, err := s.r.State.Transition(StateStarting)
+	if err != nil {
+		return Transition{}, err
+	}
+	s.r.State.StartSession(o)
+	t := s.r.stateChanged(from, StateStarting)
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	done := make(chan struct{})
+	s.cancel, s.done = cancel, done
+	go func() {
+		defer close(done)
+		defer cancel()
+		s.finish(s.r.Run(ctx))
+	}()
+	s.log.Info("session starting", "session", t.Session, "title", o.Title, "source", o.Source)
+	return t, nil
+}
+
+// Pause deja de procesar frames sin cerrar la sesión.
+func (s *Supervisor) Pause() (Transition, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.r.transition(StatePaused)
+}
+
+// Stop cierra la sesión en curso; termina en segundo plano y el estado pasa a
+// idle. Desde error solo vuelve a idle.
+func (s *Supervisor) Stop() (Transition, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.r.State.Snapshot().Status == StateError {
+		return s.r.transition(StateIdle)
+	}
+	t, err := s.r.transition(StateStopping)
+	if err != nil {
+		return t, err
+	}
+	s.cancel()
+	return t, nil
+}
+
+// Shutdown detiene la sesión en curso, si hay, y espera a que termine.
+func (s *Supervisor) Shutdown() {
+	s.mu.Lock()
+	done := s.done
+	if done != nil {
+		s.cancel()
+	}
+	s.mu.Unlock()
+	if done != nil {
+		<-done
+	}
+}
+
+// finish deja el estado según cómo terminó la sesión: error si Run falló e
+// idle si la detuvieron (Stop o el cierre del proceso).
+func (s *Supervisor) finish(err error) {
+	if err != nil {
+		s.log.Error("session failed", "session", s.r.State.Snapshot().SessionID, "err", err)
+		s.r.State.SetError(err.Error())
+		_, _ = s.r.transition(StateError)
+		return
+	}
+	if s.r.State.Snapshot().Status != StateStopping {
+		_, _ = s.r.transition(StateStopping)
+	}
+	_, _ = s.r.transition(StateIdle)
+}
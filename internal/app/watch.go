@@ -0,0 +1,79 @@
+package app
+
+import (
+	"context"
+	"crypto/sha256"
+	"os"
+	"time"
+
+	"IA1_EV2025_Proyecto2/internal/config"
+)
+
+// configPollInterval es cada cuánto WatchConfig revisa el archivo.
+const configPollInterval = 2 * time.Second
+
+// Reload vuelve a leer CfgPath (y los archivos de secretos de Overrides) y
+// aplica la configuración al loop en curso. Si el resultado no es válido se
+// conserva la configuración actual.
+func (r *Runner) Reload() error {
+	// el hash es el de los bytes que se aplicaron: si el archivo cambia
+	// mientras tanto, WatchConfig lo ve en la próxima revisión
+	l, err := config.LoadLayers(r.CfgPath, r.Overrides)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	old, oldFile := r.cfg, r.file
+	r.cfg, r.file, r.sources, r.cfgHash = l.Config, l.File, l.Sources, l.Hash
+	r.mu.Unlock()
+
+	r.record(oldFile, l.File, "archivo")
+	r.applyConfig(old, l.Config)
+	r.log.Info("config reloaded", "path", r.CfgPath)
+	return nil
+}
+
+// WatchConfig recarga la configuración cuando cambia el archivo, hasta que
+// termina ctx. Revisa el contenido cada configPollInterval (sin dependencias
+// de inotify); lo que guarda UpdateConfig no cuenta como cambio.
+func (r *Runner) WatchConfig(ctx context.Context) {
+	r.mu.Lock()
+	if sum, err := hashFile(r.CfgPath); err == nil && r.cfgHash == ([32]byte{}) {
+		r.cfgHash = sum
+	}
+	r.mu.Unlock()
+
+	ticker := time.NewTicker(configPollInterval)
+	defer ticker.Stop()
+	var failed [32]byte // último contenido inválido, para no repetir el error
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		sum, err := hashFile(r.CfgPath)
+		if err != nil {
+			continue // p. ej. un editor que reemplaza el archivo
+		}
+		r.mu.RLock()
+		same := sum == r.cfgHash
+		r.mu.RUnlock()
+		if same || sum == failed {
+			continue
+		}
+		if err := r.Reload(); err != nil {
+			failed = sum
+			r.fail("reload config", err, "path", r.CfgPath)
+		}
+	}
+}
+
+func hashFile(path string) ([32]byte, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return sha256.Sum256(b), nil
+}
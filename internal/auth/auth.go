@@ -0,0 +1,226 @@
+// Package auth valida las credenciales del panel de administración: tokens
+// fijos, usuarios con contraseña y las sesiones que abre el login. En la
+// configuración solo se guardan hashes (ver HashPassword y HashToken).
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"IA1_EV2025_Proyecto2/internal/config"
+)
+
+// Role es el nivel de acceso de una credencial.
+type Role string
+
+const (
+	RoleRead  Role = "read"  // consultar estado, métricas, logs, galería y vista previa
+	RoleAdmin Role = "admin" // además cambiar la configuración, controlar la captura y borrar
+)
+
+// Allows indica si el rol alcanza para una operación que pide need.
+func (r Role) Allows(need Role) bool {
+	return r == RoleAdmin || r == need
+}
+
+// Identity es quien hizo la petición.
+type Identity struct {
+	Name   string `json:"name"`
+	Role   Role   `json:"role"`
+	Method string `json:"method"` // "token", "session", "password" o "none"
+}
+
+// ErrInvalidCredentials indica un usuario o contraseña incorrectos.
+var ErrInvalidCredentials = errors.New("usuario o contraseña incorrectos")
+
+const (
+	defaultSessionTTL = 12 * time.Hour
+	// las contraseñas de Basic auth válidas se recuerdan un rato para no
+	// recalcular PBKDF2 en cada petición (el panel consulta /status seguido)
+	basicCacheTTL = 10 * time.Minute
+	basicCacheMax = 64
+)
+
+type session struct {
+	user    string
+	expires time.Time
+}
+
+// Authenticator valida credenciales contra la configuración vigente, así que
+// los cambios en admin_auth valen sin reiniciar: un usuario quitado pierde
+// también sus sesiones y un cambio de rol se aplica a las abiertas.
+type Authenticator struct {
+	get func() config.AdminAuth
+
+	mu       sync.Mutex
+	sessions map[string]session     // por HashToken del token de sesión
+	basic    map[[32]byte]time.Time // usuario+contraseña+hash ya verificados
+
+	dummyOnce sync.Once
+	dummy     string // hash para no delatar usuarios inexistentes por el tiempo
+}
+
+func New(get func() config.AdminAuth) *Authenticator {
+	return &Authenticator{
+		get:      get,
+		sessions: map[string]session{},
+		basic:    map[[32]byte]time.Time{},
+	}
+}
+
+// Enabled indica si hay credenciales configuradas; sin ninguna el panel queda
+// abierto como en versiones anteriores.
+func (a *Authenticator) Enabled() bool {
+	c := a.get()
+	return len(c.Users) > 0 || len(c.Tokens) > 0
+}
+
+// Login valida usuario y contraseña y abre una sesión. Devuelve el token de la
+// sesión, que se usa después como Bearer.
+func (a *Authenticator) Login(user, password string) (string, Identity, time.Time, error) {
+	id, ok := a.password(user, password)
+	if !ok {
+		return "", Identity{}, time.Time{}, ErrInvalidCredentials
+	}
+	token, err := NewToken()
+	if err != nil {
+		return "", Identity{}, time.Time{}, err
+	}
+	ttl := defaultSessionTTL
+	if h := a.get().SessionHours; h > 0 {
+		ttl = time.Duration(h) * time.Hour
+	}
+	expires := time.Now().Add(ttl)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.pruneLocked(time.Now())
+	a.sessions[HashToken(token)] = session{user: id.Name, expires: expires}
+	id.Method = "session"
+	return token, id, expires, nil
+}
+
+// Logout cierra la sesión del token; los tokens fijos no se pueden cerrar.
+func (a *Authenticator) Logout(token string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.sessions, HashToken(token))
+}
+
+// Token valida un token fijo de admin_auth.tokens o uno de sesión.
+func (a *Authenticator) Token(token string) (Identity, bool) {
+	if token == "" {
+		return Identity{}, false
+	}
+	c := a.get()
+	for _, t := range c.Tokens {
+		if CheckToken(t.Hash, token) {
+			return Identity{Name: t.Name, Role: Role(t.Role), Method: "token"}, true
+		}
+	}
+
+	a.mu.Lock()
+	s, ok := a.sessions[HashToken(token)]
+	if ok && time.Now().After(s.expires) {
+		delete(a.sessions, HashToken(token))
+		ok = false
+	}
+	a.mu.Unlock()
+	if !ok {
+		return Identity{}, false
+	}
+	if u, found := findUser(c, s.user); found {
+		return Identity{Name: u.Name, Role: Role(u.Role), Method: "session"}, true
+	}
+	return Identity{}, false
+}
+
+// Request autentica la petición con, en orden: "Authorization: Bearer",
+// "Authorization: Basic" o ?access_token= (para EventSource e <img>, que no
+// pueden mandar cabeceras).
+func (a *Authenticator) Request(r *http.Request) (Identity, bool) {
+	h := r.Header.Get("Authorization")
+	switch {
+	case len(h) > 7 && strings.EqualFold(h[:7], "bearer "):
+		return a.Token(strings.TrimSpace(h[7:]))
+	case h != "":
+		user, pass, ok := r.BasicAuth()
+		if !ok {
+			return Identity{}, false
+		}
+		return a.password(user, pass)
+	}
+	return a.Token(r.URL.Query().Get("access_token"))
+}
+
+func (a *Authenticator) password(user, password string) (Identity, bool) {
+	u, found := findUser(a.get(), user)
+	if !found {
+		a.dummyOnce.Do(func() { a.dummy, _ = HashPassword("") })
+		_, _ = CheckPassword(a.dummy, password)
+		return Identity{}, false
+	}
+	id := Identity{Name: u.Name, Role: Role(u.Role), Method: "password"}
+
+	key := sha256.Sum256([]byte(u.Name + "\x00" + password + "\x00" + u.PasswordHash))
+	now := time.Now()
+	a.mu.Lock()
+	exp, cached := a.basic[key]
+	a.mu.Unlock()
+	if cached && now.Before(exp) {
+		return id, true
+	}
+
+	ok, err := CheckPassword(u.PasswordHash, password)
+	if err != nil || !ok {
+		return Identity{}, false
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.pruneLocked(now)
+	if len(a.basic) < basicCacheMax {
+		a.basic[key] = now.Add(basicCacheTTL)
+	}
+	return id, true
+}
+
+// pruneLocked quita sesiones y entradas de caché vencidas.
+func (a *Authenticator) pruneLocked(now time.Time) {
+	for k, s := range a.sessions {
+		if now.After(s.expires) {
+			delete(a.sessions, k)
+		}
+	}
+	for k, exp := range a.basic {
+		if now.After(exp) {
+			delete(a.basic, k)
+		}
+	}
+}
+
+func findUser(c config.AdminAuth, name string) (config.AdminUser, bool) {
+	for _, u := range c.Users {
+		if u.Name == name {
+			return u, true
+		}
+	}
+	return config.AdminUser{}, false
+}
+
+type ctxKey struct{}
+
+// WithIdentity guarda id en el contexto de la petición.
+func WithIdentity(ctx context.Context, id Identity) context.Context {
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// FromContext devuelve la identidad guardada por WithIdentity.
+func FromContext(ctx context.Context) (Identity, bool) {
+	id, ok := ctx.Value(ctxKey{}).(Identity)
+	return id, ok
+}
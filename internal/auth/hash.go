@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Formatos de los hashes guardados en la configuración:
+//
+//	pbkdf2-sha256$<iteraciones>$<sal base64>$<hash base64>  contraseñas
+//	sha256$<hex>                                         tokens
+const (
+	passwordScheme = "pbkdf2-sha256"
+	tokenScheme    = "sha256"
+
+	// DefaultIterations es el costo de PBKDF2 para contraseñas nuevas; alcanza
+	// en una Raspberry Pi porque el login valida una sola vez por sesión.
+	DefaultIterations = 310000
+
+	saltLen = 16
+	keyLen  = 32
+)
+
+// ErrBadHash indica un hash de la configuración con formato inválido.
+var ErrBadHash = errors.New("hash de credencial inválido")
+
+// HashPassword devuelve el hash PBKDF2-HMAC-SHA256 de password con sal aleatoria.
+func HashPassword(password string) (string, error) {
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := pbkdf2([]byte(password), salt, DefaultIterations, keyLen)
+	enc := base64.RawStdEncoding
+	return fmt.Sprintf("%s$%d$%s$%s", passwordScheme, DefaultIterations, enc.EncodeToString(salt), enc.EncodeToString(key)), nil
+}
+
+// CheckPassword compara password con un hash de HashPassword en tiempo constante.
+func CheckPassword(hash, password string) (bool, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 4 || parts[0] != passwordScheme {
+		return false, ErrBadHash
+	}
+	iter, err := strconv.Atoi(parts[1])
+	if err != nil || iter <= 0 {
+		return false, ErrBadHash
+	}
+	enc := base64.RawStdEncoding
+	salt, err := enc.DecodeString(parts[2])
+	if err != nil {
+		return false, ErrBadHash
+	}
+	want, err := enc.DecodeString(parts[3])
+	if err != nil || len(want) == 0 {
+		return false, ErrBadHash
+	}
+	got := pbkdf2([]byte(password), salt, iter, len(want))
+	return subtle.ConstantTimeCompare(got, want) == 1, nil
+}
+
+// NewToken genera un token aleatorio de 32 bytes en base64 para URL.
+func NewToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken devuelve el hash con el que se guarda un token. Los tokens son
+// aleatorios, así que basta con SHA-256 sin sal ni iteraciones.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return tokenScheme + "$" + hex.EncodeToString(sum[:])
+}
+
+// CheckToken compara token con un hash de HashToken en tiempo constante.
+func CheckToken(hash, token string) bool {
+	return subtle.ConstantTimeCompare([]byte(HashToken(token)), []byte(strings.ToLower(hash))) == 1
+}
+
+// pbkdf2 implementa PBKDF2 (RFC 8018) con HMAC-SHA256.
+func pbkdf2(password, salt []byte, iter, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	hLen := prf.Size()
+	blocks := (keyLen + hLen - 1) / hLen
+
+	var idx [4]byte
+	dk := make([]byte, 0, blocks*hLen)
+	u := make([]byte, hLen)
+	for block := 1; block <= blocks; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		binary.BigEndian.PutUint32(idx[:], uint32(block))
+		prf.Write(idx[:])
+		dk = prf.Sum(dk)
+		t := dk[len(dk)-hLen:]
+		copy(u, t)
+
+		for i := 1; i < iter; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for x := range u {
+				t[x] ^= u[x]
+			}
+		}
+	}
+	return dk[:keyLen]
+}
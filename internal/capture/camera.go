@@ -1,8 +1,36 @@
 package capture
 
-import "gocv.io/x/gocv"
+import (
+	"log/slog"
 
-func OpenCamera(index int) (*gocv.VideoCapture, error) {
+	"gocv.io/x/gocv"
+
+	"IA1_EV2025_Proyecto2/internal/logs"
+	"IA1_EV2025_Proyecto2/internal/metrics"
+)
+
+// Lecturas fallidas seguidas antes de reabrir la cámara (p. ej. USB desconectada).
+const reconnectAfter = 30
+
+// Camera envuelve la VideoCapture y la reabre si deja de entregar frames.
+type Camera struct {
+	index    int
+	cam      *gocv.VideoCapture
+	failures int
+	log      *slog.Logger
+}
+
+func OpenCamera(index int) (*Camera, error) {
+	cam, err := openVideo(index)
+	if err != nil {
+		return nil, err
+	}
+	log := logs.For("capture").With("camera", index)
+	log.Info("camera opened")
+	return &Camera{index: index, cam: cam, log: log}, nil
+}
+
+func openVideo(index int) (*gocv.VideoCapture, error) {
 	cam, err := gocv.OpenVideoCapture(index)
 	if err != nil {
 		return nil, err
@@ -12,3 +40,35 @@ func OpenCamera(index int) (*gocv.VideoCapture, error) {
 	cam.Set(gocv.VideoCaptureFrameHeight, 720)
 	return cam, nil
 }
+
+// Read lee un frame en m. Devuelve false si no hubo frame.
+func (c *Camera) Read(m *gocv.Mat) bool {
+	if c.cam.Read(m) && !m.Empty() {
+		c.failures = 0
+		metrics.FramesRead.Inc()
+		return true
+	}
+	metrics.FramesDropped.Inc()
+	c.failures++
+	if c.failures >= reconnectAfter {
+		c.reconnect()
+	}
+	return false
+}
+
+func (c *Camera) reconnect() {
+	c.failures = 0
+	metrics.CameraReconnects.Inc()
+	cam, err := openVideo(c.index)
+	if err != nil {
+		c.log.Warn("reconnect camera", "err", err)
+		return
+	}
+	c.cam.Close()
+	c.cam = cam
+	c.log.Info("camera reopened")
+}
+
+func (c *Camera) Close() error {
+	return c.cam.Close()
+}
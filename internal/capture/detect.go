@@ -5,30 +5,90 @@ import (
 	"time"
 
 	"gocv.io/x/gocv"
+
+	"IA1_EV2025_Proyecto2/internal/metrics"
 )
 
 type Detector struct {
 	sensitivity float64
 	minGap      time.Duration
 	lastTrigger time.Time
+
+	// ROI limita la comparación a una zona del frame (vacía = todo)
+	ROI ROI
+
+	diff     gocv.Mat // máscara de pixeles cambiados de la última comparación
+	diffRect image.Rectangle
+	hasDiff  bool
 }
 
 func NewDetector(sensitivity float64, minGap time.Duration) *Detector {
 	return &Detector{
 		sensitivity: sensitivity,
 		minGap:      minGap,
+		diff:        gocv.NewMat(),
+	}
+}
+
+func (d *Detector) Close() error {
+	return d.diff.Close()
+}
+
+// ROI es una región del frame en fracciones de su ancho y alto (0..1).
+type ROI struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	W float64 `json:"w"`
+	H float64 `json:"h"`
+}
+
+// Rect devuelve la región en pixeles para un frame de cols x rows, recortada
+// al frame; una ROI vacía o inválida es el frame completo.
+func (r ROI) Rect(cols, rows int) image.Rectangle {
+	full := image.Rect(0, 0, cols, rows)
+	if r.W <= 0 || r.H <= 0 {
+		return full
+	}
+	rect := image.Rect(
+		int(r.X*float64(cols)), int(r.Y*float64(rows)),
+		int((r.X+r.W)*float64(cols)), int((r.Y+r.H)*float64(rows)),
+	).Intersect(full)
+	if rect.Dx() < 8 || rect.Dy() < 8 {
+		return full
 	}
+	return rect
+}
+
+// Cooldown indica si sigue la pausa mínima tras la última detección; mientras
+// tanto IsNewSlide no compara y devuelve score 0.
+func (d *Detector) Cooldown() bool {
+	return time.Since(d.lastTrigger) < d.minGap
+}
+
+// Diff devuelve la máscara de la última comparación (válida hasta la próxima
+// llamada a IsNewSlide) y su posición en el frame; false si no hubo comparación.
+func (d *Detector) Diff() (gocv.Mat, image.Rectangle, bool) {
+	return d.diff, d.diffRect, d.hasDiff
 }
 
 // score ~ proporción de pixeles que cambiaron (0..1 aprox)
 func (d *Detector) IsNewSlide(prev, cur gocv.Mat) (bool, float64) {
+	d.hasDiff = false
 	if prev.Empty() || cur.Empty() {
 		return false, 0
 	}
-	if time.Since(d.lastTrigger) < d.minGap {
+	if d.Cooldown() {
 		return false, 0
 	}
 
+	rect := d.ROI.Rect(cur.Cols(), cur.Rows())
+	if rect != image.Rect(0, 0, cur.Cols(), cur.Rows()) {
+		p, c := prev.Region(rect), cur.Region(rect)
+		defer p.Close()
+		defer c.Close()
+		prev, cur = p, c
+	}
+
 	pg := gocv.NewMat()
 	cg := gocv.NewMat()
 	defer pg.Close()
@@ -40,21 +100,21 @@ func (d *Detector) IsNewSlide(prev, cur gocv.Mat) (bool, float64) {
 	gocv.GaussianBlur(pg, &pg, image.Pt(5, 5), 0, 0, gocv.BorderDefault)
 	gocv.GaussianBlur(cg, &cg, image.Pt(5, 5), 0, 0, gocv.BorderDefault)
 
-	diff := gocv.NewMat()
-	defer diff.Close()
-
-	gocv.AbsDiff(pg, cg, &diff)
-	gocv.Threshold(diff, &diff, 25, 255, gocv.ThresholdBinary)
+	gocv.AbsDiff(pg, cg, &d.diff)
+	gocv.Threshold(d.diff, &d.diff, 25, 255, gocv.ThresholdBinary)
+	d.diffRect, d.hasDiff = rect, true
 
-	changed := gocv.CountNonZero(diff)
-	total := diff.Rows() * diff.Cols()
+	changed := gocv.CountNonZero(d.diff)
+	total := d.diff.Rows() * d.diff.Cols()
 	if total <= 0 {
 		return false, 0
 	}
 
 	score := float64(changed) / float64(total)
+	metrics.ChangeScore.Set(score)
 	if score >= d.sensitivity {
 		d.lastTrigger = time.Now()
+		metrics.Detections.Inc()
 		return true, score
 	}
 	return false, score
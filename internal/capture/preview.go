@@ -0,0 +1,221 @@
+package capture
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"sync"
+	"time"
+
+	"gocv.io/x/gocv"
+)
+
+// ErrNoFrame indica que el loop de captura todavía no leyó ningún frame.
+var ErrNoFrame = errors.New("todavía no hay frames de la cámara")
+
+// Preview guarda el último frame del loop de captura para la vista previa del
+// panel. El loop solo copia el frame; escalar, dibujar y codificar lo hace
+// quien lo pide, en su goroutine, así que la cámara se abre una sola vez y la
+// captura no se demora.
+type Preview struct {
+	mu       sync.Mutex
+	frame    gocv.Mat
+	diff     gocv.Mat
+	diffRect image.Rectangle
+	hasDiff  bool
+	roi      ROI
+	seq      uint64
+	at       time.Time
+	notify   chan struct{} // se cierra con cada frame nuevo
+	viewers  int
+
+	cache struct {
+		key  string
+		jpeg []byte
+	}
+}
+
+// PreviewOptions indica cómo renderizar el frame.
+type PreviewOptions struct {
+	Width   int // 0 = tamaño original; nunca se agranda
+	Quality int // JPEG, 1..100 (0 = 80)
+	ROI     bool
+	Screen  bool // contorno de la pantalla detectada (FindScreen)
+	Diff    bool // pixeles que cambiaron respecto de la última diapositiva
+}
+
+// Frame es un frame ya codificado.
+type Frame struct {
+	JPEG []byte
+	Seq  uint64
+	At   time.Time
+}
+
+func NewPreview() *Preview {
+	return &Preview{
+		frame:  gocv.NewMat(),
+		diff:   gocv.NewMat(),
+		notify: make(chan struct{}),
+	}
+}
+
+// SetROI indica la ROI que se dibuja con PreviewOptions.ROI.
+func (p *Preview) SetROI(roi ROI) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.roi = roi
+}
+
+// Update copia frame y, si det acaba de comparar, su máscara de diferencias.
+// det nil = sin máscara (p. ej. en pausa).
+func (p *Preview) Update(frame gocv.Mat, det *Detector) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	frame.CopyTo(&p.frame)
+	p.hasDiff = false
+	if det != nil {
+		if m, rect, ok := det.Diff(); ok {
+			m.CopyTo(&p.diff)
+			p.diffRect, p.hasDiff = rect, true
+		}
+	}
+	p.seq++
+	p.at = time.Now()
+	close(p.notify)
+	p.notify = make(chan struct{})
+}
+
+// Watch registra un cliente de la vista en vivo hasta que se llama a la
+// función devuelta. Con clientes, el Runner sigue leyendo frames en pausa.
+func (p *Preview) Watch() func() {
+	p.mu.Lock()
+	p.viewers++
+	p.mu.Unlock()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			p.mu.Lock()
+			p.viewers--
+			p.mu.Unlock()
+		})
+	}
+}
+
+// Watched indica si hay clientes de la vista en vivo.
+func (p *Preview) Watched() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.viewers > 0
+}
+
+// Wait espera un frame posterior a seq; false si ctx terminó antes.
+func (p *Preview) Wait(ctx context.Context, seq uint64) bool {
+	p.mu.Lock()
+	if p.seq > seq {
+		p.mu.Unlock()
+		return true
+	}
+	ch := p.notify
+	p.mu.Unlock()
+
+	select {
+	case <-ch:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Render codifica el último frame con opts. Varios clientes con las mismas
+// opciones comparten la codificación.
+func (p *Preview) Render(opts PreviewOptions) (Frame, error) {
+	if opts.Quality <= 0 || opts.Quality > 100 {
+		opts.Quality = 80
+	}
+
+	p.mu.Lock()
+	if p.seq == 0 || p.frame.Empty() {
+		p.mu.Unlock()
+		return Frame{}, ErrNoFrame
+	}
+	f := Frame{Seq: p.seq, At: p.at}
+	key := fmt.Sprintf("%d/%+v", p.seq, opts)
+	if p.cache.key == key {
+		f.JPEG = p.cache.jpeg
+		p.mu.Unlock()
+		return f, nil
+	}
+	img := p.frame.Clone()
+	withDiff := opts.Diff && p.hasDiff
+	var diff gocv.Mat
+	diffRect := p.diffRect
+	if withDiff {
+		diff = p.diff.Clone()
+	}
+	roi := p.roi
+	p.mu.Unlock()
+
+	defer img.Close()
+	if withDiff {
+		defer diff.Close()
+	}
+
+	// FindScreen antes de dibujar para que las marcas no la confundan
+	var screen []image.Point
+	if opts.Screen {
+		screen = FindScreen(img)
+	}
+	thick := max(2, img.Cols()/320)
+	if withDiff {
+		drawMask(&img, diff, diffRect)
+	}
+	if rect := roi.Rect(img.Cols(), img.Rows()); opts.ROI && rect != image.Rect(0, 0, img.Cols(), img.Rows()) {
+		gocv.Rectangle(&img, rect, color.RGBA{R: 255, G: 200, B: 0, A: 255}, thick)
+	}
+	if len(screen) > 0 {
+		pts := gocv.NewPointsVector()
+		pv := gocv.NewPointVectorFromPoints(screen)
+		pts.Append(pv)
+		gocv.Polylines(&img, pts, true, color.RGBA{R: 0, G: 255, B: 0, A: 255}, thick)
+		pv.Close()
+		pts.Close()
+	}
+
+	out := img
+	if opts.Width > 0 && opts.Width < img.Cols() {
+		small := gocv.NewMat()
+		defer small.Close()
+		gocv.Resize(img, &small, image.Pt(opts.Width, img.Rows()*opts.Width/img.Cols()), 0, 0, gocv.InterpolationArea)
+		out = small
+	}
+
+	buf, err := gocv.IMEncodeWithParams(gocv.JPEGFileExt, out, []int{gocv.IMWriteJpegQuality, opts.Quality})
+	if err != nil {
+		return Frame{}, err
+	}
+	f.JPEG = append([]byte(nil), buf.GetBytes()...)
+	buf.Close()
+
+	p.mu.Lock()
+	p.cache.key, p.cache.jpeg = key, f.JPEG
+	p.mu.Unlock()
+	return f, nil
+}
+
+// drawMask tiñe de rojo los pixeles de la máscara, ubicada en rect.
+func drawMask(img *gocv.Mat, mask gocv.Mat, rect image.Rectangle) {
+	if mask.Cols() != rect.Dx() || mask.Rows() != rect.Dy() {
+		return
+	}
+	region := img.Region(rect)
+	defer region.Close()
+	red := gocv.NewMatWithSizeFromScalar(gocv.NewScalar(0, 0, 255, 0), rect.Dy(), rect.Dx(), img.Type())
+	defer red.Close()
+	tint := gocv.NewMat()
+	defer tint.Close()
+	gocv.AddWeighted(region, 0.4, red, 0.6, 0, &tint)
+	tint.CopyToWithMask(&region, mask)
+}
@@ -0,0 +1,47 @@
+package capture
+
+import (
+	"image"
+
+	"gocv.io/x/gocv"
+)
+
+// minScreenArea es la fracción mínima del frame que debe ocupar la pantalla.
+const minScreenArea = 0.1
+
+// FindScreen busca la pantalla o el proyector como el mayor contorno de cuatro
+// lados. Devuelve sus esquinas o nil si no encuentra ninguno. Se usa para
+// ayudar a apuntar la cámara.
+func FindScreen(frame gocv.Mat) []image.Point {
+	if frame.Empty() {
+		return nil
+	}
+	gray := gocv.NewMat()
+	defer gray.Close()
+	gocv.CvtColor(frame, &gray, gocv.ColorBGRToGray)
+	gocv.GaussianBlur(gray, &gray, image.Pt(5, 5), 0, 0, gocv.BorderDefault)
+
+	edges := gocv.NewMat()
+	defer edges.Close()
+	gocv.Canny(gray, &edges, 50, 150)
+
+	contours := gocv.FindContours(edges, gocv.RetrievalExternal, gocv.ChainApproxSimple)
+	defer contours.Close()
+
+	minArea := minScreenArea * float64(frame.Cols()*frame.Rows())
+	var best []image.Point
+	bestArea := minArea
+	for i := 0; i < contours.Size(); i++ {
+		c := contours.At(i)
+		area := gocv.ContourArea(c)
+		if area < bestArea {
+			continue
+		}
+		approx := gocv.ApproxPolyDP(c, 0.02*gocv.ArcLength(c, true), true)
+		if approx.Size() == 4 {
+			best, bestArea = approx.ToPoints(), area
+		}
+		approx.Close()
+	}
+	return best
+}
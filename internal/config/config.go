@@ -2,8 +2,8 @@ package config
 
 import (
 	"encoding/json"
-	"errors"
 	"os"
+	"path/filepath"
 )
 
 type Config struct {
@@ -11,30 +11,201 @@ type Config struct {
 	CaptureFPS              int     `json:"capture_fps"`
 	Sensitivity             float64 `json:"sensitivity"`
 	MinSecondsBetweenSlides int     `json:"min_seconds_between_slides"`
+	// Zona del frame que se compara, en fracciones (0..1) del ancho y alto;
+	// vacía (w o h en 0) = todo el frame
+	ROI ROI `json:"roi"`
 
 	TelegramBotToken string `json:"telegram_bot_token"`
 	TelegramChatID   int64  `json:"telegram_chat_id"`
+	// Destinos con filtros propios; si está vacío se usa solo TelegramChatID
+	Destinations []Destination `json:"destinations"`
+	// Usuarios que pueden usar los comandos del bot (vacío = comandos desactivados)
+	TelegramAllowedUsers []int64 `json:"telegram_allowed_users"`
+
+	// Servidor de la Bot API; vacío = api.telegram.org. Sirve para un servidor
+	// local de la Bot API o para cmd/fakebot
+	TelegramAPIEndpoint string `json:"telegram_api_endpoint"`
 
 	TesseractLang string `json:"tesseract_lang"`
 
+	// Corrección posterior al OCR
+	EnableOCRCorrection bool     `json:"enable_ocr_correction"`
+	Glossary            []string `json:"glossary"`         // términos del curso
+	DictionaryFiles     []string `json:"dictionary_files"` // listas extra, una palabra por línea
+
+	CourseName string `json:"course_name"`
+
 	OutputDir        string `json:"output_dir"`
 	EnableAnnotation bool   `json:"enable_annotation"`
 	MaxCaptionChars  int    `json:"max_caption_chars"`
 
+	// Base de datos con sesiones, diapositivas y métricas (vacío = smartslide.db
+	// en output_dir)
+	DatabasePath string `json:"database_path"`
+
+	// Caption: plantilla text/template (vacía = la clásica), formato "plain",
+	// "markdownv2" o "html", y qué hacer con lo que no cabe: "truncate" o
+	// "followup" (enviarlo como respuesta)
+	CaptionTemplate string `json:"caption_template"`
+	CaptionFormat   string `json:"caption_format"`
+	CaptionOverflow string `json:"caption_overflow"`
+
+	// Revelaciones incrementales: "edit" actualiza el mensaje anterior,
+	// "diff" envía solo las líneas nuevas, "off" las trata como diapositivas nuevas
+	BuildMode string `json:"build_mode"`
+
+	// Álbumes: agrupar hasta AlbumSize diapositivas (1 = enviar una a una)
+	AlbumSize           int  `json:"album_size"`
+	AlbumMaxWaitSeconds int  `json:"album_max_wait_seconds"`
+	SendTableDocuments  bool `json:"send_table_documents"` // CSV de tablas como respuesta
+
 	AdminHTTPAddr string `json:"admin_http_addr"`
+	// Acceso al panel: credenciales (vacías = sin autenticación), orígenes que
+	// pueden llamarlo desde el navegador ("*" = cualquiera; vacío = solo el
+	// mismo origen) y HTTPS
+	AdminAuth        AdminAuth `json:"admin_auth"`
+	AdminCORSOrigins []string  `json:"admin_cors_origins"`
+	AdminTLS         AdminTLS  `json:"admin_tls"`
+
+	// Logs: nivel mínimo ("debug", "info", "warn", "error") y cuántos registros
+	// guarda el panel en memoria
+	LogLevel      string `json:"log_level"`
+	LogBufferSize int    `json:"log_buffer_size"`
+
+	// Resumen: "rules" (por defecto) o "llm" (endpoint compatible con OpenAI)
+	SummarizerBackend string `json:"summarizer_backend"`
+	LLMEndpoint       string `json:"llm_endpoint"`
+	LLMModel          string `json:"llm_model"`
+	LLMAPIKey         string `json:"llm_api_key"`
+	LLMTimeoutSeconds int    `json:"llm_timeout_seconds"`
+	LLMContextSlides  int    `json:"llm_context_slides"`
+
+	// Perfiles por aula: cada uno es un JSON merge patch (p. ej. cámara, ROI y
+	// sensibilidad) que se aplica sobre la configuración al elegirlo (ver
+	// WithProfile); profile es el último aplicado y profile_base guarda lo
+	// que valían antes los campos que cambió, para volver a ellos al elegir
+	// otro
+	Profiles    map[string]json.RawMessage `json:"profiles"`
+	Profile     string                     `json:"profile"`
+	ProfileBase map[string]json.RawMessage `json:"profile_base,omitempty"`
+
+	// Horario de clases: el planificador inicia y detiene la captura en cada
+	// clase semanal de schedule y en cada evento del calendario schedule_ics
+	// (un archivo .ics, que se vuelve a leer si cambia). Vacíos = a mano
+	Schedule    []ScheduleEntry `json:"schedule"`
+	ScheduleICS string          `json:"schedule_ics"`
+
+	// Versiones guardadas en history/ junto al archivo de configuración
+	ConfigHistorySize int `json:"config_history_size"`
 }
 
-func Load(path string) (Config, error) {
-	b, err := os.ReadFile(path)
-	if err != nil {
-		return Config{}, err
+// ROI es una región del frame; x e y son la esquina superior izquierda.
+type ROI struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	W float64 `json:"w"`
+	H float64 `json:"h"`
+}
+
+// AdminAuth son las credenciales del panel. Solo se guardan hashes: se generan
+// con "smartslide auth password" y "smartslide auth token".
+type AdminAuth struct {
+	Users        []AdminUser  `json:"users"`
+	Tokens       []AdminToken `json:"tokens"`
+	SessionHours int          `json:"session_hours"` // duración del login; 0 = 12
+}
+
+// AdminUser entra con usuario y contraseña (Basic auth o POST /auth/login).
+type AdminUser struct {
+	Name         string `json:"name"`
+	PasswordHash string `json:"password_hash"` // pbkdf2-sha256$...
+	Role         string `json:"role"`          // "read" (defecto) o "admin"
+}
+
+// AdminToken es un token fijo para scripts y Prometheus (Bearer).
+type AdminToken struct {
+	Name string `json:"name"`
+	Hash string `json:"hash"` // sha256$...
+	Role string `json:"role"` // "read" (defecto) o "admin"
+}
+
+// AdminTLS activa HTTPS en el panel. Si faltan los archivos se genera un
+// certificado autofirmado al iniciar.
+type AdminTLS struct {
+	Enabled  bool     `json:"enabled"`
+	CertFile string   `json:"cert_file"` // vacío = admin_cert.pem en output_dir
+	KeyFile  string   `json:"key_file"`  // vacío = admin_key.pem en output_dir
+	Hosts    []string `json:"hosts"`     // nombres o IP extra del certificado generado
+}
+
+// Destination es un chat de Telegram que recibe diapositivas, con sus filtros.
+type Destination struct {
+	Name   string `json:"name"`
+	ChatID int64  `json:"chat_id"`
+
+	// Filtros (vacíos = sin filtrar)
+	Sessions       []string `json:"sessions"` // ID o título de la sesión
+	MinChangeScore float64  `json:"min_change_score"`
+	Keywords       []string `json:"keywords"` // basta con que aparezca una
+
+	CaptionTemplate string `json:"caption_template"` // text/template; vacío = caption_template global
+	CaptionFormat   string `json:"caption_format"`   // vacío = caption_format global
+	Language        string `json:"language"`         // "es" (defecto) o "en"
+}
+
+// Targets devuelve los destinos configurados o, si no hay, el chat de
+// telegram_chat_id sin filtros.
+func (c Config) Targets() []Destination {
+	if len(c.Destinations) > 0 {
+		return c.Destinations
 	}
-	var c Config
-	if err := json.Unmarshal(b, &c); err != nil {
-		return Config{}, err
+	if c.TelegramChatID == 0 {
+		return nil
 	}
+	return []Destination{{Name: "default", ChatID: c.TelegramChatID}}
+}
+
+// AddDestination agrega d o reemplaza el destino con el mismo chat. El chat de
+// telegram_chat_id pasa a la lista para no perderlo.
+func (c *Config) AddDestination(d Destination) {
+	c.Destinations = append([]Destination(nil), c.Targets()...)
+	for i, x := range c.Destinations {
+		if x.ChatID == d.ChatID {
+			c.Destinations[i] = d
+			return
+		}
+	}
+	c.Destinations = append(c.Destinations, d)
+}
+
+// RemoveDestination quita el destino del chat; devuelve false si no existía.
+func (c *Config) RemoveDestination(chatID int64) bool {
+	targets := c.Targets()
+	out := make([]Destination, 0, len(targets))
+	for _, x := range targets {
+		if x.ChatID != chatID {
+			out = append(out, x)
+		}
+	}
+	if len(out) == len(targets) {
+		return false
+	}
+	c.Destinations = out
+	if c.TelegramChatID == chatID {
+		c.TelegramChatID = 0
+	}
+	return true
+}
 
-	// Defaults / validaciones básicas
+// Load lee path, completa los valores por defecto y valida. Para sumar el
+// entorno y los flags ver LoadLayers.
+func Load(path string) (Config, error) {
+	l, err := LoadLayers(path, Overrides{})
+	return l.Config, err
+}
+
+// ApplyDefaults completa los campos vacíos o en cero con su valor por defecto.
+func (c *Config) ApplyDefaults() {
 	if c.CaptureFPS <= 0 {
 		c.CaptureFPS = 5
 	}
@@ -47,30 +218,128 @@ func Load(path string) (Config, error) {
 	if c.OutputDir == "" {
 		c.OutputDir = "assets/output"
 	}
+	if c.DatabasePath == "" {
+		c.DatabasePath = filepath.Join(c.OutputDir, "smartslide.db")
+	}
 	if c.MaxCaptionChars <= 0 {
 		c.MaxCaptionChars = 900
 	}
+	// límite de Telegram para captions
+	if c.MaxCaptionChars > 1024 {
+		c.MaxCaptionChars = 1024
+	}
+	if c.CaptionFormat == "" {
+		c.CaptionFormat = "plain"
+	}
+	if c.CaptionOverflow == "" {
+		c.CaptionOverflow = "truncate"
+	}
+	if c.BuildMode == "" {
+		c.BuildMode = "edit"
+	}
+	if c.AlbumSize <= 0 {
+		c.AlbumSize = 1
+	}
+	if c.AlbumSize > 10 {
+		c.AlbumSize = 10
+	}
+	if c.AlbumMaxWaitSeconds <= 0 {
+		c.AlbumMaxWaitSeconds = 60
+	}
 	if c.AdminHTTPAddr == "" {
 		c.AdminHTTPAddr = ":8080"
 	}
+	if c.AdminTLS.CertFile == "" {
+		c.AdminTLS.CertFile = filepath.Join(c.OutputDir, "admin_cert.pem")
+	}
+	if c.AdminTLS.KeyFile == "" {
+		c.AdminTLS.KeyFile = filepath.Join(c.OutputDir, "admin_key.pem")
+	}
+	if c.LogLevel == "" {
+		c.LogLevel = "info"
+	}
+	if c.LogBufferSize <= 0 {
+		c.LogBufferSize = 1000
+	}
 	if c.TesseractLang == "" {
 		c.TesseractLang = "spa"
 	}
 
-	if c.TelegramBotToken == "" {
-		return Config{}, errors.New("telegram_bot_token vacío")
+	if c.SummarizerBackend == "" {
+		c.SummarizerBackend = "rules"
 	}
-	if c.TelegramChatID == 0 {
-		return Config{}, errors.New("telegram_chat_id inválido (0)")
+	if c.LLMEndpoint == "" {
+		c.LLMEndpoint = "http://localhost:11434/v1"
+	}
+	if c.LLMTimeoutSeconds <= 0 {
+		c.LLMTimeoutSeconds = 15
+	}
+	if c.LLMContextSlides < 0 {
+		c.LLMContextSlides = 0
+	}
+	if c.ConfigHistorySize <= 0 {
+		c.ConfigHistorySize = 50
 	}
 
-	return c, nil
+	for i := range c.AdminAuth.Users {
+		if c.AdminAuth.Users[i].Role == "" {
+			c.AdminAuth.Users[i].Role = "read"
+		}
+	}
+	for i := range c.AdminAuth.Tokens {
+		if c.AdminAuth.Tokens[i].Role == "" {
+			c.AdminAuth.Tokens[i].Role = "read"
+		}
+	}
 }
 
+// Save escribe c en path de forma atómica: un archivo temporal en la misma
+// carpeta reemplaza al anterior, así un corte de luz no lo deja a medias.
 func Save(path string, c Config) error {
 	b, err := json.MarshalIndent(c, "", "  ")
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(path, b, 0644)
+	return writeFileAtomic(path, append(b, '\n'), 0644)
+}
+
+// writeFileAtomic escribe b en un temporal y lo renombra a path. Conserva los
+// permisos de path si ya existe y sigue los enlaces simbólicos.
+func writeFileAtomic(path string, b []byte, perm os.FileMode) error {
+	if real, err := filepath.EvalSymlinks(path); err == nil {
+		path = real
+	}
+	if st, err := os.Stat(path); err == nil {
+		perm = st.Mode().Perm()
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	ok := false
+	defer func() {
+		if !ok {
+			_ = os.Remove(tmp)
+		}
+	}()
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp, perm); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	ok = true
+	return nil
 }
@@ -0,0 +1,219 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrVersionNotFound indica una versión que no está (o ya no está) en el
+// historial.
+var ErrVersionNotFound = errors.New("versión no encontrada")
+
+// Version es una configuración guardada. En los listados Config va vacía.
+type Version struct {
+	Version int       `json:"version"`
+	SavedAt time.Time `json:"saved_at"`
+	Source  string    `json:"source"` // quién la guardó: "panel (ana)", "bot", "archivo", ...
+	Profile string    `json:"profile,omitempty"`
+	Config  *Config   `json:"config,omitempty"`
+}
+
+// History guarda cada configuración aplicada como vNNNNNN.json en dir y
+// conserva las últimas max.
+type History struct {
+	dir string
+
+	mu  sync.Mutex
+	max int
+}
+
+func NewHistory(dir string, max int) *History {
+	return &History{dir: dir, max: max}
+}
+
+// SetMax cambia cuántas versiones se conservan; se aplica al guardar la próxima.
+func (h *History) SetMax(max int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.max = max
+}
+
+// Add guarda c como la versión siguiente y borra las que sobran.
+func (h *History) Add(c Config, source string) (Version, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err := os.MkdirAll(h.dir, 0700); err != nil {
+		return Version{}, err
+	}
+	nums, err := h.versions()
+	if err != nil {
+		return Version{}, err
+	}
+	v := Version{SavedAt: time.Now(), Source: source, Profile: c.Profile, Config: &c}
+	v.Version = 1
+	if len(nums) > 0 {
+		v.Version = nums[len(nums)-1] + 1
+	}
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return Version{}, err
+	}
+	// incluye los secretos, como el archivo de configuración
+	if err := writeFileAtomic(h.path(v.Version), b, 0600); err != nil {
+		return Version{}, err
+	}
+
+	nums = append(nums, v.Version)
+	for len(nums) > max(h.max, 1) {
+		_ = os.Remove(h.path(nums[0]))
+		nums = nums[1:]
+	}
+	return v, nil
+}
+
+// Len devuelve cuántas versiones hay.
+func (h *History) Len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	nums, _ := h.versions()
+	return len(nums)
+}
+
+// List devuelve las versiones sin la configuración, la más reciente primero.
+func (h *History) List() ([]Version, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	nums, err := h.versions()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Version, 0, len(nums))
+	for i := len(nums) - 1; i >= 0; i-- {
+		v, err := h.read(nums[i])
+		if err != nil {
+			continue
+		}
+		v.Config = nil
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// Get devuelve la versión n con su configuración.
+func (h *History) Get(n int) (Version, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.read(n)
+}
+
+func (h *History) read(n int) (Version, error) {
+	b, err := os.ReadFile(h.path(n))
+	if errors.Is(err, os.ErrNotExist) {
+		return Version{}, ErrVersionNotFound
+	}
+	if err != nil {
+		return Version{}, err
+	}
+	var v Version
+	if err := json.Unmarshal(b, &v); err != nil {
+		return Version{}, fmt.Errorf("versión %d: %w", n, err)
+	}
+	if v.Config == nil {
+		return Version{}, fmt.Errorf("versión %d: sin configuración", n)
+	}
+	return v, nil
+}
+
+// versions devuelve los números guardados en orden creciente.
+func (h *History) versions() ([]int, error) {
+	entries, err := os.ReadDir(h.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var nums []int
+	for _, e := range entries {
+		var n int
+		if _, err := fmt.Sscanf(e.Name(), "v%06d.json", &n); err == nil && e.Name() == versionFile(n) {
+			nums = append(nums, n)
+		}
+	}
+	sort.Ints(nums)
+	return nums, nil
+}
+
+func (h *History) path(n int) string {
+	return filepath.Join(h.dir, versionFile(n))
+}
+
+func versionFile(n int) string {
+	return fmt.Sprintf("v%06d.json", n)
+}
+
+// Change es un campo que difiere entre dos configuraciones.
+type Change struct {
+	Field string `json:"field"`
+	From  any    `json:"from"` // nil = no estaba
+	To    any    `json:"to"`
+}
+
+// Diff devuelve los campos que cambian de a a b, en orden alfabético. Los
+// objetos se comparan campo a campo y las listas enteras; los secretos que
+// cambian se muestran como RedactedValue.
+func Diff(a, b Config) []Change {
+	ra, rb := a.Redacted(), b.Redacted()
+	var out []Change
+	diffJSON("", toJSON(a), toJSON(b), func(field string) {
+		out = append(out, Change{Field: field, From: lookup(toJSON(ra), field), To: lookup(toJSON(rb), field)})
+	})
+	sort.Slice(out, func(i, j int) bool { return out[i].Field < out[j].Field })
+	return out
+}
+
+func diffJSON(path string, a, b any, changed func(string)) {
+	ma, okA := a.(map[string]any)
+	mb, okB := b.(map[string]any)
+	if !okA || !okB {
+		if !reflect.DeepEqual(a, b) {
+			changed(path)
+		}
+		return
+	}
+	keys := map[string]bool{}
+	for k := range ma {
+		keys[k] = true
+	}
+	for k := range mb {
+		keys[k] = true
+	}
+	for k := range keys {
+		diffJSON(joinPath(path, k), ma[k], mb[k], changed)
+	}
+}
+
+func toJSON(c Config) any {
+	b, _ := json.Marshal(c)
+	v, _ := decodeJSON(b)
+	return v
+}
+
+func lookup(v any, path string) any {
+	for _, k := range strings.Split(path, ".") {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		v = m[k]
+	}
+	return v
+}
@@ -0,0 +1,366 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// DefaultPath es el archivo de configuración si no se indica otro con
+// -config o SMARTSLIDE_CONFIG.
+const DefaultPath = "configs/config.json"
+
+// EnvPrefix antecede a las variables de entorno de la configuración: la ruta
+// JSON en mayúsculas con "_" en lugar de "." (SMARTSLIDE_CAMERA_INDEX,
+// SMARTSLIDE_ROI_X, SMARTSLIDE_TELEGRAM_ALLOWED_USERS=1,2). Los secretos
+// aceptan además el sufijo _FILE con la ruta de un archivo que contiene el
+// valor (SMARTSLIDE_TELEGRAM_BOT_TOKEN_FILE=/run/secrets/bot_token).
+const EnvPrefix = "SMARTSLIDE_"
+
+// EnvConfig es la variable con la ruta del archivo de configuración.
+const EnvConfig = EnvPrefix + "CONFIG"
+
+// Orígenes de un valor en Sources. Los de entorno y flags llevan además el
+// nombre: "env:SMARTSLIDE_CAMERA_INDEX", "flag:-camera".
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+)
+
+// Sources dice de dónde salió cada valor efectivo, por ruta JSON.
+type Sources map[string]string
+
+// Overridden devuelve las rutas fijadas por entorno o flags, en orden.
+func (s Sources) Overridden() []string {
+	var out []string
+	for path, src := range s {
+		if src != SourceDefault && src != SourceFile {
+			out = append(out, path)
+		}
+	}
+	slices.Sort(out)
+	return out
+}
+
+// Overrides son los valores que se ponen sobre el archivo. Se aplican en el
+// orden en que se agregaron: primero el entorno, después los flags.
+type Overrides struct {
+	list    []override
+	unknown []string
+}
+
+type override struct {
+	path   string
+	value  string
+	file   bool // value es la ruta de un archivo con el valor
+	source string
+}
+
+// FromEnv arma los overrides de las variables SMARTSLIDE_* de environ (con el
+// formato de os.Environ). Una variable que no corresponde a ningún campo se
+// ignora y queda en Unknown, para avisar de un nombre mal escrito sin impedir
+// arrancar (p. ej. una variable de otra versión).
+func FromEnv(environ []string) (Overrides, error) {
+	byEnv := map[string]string{}
+	for path := range overridable() {
+		byEnv[envName(path)] = path
+	}
+	var o Overrides
+	var errs ValidationError
+	environ = slices.Clone(environ)
+	slices.Sort(environ)
+	for _, kv := range environ {
+		name, value, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(name, EnvPrefix) || name == EnvConfig {
+			continue
+		}
+		src := "env:" + name
+		if path, ok := byEnv[name]; ok {
+			if err := o.Set(path, value, src); err != nil {
+				errs.add(path, "%s: %v", name, err)
+			}
+			continue
+		}
+		if base, ok := strings.CutSuffix(name, "_FILE"); ok {
+			if path, ok := byEnv[base]; ok {
+				if err := o.SetFile(path, value, src); err != nil {
+					errs.add(path, "%s: %v", name, err)
+				}
+				continue
+			}
+		}
+		o.unknown = append(o.unknown, name)
+	}
+	if len(errs) > 0 {
+		return Overrides{}, errs
+	}
+	return o, nil
+}
+
+// Unknown devuelve las variables SMARTSLIDE_* que FromEnv ignoró porque no
+// corresponden a ningún campo.
+func (o Overrides) Unknown() []string {
+	return o.unknown
+}
+
+// Set fija el campo path (ruta JSON, p. ej. "roi.x") al valor dado como texto:
+// números, true/false, listas separadas por comas o como JSON.
+func (o *Overrides) Set(path, value, source string) error {
+	t, ok := overridable()[path]
+	if !ok {
+		return fmt.Errorf("campo %q desconocido o no se puede fijar", path)
+	}
+	if _, err := parseValue(t, value); err != nil {
+		return err
+	}
+	o.list = append(o.list, override{path: path, value: value, source: source})
+	return nil
+}
+
+// SetFile fija el campo secreto path al contenido del archivo file (sin los
+// espacios y saltos de línea de los extremos). El archivo se lee al cargar,
+// así que un secreto rotado se toma en la próxima recarga.
+func (o *Overrides) SetFile(path, file, source string) error {
+	if !specs[path].Secret {
+		return fmt.Errorf("solo los secretos se leen de un archivo")
+	}
+	if file == "" {
+		return fmt.Errorf("ruta vacía")
+	}
+	o.list = append(o.list, override{path: path, value: file, file: true, source: source})
+	return nil
+}
+
+// Apply pone los overrides sobre c y anota su origen en src (si no es nil).
+func (o Overrides) Apply(c *Config, src Sources) error {
+	for _, ov := range o.list {
+		value := ov.value
+		if ov.file {
+			b, err := os.ReadFile(ov.value)
+			if err != nil {
+				return fmt.Errorf("%s: %w", ov.source, err)
+			}
+			value = strings.TrimSpace(string(b))
+		}
+		f, _ := fieldByPath(reflect.ValueOf(c).Elem(), ov.path)
+		v, err := parseValue(f.Type(), value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", ov.source, err)
+		}
+		f.Set(v)
+		if src != nil {
+			src[ov.path] = ov.source
+		}
+	}
+	return nil
+}
+
+// Keep copia de file a c los campos fijados por los overrides, para guardar c
+// sin los valores del entorno ni de los flags (p. ej. un token leído de un
+// archivo). Devuelve los campos que c intentaba cambiar: esos cambios no
+// tienen efecto mientras siga el override.
+func (o Overrides) Keep(c *Config, effective, file Config) []string {
+	var ignored []string
+	cv, ev, fv := reflect.ValueOf(c).Elem(), reflect.ValueOf(effective), reflect.ValueOf(file)
+	for _, path := range o.paths() {
+		dst, _ := fieldByPath(cv, path)
+		cur, _ := fieldByPath(ev, path)
+		saved, _ := fieldByPath(fv, path)
+		if !sameValue(dst, cur) {
+			ignored = append(ignored, path)
+		}
+		dst.Set(saved)
+	}
+	return ignored
+}
+
+// sameValue compara dos valores de un campo; una lista nil y una vacía son
+// iguales (el panel manda [] donde el archivo no tenía nada).
+func sameValue(a, b reflect.Value) bool {
+	if k := a.Kind(); (k == reflect.Slice || k == reflect.Map) && a.Len() == 0 && b.Len() == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
+// paths devuelve los campos con override, sin repetir.
+func (o Overrides) paths() []string {
+	var out []string
+	for _, ov := range o.list {
+		if !slices.Contains(out, ov.path) {
+			out = append(out, ov.path)
+		}
+	}
+	return out
+}
+
+// Loaded es una configuración cargada por capas: valores por defecto, archivo,
+// entorno y flags.
+type Loaded struct {
+	Config  Config // la efectiva, validada
+	File    Config // solo valores por defecto y archivo: lo que se guarda
+	Sources Sources
+	Hash    [32]byte // sha256 del archivo tal como se leyó
+}
+
+// LoadLayers lee path y le aplica los overrides. Solo se valida la
+// configuración efectiva: el archivo puede, por ejemplo, no tener el token si
+// llega por el entorno.
+func LoadLayers(path string, o Overrides) (Loaded, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Loaded{}, err
+	}
+	var file Config
+	if err := json.Unmarshal(b, &file); err != nil {
+		return Loaded{}, err
+	}
+	raw, _ := decodeJSON(b)
+
+	l := Loaded{Sources: Sources{}, Hash: sha256.Sum256(b)}
+	for _, p := range leaves() {
+		l.Sources[p] = SourceDefault
+		if lookup(raw, p) != nil {
+			l.Sources[p] = SourceFile
+		}
+	}
+	l.Config = file
+	if err := o.Apply(&l.Config, l.Sources); err != nil {
+		return Loaded{}, err
+	}
+	l.Config.ApplyDefaults()
+	if err := l.Config.Validate(); err != nil {
+		return Loaded{}, err
+	}
+	file.ApplyDefaults()
+	l.File = file
+	return l, nil
+}
+
+// overridable son los campos que se pueden fijar desde el entorno o flags, con
+// su tipo: los de texto, número, booleano y las listas simples. Las listas de
+// objetos (destinations, admin_auth.users) y los perfiles solo van en el archivo.
+func overridable() map[string]reflect.Type {
+	out := map[string]reflect.Type{}
+	var visit func(t reflect.Type, path string)
+	visit = func(t reflect.Type, path string) {
+		for i := 0; i < t.NumField(); i++ {
+			name := jsonName(t.Field(i))
+			if name == "" {
+				continue
+			}
+			ft := t.Field(i).Type
+			p := joinPath(path, name)
+			switch ft.Kind() {
+			case reflect.Struct:
+				visit(ft, p)
+			case reflect.String, reflect.Bool, reflect.Int, reflect.Int64, reflect.Float64:
+				out[p] = ft
+			case reflect.Slice:
+				if k := ft.Elem().Kind(); k == reflect.String || k == reflect.Int64 {
+					out[p] = ft
+				}
+			}
+		}
+	}
+	visit(reflect.TypeOf(Config{}), "")
+	return out
+}
+
+// leaves son las rutas que informa Sources: los campos de overridable más las
+// listas de objetos y los mapas, que cuentan enteros.
+func leaves() []string {
+	var out []string
+	var visit func(t reflect.Type, path string)
+	visit = func(t reflect.Type, path string) {
+		for i := 0; i < t.NumField(); i++ {
+			name := jsonName(t.Field(i))
+			if name == "" {
+				continue
+			}
+			if ft := t.Field(i).Type; ft.Kind() == reflect.Struct {
+				visit(ft, joinPath(path, name))
+			} else {
+				out = append(out, joinPath(path, name))
+			}
+		}
+	}
+	visit(reflect.TypeOf(Config{}), "")
+	return out
+}
+
+func envName(path string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(path, ".", "_"))
+}
+
+// fieldByPath devuelve el campo de v (una estructura) con la ruta JSON path.
+func fieldByPath(v reflect.Value, path string) (reflect.Value, bool) {
+	for _, name := range strings.Split(path, ".") {
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}, false
+		}
+		found := false
+		for i := 0; i < v.NumField(); i++ {
+			if jsonName(v.Type().Field(i)) == name {
+				v, found = v.Field(i), true
+				break
+			}
+		}
+		if !found {
+			return reflect.Value{}, false
+		}
+	}
+	return v, true
+}
+
+// parseValue convierte s al tipo t. Las listas aceptan "a,b" o JSON ("[1,2]").
+func parseValue(t reflect.Type, s string) (reflect.Value, error) {
+	v := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(s))
+		if err != nil {
+			return v, fmt.Errorf("%q no es true/false", s)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+		if err != nil {
+			return v, fmt.Errorf("%q no es un número entero", s)
+		}
+		v.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil {
+			return v, fmt.Errorf("%q no es un número", s)
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		if strings.HasPrefix(strings.TrimSpace(s), "[") {
+			if err := json.Unmarshal([]byte(s), v.Addr().Interface()); err != nil {
+				return v, fmt.Errorf("lista JSON inválida: %v", err)
+			}
+			return v, nil
+		}
+		for _, part := range strings.Split(s, ",") {
+			if part = strings.TrimSpace(part); part == "" {
+				continue
+			}
+			e, err := parseValue(t.Elem(), part)
+			if err != nil {
+				return v, err
+			}
+			v = reflect.Append(v, e)
+		}
+	default:
+		return v, fmt.Errorf("tipo %s no soportado", t)
+	}
+	return v, nil
+}
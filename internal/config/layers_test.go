@@ -0,0 +1,75 @@
+package config
+
+import (
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFromEnvUnknown(t *testing.T) {
+	o, err := FromEnv([]string{
+		"SMARTSLIDE_CAMERA_INDEX=1",
+		"SMARTSLIDE_CAMARA_INDEX=2",
+		"SMARTSLIDE_CONFIG=otra.json",
+		"HOME=/root",
+	})
+	if err != nil {
+		t.Fatalf("una variable desconocida no debería impedir arrancar: %v", err)
+	}
+	if want := []string{"SMARTSLIDE_CAMARA_INDEX"}; !reflect.DeepEqual(o.Unknown(), want) {
+		t.Errorf("Unknown = %v, se esperaba %v", o.Unknown(), want)
+	}
+	var c Config
+	if err := o.Apply(&c, nil); err != nil || c.CameraIndex != 1 {
+		t.Errorf("camera_index = %d, err = %v", c.CameraIndex, err)
+	}
+
+	if _, err := FromEnv([]string{"SMARTSLIDE_CAMERA_INDEX=uno"}); err == nil {
+		t.Error("un valor inválido debería ser un error")
+	}
+}
+
+func TestKeepEmptyList(t *testing.T) {
+	o, err := FromEnv([]string{"SMARTSLIDE_TELEGRAM_ALLOWED_USERS=", "SMARTSLIDE_CAMERA_INDEX=1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var file, effective Config
+	if err := o.Apply(&effective, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// el panel devuelve [] donde la configuración efectiva tenía nil
+	c := effective
+	c.TelegramAllowedUsers = []int64{}
+	if ignored := o.Keep(&c, effective, file); len(ignored) != 0 {
+		t.Errorf("ignorados = %v", ignored)
+	}
+
+	c = effective
+	c.TelegramAllowedUsers = []int64{111}
+	c.CameraIndex = 2
+	if ignored := o.Keep(&c, effective, file); !reflect.DeepEqual(ignored, []string{"camera_index", "telegram_allowed_users"}) {
+		t.Errorf("ignorados = %v", ignored)
+	}
+	if c.CameraIndex != 0 || c.TelegramAllowedUsers != nil {
+		t.Errorf("Keep no volvió al archivo: %+v", c)
+	}
+}
+
+func TestLoadLayersHash(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	b := []byte(`{"telegram_bot_token": "123:TEST", "telegram_chat_id": -100123}`)
+	if err := os.WriteFile(path, b, 0600); err != nil {
+		t.Fatal(err)
+	}
+	l, err := LoadLayers(path, Overrides{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Hash != sha256.Sum256(b) {
+		t.Error("el hash no es el del archivo leído")
+	}
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// RedactedValue reemplaza a los secretos en las respuestas del panel. Si vuelve
+// en una actualización, RestoreSecrets conserva el valor guardado.
+const RedactedValue = "********"
+
+// Redacted devuelve una copia de c sin el token del bot, la API key del LLM ni
+// los hashes de admin_auth.
+func (c Config) Redacted() Config {
+	redact(&c.TelegramBotToken)
+	redact(&c.LLMAPIKey)
+	c.AdminAuth.Users = append([]AdminUser(nil), c.AdminAuth.Users...)
+	for i := range c.AdminAuth.Users {
+		redact(&c.AdminAuth.Users[i].PasswordHash)
+	}
+	c.AdminAuth.Tokens = append([]AdminToken(nil), c.AdminAuth.Tokens...)
+	for i := range c.AdminAuth.Tokens {
+		redact(&c.AdminAuth.Tokens[i].Hash)
+	}
+	return c
+}
+
+func redact(s *string) {
+	if *s != "" {
+		*s = RedactedValue
+	}
+}
+
+// RestoreSecrets copia de prev los secretos que en c siguen ocultos. Los
+// usuarios se buscan por nombre y los tokens por nombre o posición.
+func (c *Config) RestoreSecrets(prev Config) {
+	restore(&c.TelegramBotToken, prev.TelegramBotToken)
+	restore(&c.LLMAPIKey, prev.LLMAPIKey)
+	for i := range c.AdminAuth.Users {
+		u := &c.AdminAuth.Users[i]
+		for _, p := range prev.AdminAuth.Users {
+			if p.Name == u.Name {
+				restore(&u.PasswordHash, p.PasswordHash)
+				break
+			}
+		}
+	}
+	for i := range c.AdminAuth.Tokens {
+		t := &c.AdminAuth.Tokens[i]
+		if t.Hash != RedactedValue {
+			continue
+		}
+		for j, p := range prev.AdminAuth.Tokens {
+			if p.Name == t.Name && (p.Name != "" || i == j) {
+				t.Hash = p.Hash
+				break
+			}
+		}
+	}
+}
+
+func restore(s *string, prev string) {
+	if *s == RedactedValue {
+		*s = prev
+	}
+}
+
+// MergePatch aplica a c un JSON merge patch (RFC 7396): los campos del parche
+// reemplazan a los de c, los objetos se combinan campo a campo, null vacía el
+// campo (los textos vuelven a su valor por defecto; los números quedan en cero
+// y Validate decide) y las listas se reemplazan enteras. Los campos
+// desconocidos y los de tipo incorrecto son errores de validación.
+func MergePatch(c Config, patch []byte) (Config, error) {
+	p, err := decodeJSON(patch)
+	if err != nil {
+		return Config{}, err
+	}
+	if _, ok := p.(map[string]any); !ok {
+		return Config{}, errors.New("el parche debe ser un objeto JSON")
+	}
+	b, err := json.Marshal(c)
+	if err != nil {
+		return Config{}, err
+	}
+	doc, err := decodeJSON(b)
+	if err != nil {
+		return Config{}, err
+	}
+	if b, err = json.Marshal(mergePatch(doc, p)); err != nil {
+		return Config{}, err
+	}
+
+	var out Config
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&out); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return Config{}, ValidationError{{Field: typeErr.Field, Message: fmt.Sprintf("se esperaba %s", typeErr.Type)}}
+		}
+		if name, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			return Config{}, ValidationError{{Field: strings.Trim(name, `"`), Message: "campo desconocido"}}
+		}
+		return Config{}, err
+	}
+	return out, nil
+}
+
+// decodeJSON usa json.Number para no perder precisión en los IDs de chat.
+func decodeJSON(b []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func mergePatch(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = map[string]any{}
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+		} else {
+			t[k] = mergePatch(t[k], v)
+		}
+	}
+	return t
+}
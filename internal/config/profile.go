@@ -0,0 +1,172 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ErrProfileNotFound indica un perfil que no está en profiles.
+var ErrProfileNotFound = errors.New("perfil no encontrado")
+
+// profileName son los nombres válidos de perfil ("aula-101", "auditorio").
+var profileName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// profileForbidden son los campos que un perfil no puede cambiar: los
+// secretos, el acceso al panel y los propios perfiles.
+var profileForbidden = []string{
+	"telegram_bot_token", "llm_api_key", "admin_auth", "profiles", "profile",
+	"profile_base", "schedule", "schedule_ics",
+}
+
+// WithProfile devuelve c con el perfil name aplicado y marcado como activo. El
+// perfil se aplica sobre Base: elegir otro perfil no conserva los campos que
+// cambiaba el anterior.
+func (c Config) WithProfile(name string) (Config, error) {
+	base, err := c.Base()
+	if err != nil {
+		return Config{}, err
+	}
+	patch, ok := base.Profiles[name]
+	if !ok {
+		return Config{}, ErrProfileNotFound
+	}
+	prev, err := fieldValues(base, patch)
+	if err != nil {
+		return Config{}, err
+	}
+	out, err := MergePatch(base, patch)
+	if err != nil {
+		return Config{}, err
+	}
+	out.Profile, out.ProfileBase = name, prev
+	return out, nil
+}
+
+// Base devuelve c sin el perfil activo: los campos que cambió vuelven a lo que
+// valían antes de aplicarlo (profile_base). Un cambio a mano en esos campos
+// mientras el perfil está activo se pierde.
+func (c Config) Base() (Config, error) {
+	if len(c.ProfileBase) == 0 {
+		c.Profile = ""
+		return c, nil
+	}
+	patch, err := json.Marshal(c.ProfileBase)
+	if err != nil {
+		return Config{}, err
+	}
+	base, err := MergePatch(c, patch)
+	if err != nil {
+		return Config{}, fmt.Errorf("profile_base: %w", err)
+	}
+	base.Profile, base.ProfileBase = "", nil
+	return base, nil
+}
+
+// fieldValues devuelve lo que vale en c cada campo de primer nivel que cambia
+// patch, como JSON ("null" si no está).
+func fieldValues(c Config, patch json.RawMessage) (map[string]json.RawMessage, error) {
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &keys); err != nil {
+		return nil, errors.New("el perfil debe ser un objeto JSON")
+	}
+	b, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	out := make(map[string]json.RawMessage, len(keys))
+	for k := range keys {
+		if v, ok := doc[k]; ok {
+			out[k] = v
+		} else {
+			out[k] = json.RawMessage("null")
+		}
+	}
+	return out, nil
+}
+
+// ProfileNames devuelve los nombres de los perfiles en orden alfabético.
+func (c Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CompactProfile valida que patch sea un objeto JSON y lo devuelve compactado
+// para guardarlo en profiles.
+func CompactProfile(patch []byte) (json.RawMessage, error) {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, patch); err != nil {
+		return nil, err
+	}
+	if b := buf.Bytes(); len(b) == 0 || b[0] != '{' {
+		return nil, errors.New("el perfil debe ser un objeto JSON")
+	}
+	return buf.Bytes(), nil
+}
+
+// validateProfiles revisa nombres y campos de los perfiles y que cada uno,
+// aplicado sobre la configuración sin perfil (Base), dé una configuración
+// válida.
+func (c Config) validateProfiles(errs *ValidationError) {
+	if c.Profile != "" {
+		if _, ok := c.Profiles[c.Profile]; !ok {
+			errs.add("profile", "no existe el perfil %q", c.Profile)
+		}
+	}
+	base, err := c.Base()
+	if err != nil {
+		errs.add("profile_base", "%v", err)
+		return
+	}
+	base.Profiles = nil
+	for _, name := range c.ProfileNames() {
+		field := "profiles." + name
+		if !profileName.MatchString(name) {
+			errs.add(field, "nombre inválido (letras, números, '.', '-' y '_')")
+			continue
+		}
+		var keys map[string]json.RawMessage
+		if err := json.Unmarshal(c.Profiles[name], &keys); err != nil {
+			errs.add(field, "debe ser un objeto JSON")
+			continue
+		}
+		bad := false
+		for _, k := range profileForbidden {
+			if _, ok := keys[k]; ok {
+				errs.add(field+"."+k, "no se puede cambiar desde un perfil")
+				bad = true
+			}
+		}
+		if bad {
+			continue
+		}
+		merged, err := MergePatch(base, c.Profiles[name])
+		if err == nil {
+			err = merged.Validate()
+		}
+		var verr ValidationError
+		if errors.As(err, &verr) {
+			// solo los campos que cambia el perfil; los demás ya los informa c
+			for _, fe := range verr {
+				top, _, _ := strings.Cut(strings.SplitN(fe.Field, "[", 2)[0], ".")
+				if _, ok := keys[top]; ok {
+					errs.add(field+"."+fe.Field, "%s", fe.Message)
+				}
+			}
+		} else if err != nil {
+			errs.add(field, "%v", err)
+		}
+	}
+}
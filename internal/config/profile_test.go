@@ -0,0 +1,75 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func profileConfig(t *testing.T) Config {
+	t.Helper()
+	c := Config{TelegramBotToken: "123:TEST", TelegramChatID: -100123}
+	c.ApplyDefaults()
+	c.Profiles = map[string]json.RawMessage{
+		"aula-101":  json.RawMessage(`{"sensitivity": 0.2, "roi": {"x": 0.1, "w": 0.8}}`),
+		"auditorio": json.RawMessage(`{"camera_index": 1}`),
+	}
+	return c
+}
+
+// Cambiar de perfil no conserva los campos que cambiaba el anterior.
+func TestWithProfileOverBase(t *testing.T) {
+	base := profileConfig(t)
+
+	a, err := base.WithProfile("aula-101")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Sensitivity != 0.2 || a.ROI.X != 0.1 || a.Profile != "aula-101" {
+		t.Fatalf("aula-101: sensitivity %v, roi %+v, profile %q", a.Sensitivity, a.ROI, a.Profile)
+	}
+
+	// el perfil activo sobrevive a guardar y volver a leer la configuración
+	b, err := json.Marshal(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved Config
+	if err := json.Unmarshal(b, &saved); err != nil {
+		t.Fatal(err)
+	}
+
+	aud, err := saved.WithProfile("auditorio")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if aud.CameraIndex != 1 || aud.Sensitivity != base.Sensitivity || aud.ROI != base.ROI {
+		t.Errorf("auditorio: camera %d, sensitivity %v, roi %+v; base: sensitivity %v, roi %+v",
+			aud.CameraIndex, aud.Sensitivity, aud.ROI, base.Sensitivity, base.ROI)
+	}
+	if err := aud.Validate(); err != nil {
+		t.Error(err)
+	}
+
+	back, err := aud.Base()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back.CameraIndex != base.CameraIndex || back.Profile != "" || back.ProfileBase != nil {
+		t.Errorf("Base = camera %d, profile %q, profile_base %v", back.CameraIndex, back.Profile, back.ProfileBase)
+	}
+
+	// volver a aplicar el mismo perfil no cambia nada
+	again, err := aud.WithProfile("auditorio")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.CameraIndex != 1 || string(again.ProfileBase["camera_index"]) != "0" {
+		t.Errorf("auditorio otra vez: camera %d, profile_base %s", again.CameraIndex, again.ProfileBase["camera_index"])
+	}
+}
+
+func TestWithProfileUnknown(t *testing.T) {
+	if _, err := profileConfig(t).WithProfile("nada"); err != ErrProfileNotFound {
+		t.Errorf("err = %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+
+	"IA1_EV2025_Proyecto2/internal/schedule"
+)
+
+// ScheduleEntry es una clase semanal del horario, en la hora local.
+type ScheduleEntry struct {
+	Weekday string  `json:"weekday"`  // "lunes" ... "domingo" (o en inglés, o "lun")
+	Start   string  `json:"start"`    // "08:00"
+	End     string  `json:"end"`      // "09:40", el mismo día
+	Title   string  `json:"title"`    // título de la sesión; vacío = course_name
+	ChatIDs []int64 `json:"chat_ids"` // solo estos destinos; vacío = todos
+	Profile string  `json:"profile"`  // perfil que se aplica al iniciar
+}
+
+// Weekly convierte la entrada para el planificador.
+func (e ScheduleEntry) Weekly() (schedule.Weekly, error) {
+	day, err := schedule.ParseWeekday(e.Weekday)
+	if err != nil {
+		return schedule.Weekly{}, err
+	}
+	start, err := schedule.ParseClock(e.Start)
+	if err != nil {
+		return schedule.Weekly{}, err
+	}
+	end, err := schedule.ParseClock(e.End)
+	if err != nil {
+		return schedule.Weekly{}, err
+	}
+	return schedule.Weekly{Day: day, Start: start, End: end, Title: e.Title, ChatIDs: e.ChatIDs, Profile: e.Profile}, nil
+}
+
+// WeeklySchedule devuelve las clases de schedule; las inválidas se omiten
+// (Validate ya las rechaza, también si les falta el día o una hora).
+func (c Config) WeeklySchedule() []schedule.Weekly {
+	var out []schedule.Weekly
+	for _, e := range c.Schedule {
+		if w, err := e.Weekly(); err == nil {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+func (c Config) validateSchedule(errs *ValidationError) {
+	var chats []int64
+	for _, d := range c.Targets() {
+		chats = append(chats, d.ChatID)
+	}
+	for i, e := range c.Schedule {
+		field := fmt.Sprintf("schedule[%d]", i)
+		if e.Weekday == "" {
+			errs.add(field+".weekday", "requerido")
+		} else if _, err := schedule.ParseWeekday(e.Weekday); err != nil {
+			errs.add(field+".weekday", "%v", err)
+		}
+		var start, end schedule.Clock
+		var err error
+		if e.Start == "" {
+			errs.add(field+".start", "requerido")
+		} else if start, err = schedule.ParseClock(e.Start); err != nil {
+			errs.add(field+".start", "%v", err)
+		}
+		if e.End == "" {
+			errs.add(field+".end", "requerido")
+		} else if end, err = schedule.ParseClock(e.End); err != nil {
+			errs.add(field+".end", "%v", err)
+		} else if e.Start != "" && end <= start {
+			errs.add(field+".end", "debe ser posterior a start")
+		}
+		for _, chat := range e.ChatIDs {
+			if !slices.Contains(chats, chat) {
+				errs.add(field+".chat_ids", "el chat %d no está en los destinos", chat)
+			}
+		}
+		if e.Profile != "" {
+			if _, ok := c.Profiles[e.Profile]; !ok {
+				errs.add(field+".profile", "no existe el perfil %q", e.Profile)
+			}
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateScheduleRequired(t *testing.T) {
+	c := Config{TelegramBotToken: "123:TEST", TelegramChatID: -100123}
+	c.ApplyDefaults()
+	c.Schedule = []ScheduleEntry{
+		{Weekday: "lunes", Start: "08:00", End: "09:40"},
+		{Start: "10:00"},
+	}
+	var verr ValidationError
+	if err := c.Validate(); !errors.As(err, &verr) {
+		t.Fatalf("err = %v", err)
+	}
+	got := map[string]string{}
+	for _, e := range verr {
+		got[e.Field] = e.Message
+	}
+	for _, f := range []string{"schedule[1].weekday", "schedule[1].end"} {
+		if got[f] != "requerido" {
+			t.Errorf("%s: %q (errores: %v)", f, got[f], verr)
+		}
+	}
+	if len(verr) != 2 {
+		t.Errorf("errores: %v", verr)
+	}
+}
@@ -0,0 +1,194 @@
+package config
+
+import (
+	"reflect"
+	"sync"
+)
+
+// spec describe un campo para Validate y para el JSON Schema del panel. La
+// clave en specs es la ruta JSON con "[]" para los elementos de una lista.
+type spec struct {
+	Description string
+	Enum        []string // además se acepta "" (= valor por defecto)
+	Min, Max    *float64
+	Required    bool
+	Secret      bool   // se oculta en las respuestas (ver Redacted)
+	Format      string // formato de JSON Schema: "uri", "password", ...
+}
+
+func num(v float64) *float64 { return &v }
+
+var specs = map[string]spec{
+	"camera_index":               {Description: "Índice de la cámara (/dev/videoN)", Min: num(0)},
+	"capture_fps":                {Description: "Frames por segundo que se leen de la cámara", Min: num(1), Max: num(60)},
+	"sensitivity":                {Description: "Proporción de pixeles que deben cambiar para detectar una diapositiva nueva", Min: num(0.001), Max: num(1)},
+	"min_seconds_between_slides": {Description: "Pausa mínima entre dos detecciones, en segundos", Min: num(1), Max: num(3600)},
+	"roi":                        {Description: "Zona del frame que se compara, en fracciones del ancho y alto; w o h en 0 = todo el frame"},
+	"roi.x":                      {Description: "Borde izquierdo (0..1)", Min: num(0), Max: num(1)},
+	"roi.y":                      {Description: "Borde superior (0..1)", Min: num(0), Max: num(1)},
+	"roi.w":                      {Description: "Ancho (0..1)", Min: num(0), Max: num(1)},
+	"roi.h":                      {Description: "Alto (0..1)", Min: num(0), Max: num(1)},
+
+	"telegram_bot_token":              {Description: "Token del bot de Telegram", Required: true, Secret: true, Format: "password"},
+	"telegram_chat_id":                {Description: "Chat que recibe las diapositivas si no hay destinations"},
+	"destinations":                    {Description: "Chats con filtros propios"},
+	"destinations[].name":             {Description: "Nombre del destino"},
+	"destinations[].chat_id":          {Description: "ID del chat de Telegram"},
+	"destinations[].sessions":         {Description: "Solo estas sesiones (ID o título); vacío = todas"},
+	"destinations[].min_change_score": {Description: "Change score mínimo para enviar", Min: num(0), Max: num(1)},
+	"destinations[].keywords":         {Description: "Enviar solo si aparece alguna; vacío = todas"},
+	"destinations[].caption_template": {Description: "Plantilla text/template; vacío = caption_template"},
+	"destinations[].caption_format":   {Description: "Formato del caption; vacío = caption_format", Enum: []string{"plain", "markdownv2", "markdown", "html"}},
+	"destinations[].language":         {Description: "Idioma de las etiquetas", Enum: []string{"es", "en"}},
+	"telegram_allowed_users":          {Description: "Usuarios que pueden usar los comandos del bot; vacío = comandos desactivados"},
+	"telegram_api_endpoint":           {Description: "Servidor de la Bot API; vacío = api.telegram.org", Format: "uri"},
+
+	"tesseract_lang":        {Description: "Idiomas de Tesseract (p. ej. spa o spa+eng)"},
+	"enable_ocr_correction": {Description: "Corregir el texto del OCR con diccionario y glosario"},
+	"glossary":              {Description: "Términos del curso"},
+	"dictionary_files":      {Description: "Listas de palabras extra, una por línea"},
+	"course_name":           {Description: "Nombre del curso en el caption"},
+
+	"output_dir":             {Description: "Carpeta de imágenes, notas y base de datos"},
+	"enable_annotation":      {Description: "Enviar la diapositiva anotada"},
+	"max_caption_chars":      {Description: "Largo máximo del caption (límite de Telegram: 1024)", Min: num(1), Max: num(1024)},
+	"database_path":          {Description: "Base de datos; vacío = smartslide.db en output_dir"},
+	"caption_template":       {Description: "Plantilla text/template del caption; vacío = la clásica"},
+	"caption_format":         {Description: "Formato del caption", Enum: []string{"plain", "markdownv2", "markdown", "html"}},
+	"caption_overflow":       {Description: "Qué hacer con lo que no cabe en el caption", Enum: []string{"truncate", "followup"}},
+	"build_mode":             {Description: "Revelaciones incrementales", Enum: []string{"edit", "diff", "off"}},
+	"album_size":             {Description: "Diapositivas por álbum (1 = una a una)", Min: num(1), Max: num(10)},
+	"album_max_wait_seconds": {Description: "Espera máxima para completar un álbum, en segundos", Min: num(1), Max: num(3600)},
+	"send_table_documents":   {Description: "Enviar las tablas como CSV"},
+
+	"admin_http_addr":                  {Description: "Dirección del panel (p. ej. :8080)"},
+	"admin_auth":                       {Description: "Credenciales del panel; sin usuarios ni tokens no se pide autenticación"},
+	"admin_auth.users[].name":          {Description: "Usuario", Required: true},
+	"admin_auth.users[].password_hash": {Description: "Hash de \"smartslide auth password\"", Required: true, Secret: true},
+	"admin_auth.users[].role":          {Description: "Rol", Enum: []string{"read", "admin"}},
+	"admin_auth.tokens[].name":         {Description: "Nombre del token"},
+	"admin_auth.tokens[].hash":         {Description: "Hash de \"smartslide auth token\"", Required: true, Secret: true},
+	"admin_auth.tokens[].role":         {Description: "Rol", Enum: []string{"read", "admin"}},
+	"admin_auth.session_hours":         {Description: "Duración del login en horas; 0 = 12", Min: num(0), Max: num(8760)},
+	"admin_cors_origins":               {Description: "Orígenes que pueden usar el panel desde el navegador; \"*\" = cualquiera"},
+	"admin_tls.enabled":                {Description: "Atender el panel por HTTPS"},
+	"admin_tls.cert_file":              {Description: "Certificado PEM; si no existe se genera uno autofirmado"},
+	"admin_tls.key_file":               {Description: "Clave privada PEM"},
+	"admin_tls.hosts":                  {Description: "Nombres o IP extra del certificado generado"},
+
+	"log_level":       {Description: "Nivel mínimo de los logs", Enum: []string{"debug", "info", "warn", "error"}},
+	"log_buffer_size": {Description: "Registros que guarda el panel en memoria", Min: num(1), Max: num(100000)},
+
+	"summarizer_backend":  {Description: "Resumen por reglas o con un LLM", Enum: []string{"rules", "llm"}},
+	"llm_endpoint":        {Description: "Endpoint compatible con OpenAI", Format: "uri"},
+	"llm_model":           {Description: "Modelo del LLM"},
+	"llm_api_key":         {Description: "API key del LLM", Secret: true, Format: "password"},
+	"llm_timeout_seconds": {Description: "Tiempo máximo por resumen, en segundos", Min: num(1), Max: num(600)},
+	"llm_context_slides":  {Description: "Diapositivas anteriores que se pasan como contexto", Min: num(0), Max: num(20)},
+
+	"profiles":            {Description: "Perfiles por aula: nombre → campos que cambia (JSON merge patch)"},
+	"profile":             {Description: "Último perfil aplicado"},
+	"profile_base":        {Description: "Valores de los campos del perfil activo antes de aplicarlo"},
+	"schedule":            {Description: "Clases semanales: la captura se inicia y se detiene sola"},
+	"schedule[].weekday":  {Description: "Día (\"lunes\" ... \"domingo\")", Required: true},
+	"schedule[].start":    {Description: "Hora de inicio (HH:MM)", Required: true},
+	"schedule[].end":      {Description: "Hora de fin (HH:MM)", Required: true},
+	"schedule[].title":    {Description: "Título de la sesión; vacío = course_name"},
+	"schedule[].chat_ids": {Description: "Chats que reciben la sesión; vacío = todos los destinos"},
+	"schedule[].profile":  {Description: "Perfil que se aplica al iniciar; vacío = el actual"},
+	"schedule_ics":        {Description: "Calendario .ics con más clases (DAILY y WEEKLY)"},
+	"config_history_size": {Description: "Versiones anteriores de la configuración que se guardan", Min: num(1), Max: num(1000)},
+}
+
+var (
+	schemaOnce sync.Once
+	schema     map[string]any
+)
+
+// Schema devuelve el JSON Schema (draft 2020-12) de Config para que el panel
+// arme los formularios. Los tipos salen de la estructura; descripciones,
+// rangos y valores permitidos de specs, y los valores por defecto de
+// ApplyDefaults.
+func Schema() map[string]any {
+	schemaOnce.Do(func() {
+		var defaults Config
+		defaults.ApplyDefaults()
+		schema = typeSchema(reflect.TypeOf(Config{}), reflect.ValueOf(defaults), "")
+		schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+		schema["title"] = "SmartSlide"
+	})
+	return schema
+}
+
+// typeSchema arma el esquema de t; def es el valor por defecto (inválido
+// dentro de listas, donde no hay defaults).
+func typeSchema(t reflect.Type, def reflect.Value, key string) map[string]any {
+	s := map[string]any{}
+	sp := specs[key]
+	if sp.Description != "" {
+		s["description"] = sp.Description
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		props := map[string]any{}
+		var required []string
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name := jsonName(f)
+			if name == "" {
+				continue
+			}
+			var fd reflect.Value
+			if def.IsValid() {
+				fd = def.Field(i)
+			}
+			props[name] = typeSchema(f.Type, fd, joinPath(key, name))
+			if specs[joinPath(key, name)].Required {
+				required = append(required, name)
+			}
+		}
+		s["type"] = "object"
+		s["properties"] = props
+		s["additionalProperties"] = false
+		if len(required) > 0 {
+			s["required"] = required
+		}
+		return s
+	case reflect.Map:
+		s["type"] = "object"
+		s["additionalProperties"] = map[string]any{"type": "object"}
+		return s
+	case reflect.Slice:
+		s["type"] = "array"
+		s["items"] = typeSchema(t.Elem(), reflect.Value{}, key+"[]")
+		return s
+	case reflect.String:
+		s["type"] = "string"
+		if len(sp.Enum) > 0 {
+			s["enum"] = append([]string{""}, sp.Enum...)
+		}
+	case reflect.Bool:
+		s["type"] = "boolean"
+	case reflect.Int, reflect.Int64:
+		s["type"] = "integer"
+	case reflect.Float64:
+		s["type"] = "number"
+	}
+	if sp.Min != nil {
+		s["minimum"] = *sp.Min
+	}
+	if sp.Max != nil {
+		s["maximum"] = *sp.Max
+	}
+	if sp.Format != "" {
+		s["format"] = sp.Format
+	}
+	if sp.Secret {
+		s["writeOnly"] = true
+	}
+	if def.IsValid() && !def.IsZero() {
+		s["default"] = def.Interface()
+	}
+	return s
+}
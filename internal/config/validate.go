@@ -0,0 +1,207 @@
+package config
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// FieldError es un problema en un campo, identificado por su ruta JSON
+// (p. ej. "destinations[1].chat_id").
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError reúne los problemas de una configuración.
+type ValidationError []FieldError
+
+func (e ValidationError) Error() string {
+	parts := make([]string, len(e))
+	for i, f := range e {
+		parts[i] = f.Field + ": " + f.Message
+	}
+	return "config inválida: " + strings.Join(parts, "; ")
+}
+
+// add agrega un error de field; el mismo error no se repite (p. ej. un campo
+// requerido que revisan el esquema y la regla del campo).
+func (e *ValidationError) add(field, format string, args ...any) {
+	fe := FieldError{Field: field, Message: fmt.Sprintf(format, args...)}
+	if !slices.Contains(*e, fe) {
+		*e = append(*e, fe)
+	}
+}
+
+// Validate revisa rangos, valores permitidos y reglas entre campos. Los textos
+// vacíos se aceptan cuando tienen valor por defecto (ver ApplyDefaults), así
+// que sirve tanto para un archivo recién cargado como para un cambio desde el
+// panel.
+func (c Config) Validate() error {
+	var errs ValidationError
+
+	walk(reflect.ValueOf(c), "", "", func(path, key string, v reflect.Value) {
+		sp, ok := specs[key]
+		if !ok {
+			return
+		}
+		switch v.Kind() {
+		case reflect.String:
+			s := v.String()
+			if s == "" {
+				if sp.Required {
+					errs.add(path, "requerido")
+				}
+				return
+			}
+			if len(sp.Enum) > 0 && !containsFold(sp.Enum, s) {
+				errs.add(path, "debe ser uno de: %s", strings.Join(sp.Enum, ", "))
+			}
+		case reflect.Int, reflect.Int64, reflect.Float64:
+			n := v.Convert(reflect.TypeOf(float64(0))).Float()
+			if sp.Min != nil && n < *sp.Min {
+				errs.add(path, "debe ser mayor o igual a %g", *sp.Min)
+			}
+			if sp.Max != nil && n > *sp.Max {
+				errs.add(path, "debe ser menor o igual a %g", *sp.Max)
+			}
+		}
+	})
+
+	if c.ROI.X+c.ROI.W > 1 {
+		errs.add("roi.w", "x + w no puede superar 1")
+	}
+	if c.ROI.Y+c.ROI.H > 1 {
+		errs.add("roi.h", "y + h no puede superar 1")
+	}
+
+	if c.TelegramChatID == 0 && len(c.Destinations) == 0 {
+		errs.add("telegram_chat_id", "requerido si no hay destinations")
+	}
+	chats := map[int64]bool{}
+	for i, d := range c.Destinations {
+		if d.ChatID == 0 {
+			errs.add(fmt.Sprintf("destinations[%d].chat_id", i), "requerido")
+		} else if chats[d.ChatID] {
+			errs.add(fmt.Sprintf("destinations[%d].chat_id", i), "chat %d repetido", d.ChatID)
+		}
+		chats[d.ChatID] = true
+	}
+
+	checkURL(&errs, "telegram_api_endpoint", c.TelegramAPIEndpoint)
+	if c.SummarizerBackend == "llm" {
+		checkURL(&errs, "llm_endpoint", c.LLMEndpoint)
+	}
+	if c.AdminHTTPAddr != "" {
+		if _, port, err := net.SplitHostPort(c.AdminHTTPAddr); err != nil {
+			errs.add("admin_http_addr", "dirección inválida (p. ej. \":8080\")")
+		} else if p, err := strconv.Atoi(port); err != nil || p < 0 || p > 65535 {
+			errs.add("admin_http_addr", "puerto inválido")
+		}
+	}
+	for i, o := range c.AdminCORSOrigins {
+		if o == "*" {
+			continue
+		}
+		if u, err := url.Parse(o); err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") || (u.Path != "" && u.Path != "/") {
+			errs.add(fmt.Sprintf("admin_cors_origins[%d]", i), "origen inválido (p. ej. \"http://localhost:3000\" o \"*\")")
+		}
+	}
+
+	users := map[string]bool{}
+	for i, u := range c.AdminAuth.Users {
+		field := fmt.Sprintf("admin_auth.users[%d]", i)
+		if users[u.Name] {
+			errs.add(field+".name", "usuario %q repetido", u.Name)
+		}
+		users[u.Name] = true
+		if u.PasswordHash != "" && !strings.HasPrefix(u.PasswordHash, "pbkdf2-sha256$") {
+			errs.add(field+".password_hash", "no es un hash pbkdf2-sha256 (usar \"smartslide auth password\")")
+		}
+	}
+	for i, t := range c.AdminAuth.Tokens {
+		h, ok := strings.CutPrefix(t.Hash, "sha256$")
+		if _, err := hex.DecodeString(h); t.Hash != "" && (!ok || err != nil || len(h) != 64) {
+			errs.add(fmt.Sprintf("admin_auth.tokens[%d].hash", i), "no es un hash sha256 (usar \"smartslide auth token\")")
+		}
+	}
+
+	c.validateProfiles(&errs)
+	c.validateSchedule(&errs)
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func checkURL(errs *ValidationError, field, v string) {
+	if v == "" {
+		return
+	}
+	if u, err := url.Parse(v); err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		errs.add(field, "URL http(s) inválida")
+	}
+}
+
+func containsFold(list []string, s string) bool {
+	for _, x := range list {
+		if strings.EqualFold(x, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// walk recorre los campos de v con su ruta JSON (con índices, para los
+// errores) y su clave en specs (con "[]" en lugar de índices). Los slices de
+// estructuras se recorren elemento por elemento; el resto son hojas.
+func walk(v reflect.Value, path, key string, fn func(path, key string, v reflect.Value)) {
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			name := jsonName(t.Field(i))
+			if name == "" {
+				continue
+			}
+			walk(v.Field(i), joinPath(path, name), joinPath(key, name), fn)
+		}
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Struct {
+			for i := 0; i < v.Len(); i++ {
+				walk(v.Index(i), fmt.Sprintf("%s[%d]", path, i), key+"[]", fn)
+			}
+			return
+		}
+		fn(path, key, v)
+	default:
+		fn(path, key, v)
+	}
+}
+
+func jsonName(f reflect.StructField) string {
+	if !f.IsExported() {
+		return ""
+	}
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return f.Name
+	}
+	return name
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,156 @@
+// Package events es el bus de eventos del pipeline: el Runner publica cambios
+// de estado, detecciones, resultados de OCR y envíos, y el panel los recibe en
+// vivo por /events.
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+type Type string
+
+const (
+	StateChanged  Type = "state"          // StateData
+	FrameScored   Type = "frame"          // FrameData, uno por frame comparado
+	SlideDetected Type = "slide_detected" // SlideData
+	OCRDone       Type = "ocr_done"       // OCRData
+	SlideSent     Type = "slide_sent"     // SentData
+	Error         Type = "error"          // ErrorData
+)
+
+// Event es lo que se envía al panel; Data depende de Type.
+type Event struct {
+	ID      int64     `json:"id"`
+	Type    Type      `json:"type"`
+	Time    time.Time `json:"time"`
+	Session string    `json:"session,omitempty"`
+	Data    any       `json:"data"`
+}
+
+type StateData struct {
+	Status   string `json:"status"`
+	Previous string `json:"previous"`
+}
+
+// FrameData es el change score de un frame contra la última diapositiva.
+// Durante la pausa mínima entre diapositivas no se compara (Cooldown).
+type FrameData struct {
+	Score     float64 `json:"score"`
+	Threshold float64 `json:"threshold"` // sensitivity
+	Changed   bool    `json:"changed"`
+	Cooldown  bool    `json:"cooldown,omitempty"`
+}
+
+type SlideData struct {
+	Slide   string  `json:"slide"`
+	Score   float64 `json:"score"`
+	RawPath string  `json:"raw_path"`
+	Build   bool    `json:"build"` // revelación de la anterior: Slide es su ID
+}
+
+type OCRData struct {
+	Slide  string `json:"slide"`
+	Chars  int    `json:"chars"`
+	Millis int64  `json:"ms"`
+	Title  string `json:"title,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type SentData struct {
+	Slide    string           `json:"slide"`
+	Build    bool             `json:"build"` // revelación de la anterior
+	Messages map[int64]int    `json:"messages,omitempty"`
+	Errors   map[int64]string `json:"errors,omitempty"`
+	Millis   int64            `json:"ms"`
+}
+
+type ErrorData struct {
+	Message string `json:"message"`
+	Error   string `json:"error"`
+	Slide   string `json:"slide,omitempty"`
+}
+
+// subBuffer es cuántos eventos puede tener pendientes un suscriptor lento
+// antes de que se descarten.
+const subBuffer = 256
+
+type subscriber struct {
+	ch    chan Event
+	types map[Type]bool // vacío = todos
+}
+
+// Bus reparte los eventos sin bloquear al que publica y guarda los últimos
+// (salvo los frames, demasiado frecuentes) para reenviarlos al reconectar.
+type Bus struct {
+	mu      sync.Mutex
+	lastID  int64
+	subs    map[*subscriber]struct{}
+	history []Event
+	size    int
+}
+
+// NewBus guarda hasta history eventos para Since.
+func NewBus(history int) *Bus {
+	return &Bus{subs: map[*subscriber]struct{}{}, size: history}
+}
+
+// Publish asigna ID y hora al evento y lo envía a los suscriptores.
+func (b *Bus) Publish(t Type, session string, data any) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	e := Event{ID: b.lastID, Type: t, Time: time.Now(), Session: session, Data: data}
+	if t != FrameScored && b.size > 0 {
+		if len(b.history) >= b.size {
+			b.history = append(b.history[:0], b.history[1:]...)
+		}
+		b.history = append(b.history, e)
+	}
+	for s := range b.subs {
+		if len(s.types) > 0 && !s.types[t] {
+			continue
+		}
+		select {
+		case s.ch <- e:
+		default: // lector atrasado; lo ve por el salto de IDs
+		}
+	}
+	return e
+}
+
+// Subscribe devuelve un canal con los eventos de los tipos indicados (ninguno
+// = todos) y la función que lo cierra.
+func (b *Bus) Subscribe(types ...Type) (<-chan Event, func()) {
+	s := &subscriber{ch: make(chan Event, subBuffer), types: map[Type]bool{}}
+	for _, t := range types {
+		s.types[t] = true
+	}
+	b.mu.Lock()
+	b.subs[s] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return s.ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs, s)
+			b.mu.Unlock()
+			close(s.ch)
+		})
+	}
+}
+
+// Since devuelve los eventos guardados con ID mayor que id, en orden.
+func (b *Bus) Since(id int64) []Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var out []Event
+	for _, e := range b.history {
+		if e.ID > id {
+			out = append(out, e)
+		}
+	}
+	return out
+}
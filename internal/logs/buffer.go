@@ -0,0 +1,154 @@
+package logs
+
+import (
+	"strings"
+	"sync"
+)
+
+// Buffer guarda los últimos registros en un arreglo circular y los reparte a
+// los suscriptores (SSE).
+type Buffer struct {
+	mu      sync.Mutex
+	entries []Entry
+	start   int // posición del más antiguo
+	n       int
+	lastID  int64
+	subs    map[chan Entry]struct{}
+}
+
+// subBuffer es cuántos registros puede tener pendientes un suscriptor lento
+// antes de que se descarten.
+const subBuffer = 256
+
+func NewBuffer(size int) *Buffer {
+	if size <= 0 {
+		size = 1000
+	}
+	return &Buffer{
+		entries: make([]Entry, size),
+		subs:    map[chan Entry]struct{}{},
+	}
+}
+
+// Add asigna el ID, guarda e (reemplazando el más antiguo si está lleno) y lo
+// envía a los suscriptores sin bloquear.
+func (b *Buffer) Add(e Entry) Entry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	e.ID = b.lastID
+	size := len(b.entries)
+	if b.n < size {
+		b.entries[(b.start+b.n)%size] = e
+		b.n++
+	} else {
+		b.entries[b.start] = e
+		b.start = (b.start + 1) % size
+	}
+
+	for ch := range b.subs {
+		select {
+		case ch <- e:
+		default: // lector atrasado; lo ve por el salto de IDs
+		}
+	}
+	return e
+}
+
+// Query filtra Entries; los campos vacíos no filtran.
+type Query struct {
+	Level  string // nivel mínimo
+	Source string
+	Text   string // contenido en el mensaje, sin distinguir mayúsculas
+	Before int64  // solo IDs menores (página siguiente)
+	After  int64  // solo IDs mayores (lo nuevo desde la última consulta)
+	Limit  int
+}
+
+// Matches indica si e cumple q sin mirar Before, After ni Limit.
+func (q Query) Matches(e Entry) bool {
+	if q.Level != "" && levelRank(e.Level) < levelRank(q.Level) {
+		return false
+	}
+	if q.Source != "" && !strings.EqualFold(e.Source, q.Source) {
+		return false
+	}
+	if q.Text != "" && !strings.Contains(strings.ToLower(e.Message), strings.ToLower(q.Text)) {
+		return false
+	}
+	return true
+}
+
+func levelRank(name string) int {
+	switch strings.ToUpper(name) {
+	case "ERROR":
+		return 3
+	case "WARN", "WARNING":
+		return 2
+	case "INFO":
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Entries devuelve los registros que cumplen q, el más reciente primero.
+func (b *Buffer) Entries(q Query) []Entry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	out := []Entry{}
+	size := len(b.entries)
+	for i := b.n - 1; i >= 0; i-- {
+		e := b.entries[(b.start+i)%size]
+		if q.Before > 0 && e.ID >= q.Before {
+			continue
+		}
+		if e.ID <= q.After {
+			break
+		}
+		if !q.Matches(e) {
+			continue
+		}
+		out = append(out, e)
+		if q.Limit > 0 && len(out) >= q.Limit {
+			break
+		}
+	}
+	return out
+}
+
+// Len es la cantidad de registros guardados.
+func (b *Buffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.n
+}
+
+// Clear vacía el buffer; los IDs siguen creciendo.
+func (b *Buffer) Clear() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.start, b.n = 0, 0
+	clear(b.entries)
+}
+
+// Subscribe devuelve un canal con cada registro nuevo y la función que lo
+// cierra. Si el lector se atrasa, los registros se descartan.
+func (b *Buffer) Subscribe() (<-chan Entry, func()) {
+	ch := make(chan Entry, subBuffer)
+	b.mu.Lock()
+	b.subs[ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs, ch)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+}
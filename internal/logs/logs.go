@@ -0,0 +1,166 @@
+// Package logs configura log/slog: cada registro se escribe en stderr y se
+// guarda en un buffer circular en memoria que el panel consulta (/logs) o sigue
+// en vivo (/logs/stream).
+package logs
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"time"
+)
+
+// ComponentKey es el atributo con el componente que originó el registro
+// (app, capture, ocr, telegram, admin, main); en Entry es Source.
+const ComponentKey = "component"
+
+// Entry es un registro tal como lo muestra LogsPanel.
+type Entry struct {
+	ID        int64             `json:"id"`
+	Timestamp time.Time         `json:"timestamp"`
+	Level     string            `json:"level"` // DEBUG, INFO, WARN o ERROR
+	Message   string            `json:"message"`
+	Source    string            `json:"source"`
+	Attrs     map[string]string `json:"attrs,omitempty"`
+}
+
+// Setup instala como logger por defecto uno que escribe en w desde level y
+// guarda en un buffer de size registros, que devuelve. Los log.Printf que
+// queden (p. ej. de dependencias) pasan también por él.
+func Setup(w io.Writer, level slog.Level, size int) *Buffer {
+	buf := NewBuffer(size)
+	minLevel.Set(level)
+	slog.SetDefault(slog.New(NewHandler(w, minLevel, buf)))
+	return buf
+}
+
+// minLevel es el nivel del handler de Setup; SetLevel lo cambia en caliente.
+var minLevel = new(slog.LevelVar)
+
+// SetLevel cambia el nivel mínimo de los logs configurados con Setup.
+func SetLevel(level slog.Level) {
+	minLevel.Set(level)
+}
+
+// For devuelve el logger por defecto con el componente indicado.
+func For(component string) *slog.Logger {
+	return slog.Default().With(ComponentKey, component)
+}
+
+// ParseLevel acepta debug, info, warn o error (vacío = info).
+func ParseLevel(s string) (slog.Level, error) {
+	var l slog.Level
+	if s == "" {
+		return slog.LevelInfo, nil
+	}
+	err := l.UnmarshalText([]byte(s))
+	return l, err
+}
+
+// LevelName redondea los niveles intermedios (p. ej. INFO+2) hacia abajo.
+func LevelName(l slog.Level) string {
+	switch {
+	case l >= slog.LevelError:
+		return "ERROR"
+	case l >= slog.LevelWarn:
+		return "WARN"
+	case l >= slog.LevelInfo:
+		return "INFO"
+	default:
+		return "DEBUG"
+	}
+}
+
+// Handler pasa cada registro a un TextHandler y lo guarda en el buffer.
+type Handler struct {
+	next  slog.Handler
+	level slog.Leveler
+	buf   *Buffer
+
+	attrs  []slog.Attr // de WithAttrs, con el prefijo de grupo ya aplicado
+	prefix string      // grupos abiertos, "a.b."
+}
+
+func NewHandler(w io.Writer, level slog.Leveler, buf *Buffer) *Handler {
+	return &Handler{
+		next:  slog.NewTextHandler(w, &slog.HandlerOptions{Level: level}),
+		level: level,
+		buf:   buf,
+	}
+}
+
+func (h *Handler) Enabled(_ context.Context, l slog.Level) bool {
+	return l >= h.level.Level()
+}
+
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	e := Entry{
+		Timestamp: r.Time,
+		Level:     LevelName(r.Level),
+		Message:   r.Message,
+	}
+	add := func(a slog.Attr) {
+		if a.Key == ComponentKey && e.Source == "" {
+			e.Source = a.Value.String()
+			return
+		}
+		if e.Attrs == nil {
+			e.Attrs = map[string]string{}
+		}
+		e.Attrs[a.Key] = a.Value.String()
+	}
+	for _, a := range h.attrs {
+		add(a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		for _, a := range flatten(h.prefix, a) {
+			add(a)
+		}
+		return true
+	})
+	if e.Timestamp.IsZero() {
+		e.Timestamp = time.Now()
+	}
+	h.buf.Add(e)
+	return h.next.Handle(ctx, r)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.next = h.next.WithAttrs(attrs)
+	h2.attrs = append([]slog.Attr(nil), h.attrs...)
+	for _, a := range attrs {
+		h2.attrs = append(h2.attrs, flatten(h.prefix, a)...)
+	}
+	return &h2
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.next = h.next.WithGroup(name)
+	h2.prefix = h.prefix + name + "."
+	return &h2
+}
+
+// flatten expande los grupos a claves "grupo.clave".
+func flatten(prefix string, a slog.Attr) []slog.Attr {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() != slog.KindGroup {
+		if a.Key == "" {
+			return nil
+		}
+		a.Key = prefix + a.Key
+		return []slog.Attr{a}
+	}
+	if a.Key != "" {
+		prefix += a.Key + "."
+	}
+	var out []slog.Attr
+	for _, g := range a.Value.Group() {
+		out = append(out, flatten(prefix, g)...)
+	}
+	return out
+}
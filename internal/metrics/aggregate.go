@@ -0,0 +1,160 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Summary son los agregados de un conjunto de registros. Los primeros campos
+// son los que muestra MetricsPanel.
+type Summary struct {
+	OCRAccuracy         float64 `json:"ocr_accuracy"`          // % de capturas con OCR correcto y texto
+	ProcessingTime      float64 `json:"processing_time"`       // segundos, promedio por diapositiva
+	TelegramSuccessRate float64 `json:"telegram_success_rate"` // % de envíos correctos
+	AvgChangeScore      float64 `json:"avg_change_score"`
+	TotalSlides         int     `json:"total_slides"` // sin contar revelaciones incrementales
+	LastHourSlides      int     `json:"last_hour_slides"`
+
+	Records       int         `json:"records"`
+	From          time.Time   `json:"from"`
+	To            time.Time   `json:"to"`
+	OCRMillis     Percentiles `json:"ocr_ms"`
+	SendMillis    Percentiles `json:"send_ms"`
+	TotalMillis   Percentiles `json:"total_ms"`
+	SlidesPerHour float64     `json:"slides_per_hour"` // promedio entre la primera y la última
+	Hourly        []HourCount `json:"hourly"`
+	ChangeScores  []Bin       `json:"change_score_histogram"`
+}
+
+type Percentiles struct {
+	Avg float64 `json:"avg"`
+	P50 float64 `json:"p50"`
+	P95 float64 `json:"p95"`
+	Max float64 `json:"max"`
+}
+
+// HourCount es la cantidad de diapositivas en una hora.
+type HourCount struct {
+	Hour   time.Time `json:"hour"`
+	Slides int       `json:"slides"`
+}
+
+// Bin es un intervalo [From, To) del histograma de change_score.
+type Bin struct {
+	From  float64 `json:"from"`
+	To    float64 `json:"to"`
+	Count int     `json:"count"`
+}
+
+// histogramBins es la cantidad de intervalos del histograma de change_score.
+const histogramBins = 10
+
+// Aggregate calcula los agregados de records (en orden cronológico). now se
+// usa para last_hour_slides.
+func Aggregate(records []Record, now time.Time) Summary {
+	s := Summary{
+		Records:      len(records),
+		Hourly:       []HourCount{},
+		ChangeScores: make([]Bin, histogramBins),
+	}
+	for i := range s.ChangeScores {
+		s.ChangeScores[i] = Bin{From: float64(i) / histogramBins, To: float64(i+1) / histogramBins}
+	}
+	if len(records) == 0 {
+		return s
+	}
+
+	var ocrOK, sendOK int
+	var score float64
+	var ocrMs, sendMs, totalMs []float64
+	hourly := map[time.Time]int{}
+
+	for _, r := range records {
+		t, err := time.Parse(time.RFC3339, r.TimeISO)
+		if err == nil {
+			if s.From.IsZero() || t.Before(s.From) {
+				s.From = t
+			}
+			if t.After(s.To) {
+				s.To = t
+			}
+		}
+
+		if r.OCROK && r.TextChars > 0 {
+			ocrOK++
+		}
+		if r.SendOK {
+			sendOK++
+		}
+		ocrMs = append(ocrMs, float64(r.OCRMillis))
+		sendMs = append(sendMs, float64(r.SendMillis))
+		totalMs = append(totalMs, float64(r.TotalMillis))
+
+		if r.Build {
+			continue
+		}
+		s.TotalSlides++
+		score += r.ChangeScore
+		bin := int(r.ChangeScore * histogramBins)
+		s.ChangeScores[min(max(bin, 0), histogramBins-1)].Count++
+		if err == nil {
+			hourly[t.Truncate(time.Hour)]++
+			if now.Sub(t) <= time.Hour {
+				s.LastHourSlides++
+			}
+		}
+	}
+
+	n := float64(len(records))
+	s.OCRAccuracy = round(100*float64(ocrOK)/n, 1)
+	s.TelegramSuccessRate = round(100*float64(sendOK)/n, 1)
+	if s.TotalSlides > 0 {
+		s.AvgChangeScore = round(score/float64(s.TotalSlides), 3)
+	}
+	s.OCRMillis = percentiles(ocrMs)
+	s.SendMillis = percentiles(sendMs)
+	s.TotalMillis = percentiles(totalMs)
+	s.ProcessingTime = round(s.TotalMillis.Avg/1000, 2)
+
+	if hours := s.To.Sub(s.From).Hours(); hours >= 1 {
+		s.SlidesPerHour = round(float64(s.TotalSlides)/hours, 1)
+	} else {
+		s.SlidesPerHour = float64(s.TotalSlides)
+	}
+
+	for h, c := range hourly {
+		s.Hourly = append(s.Hourly, HourCount{Hour: h, Slides: c})
+	}
+	sort.Slice(s.Hourly, func(i, j int) bool { return s.Hourly[i].Hour.Before(s.Hourly[j].Hour) })
+	return s
+}
+
+// percentiles usa el método del rango más cercano.
+func percentiles(v []float64) Percentiles {
+	if len(v) == 0 {
+		return Percentiles{}
+	}
+	sorted := append([]float64(nil), v...)
+	sort.Float64s(sorted)
+
+	var sum float64
+	for _, x := range sorted {
+		sum += x
+	}
+	rank := func(p float64) float64 {
+		i := int(math.Ceil(p*float64(len(sorted)))) - 1
+		return sorted[min(max(i, 0), len(sorted)-1)]
+	}
+	return Percentiles{
+		Avg: round(sum/float64(len(sorted)), 1),
+		P50: rank(0.50),
+		P95: rank(0.95),
+		Max: sorted[len(sorted)-1],
+	}
+}
+
+func round(x float64, decimals int) float64 {
+	p := math.Pow(10, float64(decimals))
+	return math.Round(x*p) / p
+}
@@ -1,47 +1,24 @@
 package metrics
 
-import (
-	"encoding/json"
-	"os"
-	"sync"
-)
-
+// Record es la métrica de una diapositiva capturada. Es también el formato de
+// las líneas de metrics.jsonl de versiones anteriores (ver
+// store.ImportMetricsFile).
 type Record struct {
-	TimeISO      string  `json:"time_iso"`
-	SlidePath    string  `json:"slide_path"`
-	RawPath      string  `json:"raw_path"`
-	ChangeScore  float64 `json:"change_score"`
-	OCRMillis    int64   `json:"ocr_ms"`
-	TotalMillis  int64   `json:"total_ms"`
-	TextChars    int     `json:"text_chars"`
-	CaptionChars int     `json:"caption_chars"`
-	SendOK       bool    `json:"send_ok"`
-	OCROK        bool    `json:"ocr_ok"`
-	Error        string  `json:"error,omitempty"`
-}
-
-type Writer struct {
-	mu sync.Mutex
-	f  *os.File
-}
-
-func New(path string) (*Writer, error) {
-	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
-	if err != nil {
-		return nil, err
-	}
-	return &Writer{f: f}, nil
-}
-
-func (w *Writer) Close() error {
-	w.mu.Lock()
-	defer w.mu.Unlock()
-	return w.f.Close()
-}
-
-func (w *Writer) Write(r Record) {
-	w.mu.Lock()
-	defer w.mu.Unlock()
-	b, _ := json.Marshal(r)
-	_, _ = w.f.Write(append(b, '\n'))
+	TimeISO      string        `json:"time_iso"`
+	Course       string        `json:"course,omitempty"`
+	Session      string        `json:"session,omitempty"`
+	SlidePath    string        `json:"slide_path"`
+	RawPath      string        `json:"raw_path"`
+	ChangeScore  float64       `json:"change_score"`
+	OCRMillis    int64         `json:"ocr_ms"`
+	TotalMillis  int64         `json:"total_ms"`
+	SendMillis   int64         `json:"send_ms"`
+	TextChars    int           `json:"text_chars"`
+	CaptionChars int           `json:"caption_chars"`
+	SendOK       bool          `json:"send_ok"`
+	MessageIDs   map[int64]int `json:"message_ids,omitempty"` // por chat; falta si quedó pendiente en un álbum
+	OCROK        bool          `json:"ocr_ok"`
+	Regions      []string      `json:"regions,omitempty"` // code, math, table
+	Build        bool          `json:"build,omitempty"`   // revelación incremental de la diapositiva anterior
+	Error        string        `json:"error,omitempty"`
 }
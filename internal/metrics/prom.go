@@ -0,0 +1,246 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Contadores, gauges e histogramas en memoria, exportados en el formato de
+// texto de Prometheus por WritePrometheus. Se registran al declararse.
+
+type collector interface {
+	write(w io.Writer)
+}
+
+var (
+	registryMu sync.Mutex
+	registry   []collector
+	startTime  = time.Now()
+)
+
+func register(c collector) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry = append(registry, c)
+}
+
+// WritePrometheus escribe todas las métricas registradas y las del proceso.
+func WritePrometheus(w io.Writer) {
+	registryMu.Lock()
+	cs := append([]collector(nil), registry...)
+	registryMu.Unlock()
+
+	for _, c := range cs {
+		c.write(w)
+	}
+	writeProcess(w)
+}
+
+// atomicFloat es un float64 con suma atómica.
+type atomicFloat struct {
+	bits atomic.Uint64
+}
+
+func (f *atomicFloat) Add(v float64) {
+	for {
+		old := f.bits.Load()
+		if f.bits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+v)) {
+			return
+		}
+	}
+}
+
+func (f *atomicFloat) Set(v float64) { f.bits.Store(math.Float64bits(v)) }
+
+func (f *atomicFloat) Load() float64 { return math.Float64frombits(f.bits.Load()) }
+
+// ---- counter ----
+
+type Counter struct {
+	name, help string
+	v          atomicFloat
+}
+
+func NewCounter(name, help string) *Counter {
+	c := &Counter{name: name, help: help}
+	register(c)
+	return c
+}
+
+func (c *Counter) Inc()          { c.v.Add(1) }
+func (c *Counter) Add(v float64) { c.v.Add(v) }
+
+func (c *Counter) write(w io.Writer) {
+	writeHeader(w, c.name, c.help, "counter")
+	fmt.Fprintf(w, "%s %s\n", c.name, formatFloat(c.v.Load()))
+}
+
+// CounterVec es un contador con una etiqueta (p. ej. method).
+type CounterVec struct {
+	name, help, label string
+
+	mu     sync.Mutex
+	values map[string]*atomicFloat
+}
+
+func NewCounterVec(name, help, label string) *CounterVec {
+	c := &CounterVec{name: name, help: help, label: label, values: map[string]*atomicFloat{}}
+	register(c)
+	return c
+}
+
+func (c *CounterVec) Inc(value string) {
+	c.mu.Lock()
+	v := c.values[value]
+	if v == nil {
+		v = &atomicFloat{}
+		c.values[value] = v
+	}
+	c.mu.Unlock()
+	v.Add(1)
+}
+
+func (c *CounterVec) write(w io.Writer) {
+	writeHeader(w, c.name, c.help, "counter")
+	c.mu.Lock()
+	keys := make([]string, 0, len(c.values))
+	for k := range c.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(w, "%s{%s=\"%s\"} %s\n", c.name, c.label, escapeLabel(k), formatFloat(c.values[k].Load()))
+	}
+	c.mu.Unlock()
+}
+
+// ---- gauge ----
+
+type Gauge struct {
+	name, help string
+	v          atomicFloat
+}
+
+func NewGauge(name, help string) *Gauge {
+	g := &Gauge{name: name, help: help}
+	register(g)
+	return g
+}
+
+func (g *Gauge) Set(v float64) { g.v.Set(v) }
+func (g *Gauge) Add(v float64) { g.v.Add(v) }
+
+func (g *Gauge) write(w io.Writer) {
+	writeHeader(w, g.name, g.help, "gauge")
+	fmt.Fprintf(w, "%s %s\n", g.name, formatFloat(g.v.Load()))
+}
+
+// ---- histogram ----
+
+type Histogram struct {
+	name, help string
+	buckets    []float64 // límites superiores, crecientes
+	counts     []atomic.Uint64
+	sum        atomicFloat
+	count      atomic.Uint64
+}
+
+// DurationBuckets sirven para duraciones en segundos, de 10 ms a 1 min.
+var DurationBuckets = []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+func NewHistogram(name, help string, buckets []float64) *Histogram {
+	h := &Histogram{name: name, help: help, buckets: buckets, counts: make([]atomic.Uint64, len(buckets))}
+	register(h)
+	return h
+}
+
+func (h *Histogram) Observe(v float64) {
+	for i, b := range h.buckets {
+		if v <= b {
+			h.counts[i].Add(1)
+		}
+	}
+	h.sum.Add(v)
+	h.count.Add(1)
+}
+
+// ObserveSince registra el tiempo transcurrido desde start, en segundos.
+func (h *Histogram) ObserveSince(start time.Time) {
+	h.Observe(time.Since(start).Seconds())
+}
+
+func (h *Histogram) write(w io.Writer) {
+	writeHeader(w, h.name, h.help, "histogram")
+	for i, b := range h.buckets {
+		fmt.Fprintf(w, "%s_bucket{le=\"%s\"} %d\n", h.name, formatFloat(b), h.counts[i].Load())
+	}
+	fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n", h.name, h.count.Load())
+	fmt.Fprintf(w, "%s_sum %s\n", h.name, formatFloat(h.sum.Load()))
+	fmt.Fprintf(w, "%s_count %d\n", h.name, h.count.Load())
+}
+
+// ---- proceso ----
+
+func writeProcess(w io.Writer) {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+
+	writeHeader(w, "go_goroutines", "Goroutines en ejecución.", "gauge")
+	fmt.Fprintf(w, "go_goroutines %d\n", runtime.NumGoroutine())
+	writeHeader(w, "go_memstats_alloc_bytes", "Bytes del heap en uso.", "gauge")
+	fmt.Fprintf(w, "go_memstats_alloc_bytes %d\n", m.Alloc)
+	writeHeader(w, "go_memstats_sys_bytes", "Bytes obtenidos del sistema por el runtime de Go.", "gauge")
+	fmt.Fprintf(w, "go_memstats_sys_bytes %d\n", m.Sys)
+	writeHeader(w, "go_gc_cycles_total", "Ciclos de GC completados.", "counter")
+	fmt.Fprintf(w, "go_gc_cycles_total %d\n", m.NumGC)
+
+	// incluye la memoria de OpenCV y Tesseract, que el runtime de Go no ve
+	if rss, ok := residentMemory(); ok {
+		writeHeader(w, "process_resident_memory_bytes", "Memoria residente del proceso.", "gauge")
+		fmt.Fprintf(w, "process_resident_memory_bytes %d\n", rss)
+	}
+	writeHeader(w, "process_start_time_seconds", "Inicio del proceso (Unix).", "gauge")
+	fmt.Fprintf(w, "process_start_time_seconds %d\n", startTime.Unix())
+}
+
+// residentMemory lee /proc/self/statm (solo Linux).
+func residentMemory() (int64, bool) {
+	b, err := os.ReadFile("/proc/self/statm")
+	if err != nil {
+		return 0, false
+	}
+	fields := strings.Fields(string(b))
+	if len(fields) < 2 {
+		return 0, false
+	}
+	pages, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return pages * int64(os.Getpagesize()), true
+}
+
+// ---- formato ----
+
+func writeHeader(w io.Writer, name, help, typ string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
@@ -0,0 +1,26 @@
+package metrics
+
+// Métricas de ejecución que exporta /metrics/prometheus.
+var (
+	// capture
+	FramesRead       = NewCounter("smartslide_frames_read_total", "Frames leídos de la cámara.")
+	FramesDropped    = NewCounter("smartslide_frames_dropped_total", "Lecturas de la cámara fallidas o vacías.")
+	CameraReconnects = NewCounter("smartslide_camera_reconnects_total", "Veces que se reabrió la cámara.")
+	Detections       = NewCounter("smartslide_slide_detections_total", "Cambios de diapositiva detectados.")
+	ChangeScore      = NewGauge("smartslide_change_score", "Último change score calculado (0..1).")
+
+	// ocr
+	OCRDuration = NewHistogram("smartslide_ocr_duration_seconds", "Duración del OCR por diapositiva.", DurationBuckets)
+	OCRFailures = NewCounter("smartslide_ocr_failures_total", "Errores de OCR.")
+
+	// telegram
+	SendDuration = NewHistogram("smartslide_telegram_send_duration_seconds", "Duración de cada petición a la Bot API, incluidos reintentos.", DurationBuckets)
+	SendFailures = NewCounterVec("smartslide_telegram_send_failures_total", "Peticiones a la Bot API fallidas tras los reintentos.", "method")
+	SendRetries  = NewCounter("smartslide_telegram_retries_total", "Reintentos por 429 o 5xx.")
+
+	// app
+	SlidesSent = NewCounter("smartslide_slides_total", "Diapositivas nuevas procesadas.")
+	Builds     = NewCounter("smartslide_builds_total", "Revelaciones incrementales aplicadas a la diapositiva anterior.")
+	QueueDepth = NewGauge("smartslide_queue_depth", "Diapositivas esperando en álbumes pendientes.")
+	Processing = NewHistogram("smartslide_processing_duration_seconds", "Tiempo total desde la detección hasta el envío.", DurationBuckets)
+)
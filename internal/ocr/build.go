@@ -0,0 +1,70 @@
+package ocr
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// DetectBuild indica si cur es una revelación incremental de prev (mismo
+// título y todas las líneas de prev siguen presentes) y devuelve las líneas
+// nuevas de cur.
+func DetectBuild(prev, cur Summary) ([]string, bool) {
+	prevLines := buildLines(prev.RawText)
+	curLines := buildLines(cur.RawText)
+	if len(prevLines) == 0 || len(curLines) <= len(prevLines) {
+		return nil, false
+	}
+
+	// el título se toma de la primera línea del OCR: el de Summary puede
+	// variar entre llamadas (p. ej. con el resumidor LLM)
+	if !similarLine(prevLines[0], curLines[0]) {
+		return nil, false
+	}
+
+	matched := make([]bool, len(curLines))
+	found := 0
+	for _, p := range prevLines {
+		for j, c := range curLines {
+			if !matched[j] && similarLine(p, c) {
+				matched[j] = true
+				found++
+				break
+			}
+		}
+	}
+	// se tolera alguna línea mal leída por el OCR
+	if float64(found)/float64(len(prevLines)) < 0.8 {
+		return nil, false
+	}
+
+	var added []string
+	for j, c := range curLines {
+		if !matched[j] {
+			added = append(added, c)
+		}
+	}
+	if len(added) == 0 {
+		return nil, false
+	}
+	return added, true
+}
+
+func buildLines(text string) []string {
+	var out []string
+	for _, l := range splitLines(text) {
+		if !isNoiseLine(l) {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+func similarLine(a, b string) bool {
+	a, b = fold(strings.TrimSpace(a)), fold(strings.TrimSpace(b))
+	if a == b {
+		return true
+	}
+	n := max(utf8.RuneCountInString(a), utf8.RuneCountInString(b))
+	limit := max(2, n/10)
+	return editDistance(a, b, limit) <= limit
+}
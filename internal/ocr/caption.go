@@ -0,0 +1,335 @@
+package ocr
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+)
+
+// Límites de Telegram, en caracteres.
+const (
+	TelegramCaptionLimit = 1024
+	TelegramMessageLimit = 4096
+)
+
+// Formatos de caption; coinciden con el parse_mode de Telegram salvo plain.
+const (
+	FormatPlain      = "plain"
+	FormatMarkdownV2 = "markdownv2"
+	FormatHTML       = "html"
+)
+
+// DefaultCaptionTemplate reproduce el caption clásico. Los campos de texto ya
+// vienen escapados para el formato; b, i, code y pre agregan el marcado.
+const DefaultCaptionTemplate = `{{if .Title}}{{b .L.Title}}: {{.Title}}
+{{end}}{{if .Bullets}}
+{{b .L.Points}}:
+{{range .Bullets}}• {{.}}
+{{end}}{{end}}{{range .Regions}}{{if eq .Kind "code"}}
+{{b $.L.Code}}:
+{{pre .Raw}}
+{{else if eq .Kind "math"}}
+{{b $.L.Formula}}:
+{{code .Raw}}
+{{else if eq .Kind "table"}}
+{{.Text}}
+{{end}}{{end}}{{if .Keywords}}
+{{b .L.Keywords}}: {{join .Keywords ", "}}
+{{end}}
+{{b .L.Change}}: {{.Change}}`
+
+// CaptionData son los campos disponibles en las plantillas de caption. Los
+// textos se escapan según el formato antes de ejecutar la plantilla.
+type CaptionData struct {
+	Title       string
+	Bullets     []string
+	Keywords    []string
+	Regions     []CaptionRegion
+	ChangeScore float64 // porcentaje (0..100)
+	Change      string  // ChangeScore con formato, p. ej. "12.5%"
+	Course      string
+	Session     string
+	Time        time.Time
+	L           captionLabels
+}
+
+// CaptionRegion es una región de código, fórmula o tabla. Text está escapado
+// (en tablas es la etiqueta con el número de filas); Raw es el texto original,
+// para usar con code o pre.
+type CaptionRegion struct {
+	Kind string
+	Text string
+	Raw  string
+	Rows int
+}
+
+func NewCaptionData(s Summary, changeScore float64) CaptionData {
+	d := CaptionData{
+		Title:       s.Title,
+		Bullets:     s.Bullets,
+		Keywords:    s.Keywords,
+		ChangeScore: changeScore * 100,
+		Change:      fmt.Sprintf("%.1f%%", changeScore*100),
+		Time:        time.Now(),
+	}
+	for _, r := range s.Regions {
+		d.Regions = append(d.Regions, CaptionRegion{Kind: string(r.Kind), Text: r.Text, Raw: r.Text, Rows: len(r.Rows)})
+	}
+	return d
+}
+
+// CaptionOptions controla cómo se genera un caption.
+type CaptionOptions struct {
+	Template *template.Template // nil = DefaultCaptionTemplate
+	Format   string             // plain (defecto), markdownv2 o html
+	Lang     string             // "es" (defecto) o "en"
+	MaxChars int                // se limita a TelegramCaptionLimit
+}
+
+// Caption es el resultado: Text cabe en el caption y Overflow es lo que quedó
+// fuera (bullets descartados o el resto del texto), en el mismo formato.
+type Caption struct {
+	Text      string
+	Overflow  string
+	ParseMode string // "MarkdownV2", "HTML" o "" (texto plano)
+}
+
+// ParseCaptionTemplate compila una plantilla text/template para captions.
+func ParseCaptionTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(formatFuncs(FormatPlain)).Parse(text)
+}
+
+var defaultCaptionTemplate = template.Must(ParseCaptionTemplate("default", DefaultCaptionTemplate))
+
+// RenderCaption ejecuta la plantilla escapando los datos según el formato. Si
+// el resultado excede el límite se descartan bullets desde el final; si aun así
+// no cabe, se recorta el texto plano por runas.
+func RenderCaption(data CaptionData, opts CaptionOptions) (Caption, error) {
+	t := opts.Template
+	if t == nil {
+		t = defaultCaptionTemplate
+	}
+	format := normalizeFormat(opts.Format)
+	limit := opts.MaxChars
+	if limit <= 0 || limit > TelegramCaptionLimit {
+		limit = TelegramCaptionLimit
+	}
+	data.L = labelsFor(opts.Lang)
+
+	bullets := data.Bullets
+	for n := len(bullets); n >= 0; n-- {
+		d := data
+		d.Bullets = bullets[:n]
+		out, err := execCaption(t, escapeData(d, format), format)
+		if err != nil {
+			return Caption{}, err
+		}
+		if utf8.RuneCountInString(out) <= limit {
+			c := Caption{Text: out, ParseMode: parseMode(format)}
+			if n < len(bullets) {
+				c.Overflow = overflowBullets(bullets[n:], format)
+			}
+			return c, nil
+		}
+	}
+
+	// ni sin bullets cabe: texto plano recortado (sin riesgo de cortar marcado)
+	full, err := execCaption(t, escapeData(data, FormatPlain), FormatPlain)
+	if err != nil {
+		return Caption{}, err
+	}
+	text, rest := TruncateRunes(full, limit)
+	return Caption{Text: text, Overflow: rest}, nil
+}
+
+func execCaption(t *template.Template, data CaptionData, format string) (string, error) {
+	t, err := t.Clone()
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := t.Funcs(formatFuncs(format)).Execute(&b, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// TruncateRunes corta s en a lo sumo max runas, preferentemente en un salto de
+// línea o espacio, y agrega "…". Devuelve también el resto.
+func TruncateRunes(s string, max int) (string, string) {
+	if utf8.RuneCountInString(s) <= max {
+		return s, ""
+	}
+	if max <= 1 {
+		return "…", s
+	}
+	r := []rune(s)
+	cut := max - 1
+	for i := cut; i > cut/2; i-- {
+		if r[i] == '\n' || r[i] == ' ' {
+			cut = i
+			break
+		}
+	}
+	return strings.TrimRight(string(r[:cut]), " \n") + "…", strings.TrimLeft(string(r[cut:]), " \n")
+}
+
+// SplitMessage divide un texto largo en partes que caben en un mensaje.
+func SplitMessage(s string) []string {
+	var parts []string
+	for s != "" {
+		if utf8.RuneCountInString(s) <= TelegramMessageLimit {
+			parts = append(parts, s)
+			break
+		}
+		r := []rune(s)
+		cut := TelegramMessageLimit
+		for i := cut - 1; i > cut/2; i-- {
+			if r[i] == '\n' {
+				cut = i
+				break
+			}
+		}
+		parts = append(parts, string(r[:cut]))
+		s = strings.TrimLeft(string(r[cut:]), "\n")
+	}
+	return parts
+}
+
+func overflowBullets(bullets []string, format string) string {
+	var b strings.Builder
+	for _, x := range bullets {
+		b.WriteString("• ")
+		b.WriteString(escapeText(x, format))
+		b.WriteString("\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func normalizeFormat(f string) string {
+	switch strings.ToLower(f) {
+	case FormatMarkdownV2, "markdown":
+		return FormatMarkdownV2
+	case FormatHTML:
+		return FormatHTML
+	}
+	return FormatPlain
+}
+
+func parseMode(format string) string {
+	switch format {
+	case FormatMarkdownV2:
+		return "MarkdownV2"
+	case FormatHTML:
+		return "HTML"
+	}
+	return ""
+}
+
+func escapeData(d CaptionData, format string) CaptionData {
+	out := d
+	out.Title = escapeText(d.Title, format)
+	out.Change = escapeText(d.Change, format)
+	out.Course = escapeText(d.Course, format)
+	out.Session = escapeText(d.Session, format)
+	out.Bullets = escapeAll(d.Bullets, format)
+	out.Keywords = escapeAll(d.Keywords, format)
+
+	out.Regions = make([]CaptionRegion, len(d.Regions))
+	for i, r := range d.Regions {
+		text := r.Text
+		if r.Kind == string(RegionTable) {
+			text = fmt.Sprintf(d.L.Table, r.Rows)
+		}
+		out.Regions[i] = CaptionRegion{Kind: r.Kind, Text: escapeText(text, format), Raw: r.Raw, Rows: r.Rows}
+	}
+
+	out.L = captionLabels{
+		Title:    escapeText(d.L.Title, format),
+		Points:   escapeText(d.L.Points, format),
+		Code:     escapeText(d.L.Code, format),
+		Formula:  escapeText(d.L.Formula, format),
+		Table:    d.L.Table,
+		Keywords: escapeText(d.L.Keywords, format),
+		Change:   escapeText(d.L.Change, format),
+	}
+	return out
+}
+
+func escapeAll(in []string, format string) []string {
+	out := make([]string, len(in))
+	for i, s := range in {
+		out[i] = escapeText(s, format)
+	}
+	return out
+}
+
+var (
+	mdV2Escaper   = strings.NewReplacer(mdV2Pairs("\\_*[]()~`>#+-=|{}.!")...)
+	mdV2CodeEsc   = strings.NewReplacer("\\", "\\\\", "`", "\\`")
+	plainIdentity = func(s string) string { return s }
+)
+
+func mdV2Pairs(chars string) []string {
+	var pairs []string
+	for _, c := range chars {
+		pairs = append(pairs, string(c), "\\"+string(c))
+	}
+	return pairs
+}
+
+func escapeText(s, format string) string {
+	switch format {
+	case FormatMarkdownV2:
+		return mdV2Escaper.Replace(s)
+	case FormatHTML:
+		return html.EscapeString(s)
+	}
+	return s
+}
+
+// formatFuncs devuelve las funciones de marcado para el formato. Los
+// argumentos de b e i ya están escapados; los de code y pre son texto crudo.
+func formatFuncs(format string) template.FuncMap {
+	esc := func(s string) string { return escapeText(s, format) }
+	fm := template.FuncMap{
+		"join": strings.Join,
+		"esc":  esc,
+		"b":    plainIdentity,
+		"i":    plainIdentity,
+		"code": plainIdentity,
+		"pre":  plainIdentity,
+	}
+	switch format {
+	case FormatMarkdownV2:
+		fm["b"] = func(s string) string { return "*" + s + "*" }
+		fm["i"] = func(s string) string { return "_" + s + "_" }
+		fm["code"] = func(s string) string { return "`" + mdV2CodeEsc.Replace(s) + "`" }
+		fm["pre"] = func(s string) string { return "```\n" + mdV2CodeEsc.Replace(s) + "\n```" }
+	case FormatHTML:
+		fm["b"] = func(s string) string { return "<b>" + s + "</b>" }
+		fm["i"] = func(s string) string { return "<i>" + s + "</i>" }
+		fm["code"] = func(s string) string { return "<code>" + html.EscapeString(s) + "</code>" }
+		fm["pre"] = func(s string) string { return "<pre>" + html.EscapeString(s) + "</pre>" }
+	}
+	return fm
+}
+
+type captionLabels struct {
+	Title, Points, Code, Formula, Table, Keywords, Change string
+}
+
+var labelsByLang = map[string]captionLabels{
+	"es": {"Título", "Puntos", "Código", "Fórmula", "Tabla: %d filas", "Palabras clave", "Cambio"},
+	"en": {"Title", "Key points", "Code", "Formula", "Table: %d rows", "Keywords", "Change"},
+}
+
+func labelsFor(lang string) captionLabels {
+	if l, ok := labelsByLang[lang]; ok {
+		return l
+	}
+	return labelsByLang["es"]
+}
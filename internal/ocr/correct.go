@@ -0,0 +1,304 @@
+package ocr
+
+import (
+	"bufio"
+	"embed"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+//go:embed dict/*.txt
+var dictFS embed.FS
+
+// Corrector aplica correcciones posteriores al OCR: une palabras cortadas con
+// guion al final de línea, restaura acentos y corrige confusiones típicas de
+// Tesseract ("rn" → "m", "0" → "o") y errores por distancia de edición.
+//
+// El diccionario es chico, así que una palabra que no está en él suele ser
+// correcta: contra el diccionario solo se restauran acentos y se corrigen
+// palabras con dígitos en medio ("c0mo"), que no pueden ser correctas. Un
+// acento solo se restaura si la palabra tiene una única forma en el
+// diccionario: "como" y "esta" también son palabras y quedan como están. El
+// resto de las correcciones solo lleva a términos del glosario del curso.
+type Corrector struct {
+	// forma plegada (minúsculas, sin acentos) -> formas del diccionario
+	words    map[string][]string
+	glossary map[string]string
+	byLen    map[int][]string // términos del glosario plegados, por longitud en runas
+}
+
+// NewCorrector carga el diccionario embebido de los idiomas de Tesseract
+// (p. ej. "spa+eng"), los archivos extra (una palabra por línea) y el glosario.
+func NewCorrector(tessLang string, glossary []string, dictFiles []string) (*Corrector, error) {
+	c := &Corrector{
+		words:    map[string][]string{},
+		glossary: map[string]string{},
+		byLen:    map[int][]string{},
+	}
+
+	for _, lang := range strings.Split(tessLang, "+") {
+		name := ""
+		switch strings.TrimSpace(lang) {
+		case "spa":
+			name = "dict/es.txt"
+		case "eng":
+			name = "dict/en.txt"
+		}
+		if name == "" {
+			continue
+		}
+		f, err := dictFS.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		c.loadWords(bufio.NewScanner(f))
+		f.Close()
+	}
+
+	for _, p := range dictFiles {
+		f, err := os.Open(p)
+		if err != nil {
+			return nil, err
+		}
+		c.loadWords(bufio.NewScanner(f))
+		f.Close()
+	}
+
+	for _, term := range glossary {
+		for _, w := range wordRe.FindAllString(term, -1) {
+			k := fold(w)
+			c.glossary[k] = w
+			c.addLen(k)
+		}
+	}
+
+	return c, nil
+}
+
+func (c *Corrector) loadWords(sc *bufio.Scanner) {
+	for sc.Scan() {
+		w := strings.TrimSpace(sc.Text())
+		if w == "" || strings.HasPrefix(w, "#") {
+			continue
+		}
+		w = strings.ToLower(w)
+		k := fold(w)
+		if !slices.Contains(c.words[k], w) {
+			c.words[k] = append(c.words[k], w)
+		}
+	}
+}
+
+func (c *Corrector) addLen(k string) {
+	n := utf8.RuneCountInString(k)
+	c.byLen[n] = append(c.byLen[n], k)
+}
+
+var (
+	// "pala-\nbra" -> "palabra"; Tesseract también usa "¬" como guion blando
+	hyphenBreak = regexp.MustCompile(`(\p{L})[-‐¬]\s*\n\s*(\p{Ll})`)
+	wordRe      = regexp.MustCompile(`[\p{L}\p{N}]+`)
+	digitSubs   = strings.NewReplacer("0", "o", "1", "l", "5", "s", "3", "e")
+)
+
+// Correct devuelve el texto corregido conservando saltos de línea y puntuación.
+// Los bloques de código, fórmulas y tablas no se tocan.
+func (c *Corrector) Correct(text string) string {
+	text = hyphenBreak.ReplaceAllString(text, "$1$2")
+
+	regions := ClassifyRegions(text)
+	blocks := make([]string, 0, len(regions))
+	for _, r := range regions {
+		if r.Kind == RegionText {
+			blocks = append(blocks, wordRe.ReplaceAllStringFunc(r.Text, c.correctWord))
+		} else {
+			blocks = append(blocks, r.Text)
+		}
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+func (c *Corrector) correctWord(w string) string {
+	if !hasLetter(w) || utf8.RuneCountInString(w) < 2 {
+		return w
+	}
+
+	lower := strings.ToLower(w)
+	k := fold(lower)
+
+	// 1) glosario del curso
+	if g, ok := c.glossary[k]; ok {
+		return glossaryCase(w, g)
+	}
+
+	// 2) palabra conocida: restaurar acentos si se perdieron y no hay dudas
+	// (con como/cómo las dos formas son correctas)
+	if forms, ok := c.words[k]; ok {
+		if lower == k && len(forms) == 1 {
+			return applyCase(w, forms[0])
+		}
+		return w
+	}
+
+	// 3) dígitos en lugar de letras: la palabra no puede ser correcta
+	if strings.ContainsAny(lower, "0135") && mostlyLetters(lower) {
+		v := fold(digitSubs.Replace(lower))
+		if g, ok := c.glossary[v]; ok {
+			return glossaryCase(w, g)
+		}
+		if forms, ok := c.words[v]; ok {
+			if len(forms) == 1 {
+				return applyCase(w, forms[0])
+			}
+			if slices.Contains(forms, v) {
+				return applyCase(w, v)
+			}
+		}
+	}
+
+	// 4) otras confusiones típicas de OCR, solo hacia el glosario
+	for _, v := range ocrVariants(lower) {
+		if g, ok := c.glossary[fold(v)]; ok {
+			return glossaryCase(w, g)
+		}
+	}
+
+	// 5) distancia de edición al glosario, solo si hay un único mejor candidato
+	if best, ok := c.closest(k); ok {
+		return glossaryCase(w, c.glossary[best])
+	}
+
+	return w
+}
+
+func ocrVariants(w string) []string {
+	var out []string
+	if strings.Contains(w, "rn") {
+		out = append(out, strings.ReplaceAll(w, "rn", "m"))
+	}
+	if strings.Contains(w, "m") {
+		out = append(out, strings.ReplaceAll(w, "m", "rn"))
+	}
+	if strings.Contains(w, "cl") {
+		out = append(out, strings.ReplaceAll(w, "cl", "d"))
+	}
+	if strings.Contains(w, "vv") {
+		out = append(out, strings.ReplaceAll(w, "vv", "w"))
+	}
+	return out
+}
+
+func mostlyLetters(w string) bool {
+	letters, digits := 0, 0
+	for _, r := range w {
+		if unicode.IsLetter(r) {
+			letters++
+		} else if unicode.IsDigit(r) {
+			digits++
+		}
+	}
+	return letters > digits
+}
+
+// closest busca el término del glosario más cercano a k. Un plural del término
+// ("neuronas" para "neurona") no es un error y se deja como está.
+func (c *Corrector) closest(k string) (string, bool) {
+	n := utf8.RuneCountInString(k)
+	if n < 5 {
+		return "", false
+	}
+	maxDist := 1
+	if n >= 9 {
+		maxDist = 2
+	}
+
+	best, bestDist, ties := "", maxDist+1, 0
+	for l := n - maxDist; l <= n+maxDist; l++ {
+		for _, cand := range c.byLen[l] {
+			if k == cand+"s" || k == cand+"es" {
+				return "", false
+			}
+			d := editDistance(k, cand, maxDist)
+			if d < bestDist {
+				best, bestDist, ties = cand, d, 1
+			} else if d == bestDist && cand != best {
+				ties++
+			}
+		}
+	}
+	if bestDist > maxDist || ties != 1 {
+		return "", false
+	}
+	return best, true
+}
+
+// editDistance calcula Levenshtein por runas; corta en cuanto supera limit.
+func editDistance(a, b string, limit int) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		rowMin := cur[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			rowMin = min(rowMin, cur[j])
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+var accentFold = strings.NewReplacer(
+	"á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u",
+	"à", "a", "è", "e", "ì", "i", "ò", "o", "ù", "u",
+)
+
+// fold pasa a minúsculas y quita acentos (la ñ se conserva).
+func fold(s string) string {
+	return accentFold.Replace(strings.ToLower(s))
+}
+
+func hasLetter(s string) bool {
+	for _, r := range s {
+		if unicode.IsLetter(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// glossaryCase respeta la forma del glosario salvo que esté toda en minúsculas.
+func glossaryCase(orig, term string) string {
+	if strings.ToLower(term) == term {
+		return applyCase(orig, term)
+	}
+	return term
+}
+
+// applyCase copia el patrón de mayúsculas de orig sobre la palabra corregida.
+func applyCase(orig, word string) string {
+	if strings.ToUpper(orig) == orig && utf8.RuneCountInString(orig) > 1 {
+		return strings.ToUpper(word)
+	}
+	r, _ := utf8.DecodeRuneInString(orig)
+	if unicode.IsUpper(r) {
+		w, size := utf8.DecodeRuneInString(word)
+		return string(unicode.ToUpper(w)) + word[size:]
+	}
+	return word
+}
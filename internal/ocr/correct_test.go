@@ -0,0 +1,29 @@
+package ocr
+
+import "testing"
+
+func TestCorrectAccents(t *testing.T) {
+	c, err := NewCorrector("spa", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct{ in, want string }{
+		// sin acento también son palabras: no se tocan
+		{"como", "como"},
+		{"esta", "esta"},
+		{"practica", "practica"},
+		{"Esta práctica", "Esta práctica"},
+		{"que si el calculo es publico", "que si el calculo es publico"},
+		// una sola forma en el diccionario: se restaura el acento
+		{"informacion", "información"},
+		{"Funcion", "Función"},
+		// dígitos en lugar de letras
+		{"c0mo", "como"},
+		{"inf0rmacion", "información"},
+	}
+	for _, tc := range cases {
+		if got := c.Correct(tc.in); got != tc.want {
+			t.Errorf("Correct(%q) = %q, se esperaba %q", tc.in, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"IA1_EV2025_Proyecto2/internal/logs"
+)
+
+// Summarizer genera un Summary a partir del texto OCR. previous contiene los
+// resúmenes de las diapositivas anteriores (más antigua primero) como contexto.
+type Summarizer interface {
+	Summarize(ctx context.Context, text string, previous []Summary) Summary
+}
+
+// RuleSummarizer usa el resumen basado en reglas de Summarize.
+type RuleSummarizer struct{}
+
+func (RuleSummarizer) Summarize(_ context.Context, text string, _ []Summary) Summary {
+	return Summarize(text)
+}
+
+// LLMSummarizer envía el texto a un endpoint compatible con OpenAI
+// (/v1/chat/completions), por ejemplo un servidor local de llama.cpp u Ollama.
+// Ante timeout o error cae al resumen por reglas.
+type LLMSummarizer struct {
+	Endpoint   string // base, p. ej. http://localhost:11434/v1
+	Model      string
+	APIKey     string
+	Timeout    time.Duration
+	HTTPClient *http.Client
+	Log        *slog.Logger // nil = slog.Default()
+}
+
+func NewLLMSummarizer(endpoint, model, apiKey string, timeout time.Duration) *LLMSummarizer {
+	return &LLMSummarizer{
+		Endpoint:   endpoint,
+		Model:      model,
+		APIKey:     apiKey,
+		Timeout:    timeout,
+		HTTPClient: &http.Client{},
+		Log:        logs.For("ocr"),
+	}
+}
+
+func (l *LLMSummarizer) Summarize(ctx context.Context, text string, previous []Summary) Summary {
+	if strings.TrimSpace(text) == "" {
+		return Summarize(text)
+	}
+	s, err := l.Request(ctx, text, previous)
+	if err != nil {
+		l.logger().Warn("llm summarize failed, using rules", "model", l.Model, "err", err)
+		return Summarize(text)
+	}
+	return s
+}
+
+func (l *LLMSummarizer) logger() *slog.Logger {
+	if l.Log != nil {
+		return l.Log
+	}
+	return slog.Default()
+}
+
+const llmSystemPrompt = `Eres un asistente que resume diapositivas de clase a partir de texto OCR con errores.
+Responde SOLO con un objeto JSON: {"title": string, "bullets": [string], "keywords": [string]}.
+Máximo 5 bullets breves y 6 keywords. Usa el idioma de la diapositiva.`
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model          string            `json:"model"`
+	Messages       []chatMessage     `json:"messages"`
+	Temperature    float64           `json:"temperature"`
+	ResponseFormat map[string]string `json:"response_format,omitempty"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+type llmSummary struct {
+	Title    string   `json:"title"`
+	Bullets  []string `json:"bullets"`
+	Keywords []string `json:"keywords"`
+}
+
+// Request hace la llamada al modelo sin fallback.
+func (l *LLMSummarizer) Request(ctx context.Context, text string, previous []Summary) (Summary, error) {
+	if l.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, l.Timeout)
+		defer cancel()
+	}
+
+	body, err := json.Marshal(chatRequest{
+		Model: l.Model,
+		Messages: []chatMessage{
+			{Role: "system", Content: llmSystemPrompt},
+			{Role: "user", Content: buildLLMPrompt(text, previous)},
+		},
+		Temperature:    0.2,
+		ResponseFormat: map[string]string{"type": "json_object"},
+	})
+	if err != nil {
+		return Summary{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, chatCompletionsURL(l.Endpoint), bytes.NewReader(body))
+	if err != nil {
+		return Summary{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if l.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+l.APIKey)
+	}
+
+	hc := l.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return Summary{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Summary{}, fmt.Errorf("llm: status %d", resp.StatusCode)
+	}
+
+	var cr chatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&cr); err != nil {
+		return Summary{}, err
+	}
+	if len(cr.Choices) == 0 {
+		return Summary{}, errors.New("llm: respuesta sin choices")
+	}
+
+	ls, err := parseLLMSummary(cr.Choices[0].Message.Content)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	return Summary{
+		Title:    strings.TrimSpace(ls.Title),
+		Bullets:  trimAll(ls.Bullets, 5),
+		Keywords: trimAll(ls.Keywords, 6),
+		RawText:  text,
+		Regions:  specialRegions(ClassifyRegions(text)),
+	}, nil
+}
+
+func chatCompletionsURL(endpoint string) string {
+	endpoint = strings.TrimRight(endpoint, "/")
+	if strings.HasSuffix(endpoint, "/chat/completions") {
+		return endpoint
+	}
+	return endpoint + "/chat/completions"
+}
+
+func buildLLMPrompt(text string, previous []Summary) string {
+	var b strings.Builder
+	if len(previous) > 0 {
+		b.WriteString("Diapositivas anteriores (contexto):\n")
+		for _, p := range previous {
+			b.WriteString("- ")
+			b.WriteString(p.Title)
+			if len(p.Bullets) > 0 {
+				b.WriteString(": ")
+				b.WriteString(strings.Join(p.Bullets, "; "))
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("Texto OCR de la diapositiva actual:\n")
+	b.WriteString(text)
+	return b.String()
+}
+
+// Los modelos locales suelen envolver el JSON en ```json ... ```; se toma el
+// primer objeto del contenido.
+func parseLLMSummary(content string) (llmSummary, error) {
+	start := strings.Index(content, "{")
+	end := strings.LastIndex(content, "}")
+	if start < 0 || end <= start {
+		return llmSummary{}, errors.New("llm: respuesta sin JSON")
+	}
+
+	var ls llmSummary
+	if err := json.Unmarshal([]byte(content[start:end+1]), &ls); err != nil {
+		return llmSummary{}, err
+	}
+	if strings.TrimSpace(ls.Title) == "" && len(ls.Bullets) == 0 {
+		return llmSummary{}, errors.New("llm: resumen vacío")
+	}
+	return ls, nil
+}
+
+func trimAll(in []string, n int) []string {
+	var out []string
+	for _, s := range in {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		out = append(out, s)
+		if len(out) >= n {
+			break
+		}
+	}
+	return out
+}
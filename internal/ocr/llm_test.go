@@ -0,0 +1,149 @@
+package ocr
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const llmTestText = "Redes neuronales\nUna red neuronal aprende pesos a partir de ejemplos.\nSe entrena con descenso por gradiente."
+
+// llmServer responde a /v1/chat/completions con handler y guarda la última
+// petición recibida.
+func llmServer(t *testing.T, handler http.HandlerFunc) (*httptest.Server, *chatRequest) {
+	t.Helper()
+	var got chatRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("petición inválida: %v", err)
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &got
+}
+
+func chatReply(content string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"choices": []any{map[string]any{"message": map[string]string{"role": "assistant", "content": content}}},
+		})
+	}
+}
+
+func testSummarizer(endpoint string, timeout time.Duration) *LLMSummarizer {
+	l := NewLLMSummarizer(endpoint, "test-model", "secreto", timeout)
+	l.Log = slog.New(slog.NewTextHandler(io.Discard, nil))
+	return l
+}
+
+func TestLLMSummarizeOK(t *testing.T) {
+	var auth string
+	srv, req := llmServer(t, func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		chatReply("```json\n{\"title\": \" Redes neuronales \", \"bullets\": [\"Aprenden pesos\", \"\", \"Descenso por gradiente\"], \"keywords\": [\"red\", \"gradiente\"]}\n```")(w, r)
+	})
+
+	prev := []Summary{{Title: "Perceptrón", Bullets: []string{"Una capa"}}}
+	s := testSummarizer(srv.URL+"/v1/", time.Second).Summarize(context.Background(), llmTestText, prev)
+
+	if s.Title != "Redes neuronales" {
+		t.Errorf("Title = %q", s.Title)
+	}
+	if want := []string{"Aprenden pesos", "Descenso por gradiente"}; !reflect.DeepEqual(s.Bullets, want) {
+		t.Errorf("Bullets = %q, se esperaba %q", s.Bullets, want)
+	}
+	if want := []string{"red", "gradiente"}; !reflect.DeepEqual(s.Keywords, want) {
+		t.Errorf("Keywords = %q, se esperaba %q", s.Keywords, want)
+	}
+	if s.RawText != llmTestText {
+		t.Errorf("RawText = %q", s.RawText)
+	}
+	if auth != "Bearer secreto" {
+		t.Errorf("Authorization = %q", auth)
+	}
+	if req.Model != "test-model" || len(req.Messages) != 2 {
+		t.Fatalf("petición = %+v", req)
+	}
+	if user := req.Messages[1].Content; !strings.Contains(user, "Perceptrón: Una capa") || !strings.Contains(user, llmTestText) {
+		t.Errorf("el prompt no trae el contexto y el texto: %q", user)
+	}
+}
+
+func TestLLMRequestErrors(t *testing.T) {
+	cases := []struct {
+		name    string
+		handler http.HandlerFunc
+		timeout time.Duration
+	}{
+		{"timeout", func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(2 * time.Second):
+			}
+		}, 50 * time.Millisecond},
+		{"status 500", func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "fallo", http.StatusInternalServerError)
+		}, time.Second},
+		{"cuerpo no JSON", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, "<html>proxy</html>")
+		}, time.Second},
+		{"sin choices", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, `{"choices": []}`)
+		}, time.Second},
+		{"contenido sin JSON", chatReply("No puedo resumir esto."), time.Second},
+		{"resumen vacío", chatReply(`{"title": "", "bullets": []}`), time.Second},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			srv, _ := llmServer(t, c.handler)
+			l := testSummarizer(srv.URL+"/v1", c.timeout)
+
+			start := time.Now()
+			if _, err := l.Request(context.Background(), llmTestText, nil); err == nil {
+				t.Fatal("se esperaba un error")
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("Request tardó %s, no respetó el timeout", elapsed)
+			}
+
+			// Summarize no falla: cae al resumen por reglas
+			if got, want := l.Summarize(context.Background(), llmTestText, nil), Summarize(llmTestText); !reflect.DeepEqual(got, want) {
+				t.Errorf("Summarize = %+v, se esperaba el resumen por reglas %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestLLMSummarizeUnreachable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	l := testSummarizer(url, time.Second)
+	if got, want := l.Summarize(context.Background(), llmTestText, nil), Summarize(llmTestText); !reflect.DeepEqual(got, want) {
+		t.Errorf("Summarize = %+v, se esperaba el resumen por reglas %+v", got, want)
+	}
+}
+
+func TestLLMSummarizeEmptyText(t *testing.T) {
+	srv, _ := llmServer(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("no debería llamar al modelo con texto vacío")
+	})
+	l := testSummarizer(srv.URL, time.Second)
+	if got, want := l.Summarize(context.Background(), "  \n", nil), Summarize("  \n"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Summarize = %+v, se esperaba %+v", got, want)
+	}
+}
@@ -2,15 +2,21 @@ package ocr
 
 import (
 	"errors"
+	"log/slog"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/otiai10/gosseract/v2"
 	"gocv.io/x/gocv"
+
+	"IA1_EV2025_Proyecto2/internal/logs"
+	"IA1_EV2025_Proyecto2/internal/metrics"
 )
 
 type Client struct {
-	c *gosseract.Client
+	c   *gosseract.Client
+	log *slog.Logger
 }
 
 func NewClient(lang string) (*Client, error) {
@@ -19,25 +25,54 @@ func NewClient(lang string) (*Client, error) {
 		return nil, errors.New("no se pudo crear cliente gosseract")
 	}
 	_ = c.SetLanguage(lang)
-	return &Client{c: c}, nil
+	// conservar espacios para la detección de código y tablas
+	_ = c.SetVariable("preserve_interword_spaces", "1")
+	return &Client{c: c, log: logs.For("ocr").With("lang", lang)}, nil
 }
 
 func (cl *Client) Close() { cl.c.Close() }
 
 func (cl *Client) ExtractText(frame gocv.Mat) (string, int64, error) {
 	start := time.Now()
+	txt, err := cl.extract(frame)
+	metrics.OCRDuration.ObserveSince(start)
+	ms := time.Since(start).Milliseconds()
+	if err != nil {
+		metrics.OCRFailures.Inc()
+	} else {
+		cl.log.Debug("text extracted", "chars", len(txt), "ms", ms)
+	}
+	return txt, ms, err
+}
+
+func (cl *Client) extract(frame gocv.Mat) (string, error) {
 
 	buf, err := gocv.IMEncode(gocv.JPEGFileExt, frame)
 	if err != nil {
-		return "", time.Since(start).Milliseconds(), err
+		return "", err
 	}
 	defer buf.Close()
 
 	cl.c.SetImageFromBytes(buf.GetBytes())
 	txt, err := cl.c.Text()
 	if err != nil {
-		return "", time.Since(start).Milliseconds(), err
+		return "", err
+	}
+	return strings.TrimSpace(txt), nil
+}
+
+// MissingLanguages devuelve los idiomas de lang ("spa+eng") que no están
+// instalados en tessdata.
+func MissingLanguages(lang string) ([]string, error) {
+	installed, err := gosseract.GetAvailableLanguages()
+	if err != nil {
+		return nil, err
+	}
+	var missing []string
+	for _, l := range strings.Split(lang, "+") {
+		if l = strings.TrimSpace(l); l != "" && !slices.Contains(installed, l) {
+			missing = append(missing, l)
+		}
 	}
-	txt = strings.TrimSpace(txt)
-	return txt, time.Since(start).Milliseconds(), nil
+	return missing, nil
 }
@@ -0,0 +1,204 @@
+package ocr
+
+import (
+	"bytes"
+	"encoding/csv"
+	"regexp"
+	"strings"
+)
+
+type RegionKind string
+
+const (
+	RegionText  RegionKind = "text"
+	RegionCode  RegionKind = "code"
+	RegionMath  RegionKind = "math"
+	RegionTable RegionKind = "table"
+)
+
+// Region es un bloque del texto OCR (separado por líneas en blanco) con su tipo.
+// Text conserva el bloque tal cual; Rows solo se llena para tablas.
+type Region struct {
+	Kind RegionKind
+	Text string
+	Rows [][]string
+}
+
+var (
+	codeKeyword = regexp.MustCompile(`^\s*(def|func|function|return|if|else|elif|for|while|switch|case|class|import|from|package|public|private|static|void|int|float|double|char|bool|var|let|const|print|printf|println|System\.out|#include|#define|try|catch|except)\b`)
+	// los comentarios (// y --) solo cuentan al principio de la línea: en medio
+	// son URLs (https://...) o guiones del texto
+	codeSyntax = regexp.MustCompile(`[;{}]\s*$|^\s*[{}]|\w+\([^)]*\)\s*[;{:]?\s*$|==|!=|\+\+|\w--|->|=>|:=|&&|\|\||^\s*//|^\s*--\s|/\*`)
+	mathSymbol = regexp.MustCompile(`[=+\-*/^√∑∏∫≤≥≠≈±∞πθλμσαβγδΔ∂∈∀∃→⇒<>]`)
+	cellSplit  = regexp.MustCompile(`\s*\|\s*|\t+| {2,}`)
+	urlRe      = regexp.MustCompile(`\w+://\S+`)
+)
+
+// ClassifyRegions separa el texto en bloques y clasifica cada uno como texto,
+// código, fórmula o tabla.
+func ClassifyRegions(text string) []Region {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	var regions []Region
+	for _, block := range splitBlocks(text) {
+		lines := strings.Split(block, "\n")
+		switch {
+		case looksLikeTable(lines):
+			regions = append(regions, Region{Kind: RegionTable, Text: block, Rows: tableRows(lines)})
+		case looksLikeCode(lines):
+			regions = append(regions, Region{Kind: RegionCode, Text: block})
+		case looksLikeMath(lines):
+			regions = append(regions, Region{Kind: RegionMath, Text: block})
+		default:
+			regions = append(regions, Region{Kind: RegionText, Text: block})
+		}
+	}
+	return regions
+}
+
+// RegionKinds devuelve los tipos no textuales presentes, sin repetir.
+func RegionKinds(regions []Region) []string {
+	seen := map[RegionKind]bool{}
+	var out []string
+	for _, r := range regions {
+		if r.Kind == RegionText || seen[r.Kind] {
+			continue
+		}
+		seen[r.Kind] = true
+		out = append(out, string(r.Kind))
+	}
+	return out
+}
+
+func splitBlocks(text string) []string {
+	var blocks []string
+	var cur []string
+	flush := func() {
+		if len(cur) > 0 {
+			blocks = append(blocks, strings.Join(cur, "\n"))
+			cur = nil
+		}
+	}
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+		cur = append(cur, strings.TrimRight(line, " \t"))
+	}
+	flush()
+	return blocks
+}
+
+func looksLikeCode(lines []string) bool {
+	hits := 0
+	for _, l := range lines {
+		if codeKeyword.MatchString(l) || codeSyntax.MatchString(l) {
+			hits++
+		}
+	}
+	if len(lines) == 1 {
+		return codeKeyword.MatchString(lines[0]) && codeSyntax.MatchString(lines[0])
+	}
+	return float64(hits)/float64(len(lines)) >= 0.5
+}
+
+func looksLikeMath(lines []string) bool {
+	hits := 0
+	for _, l := range lines {
+		trimmed := strings.TrimSpace(urlRe.ReplaceAllString(l, "url")) // las barras de una URL no son divisiones
+		symbols := len(mathSymbol.FindAllString(trimmed, -1))
+		words := 0
+		for _, w := range strings.Fields(trimmed) {
+			if len([]rune(w)) >= 4 && !mathSymbol.MatchString(w) {
+				words++
+			}
+		}
+		// una fórmula tiene símbolos y pocas palabras largas
+		if symbols >= 2 && words <= 2 {
+			hits++
+		}
+	}
+	return float64(hits)/float64(len(lines)) >= 0.5
+}
+
+func looksLikeTable(lines []string) bool {
+	if len(lines) < 2 {
+		return false
+	}
+	counts := map[int]int{}
+	for _, l := range lines {
+		n := len(splitCells(l))
+		if n >= 2 {
+			counts[n]++
+		}
+	}
+	best := 0
+	for _, c := range counts {
+		best = max(best, c)
+	}
+	// la mayoría de filas con el mismo número de columnas
+	return best >= 2 && float64(best)/float64(len(lines)) >= 0.6
+}
+
+func splitCells(line string) []string {
+	line = strings.Trim(strings.TrimSpace(line), "|")
+	var cells []string
+	for _, c := range cellSplit.Split(strings.TrimSpace(line), -1) {
+		if c = strings.TrimSpace(c); c != "" {
+			cells = append(cells, c)
+		}
+	}
+	return cells
+}
+
+func tableRows(lines []string) [][]string {
+	var rows [][]string
+	for _, l := range lines {
+		// separadores tipo "---+---"
+		if strings.Trim(l, "-=+| ") == "" {
+			continue
+		}
+		rows = append(rows, splitCells(l))
+	}
+	return rows
+}
+
+// CSV exporta una tabla a CSV.
+func (r Region) CSV() string {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.WriteAll(r.Rows)
+	return buf.String()
+}
+
+// Markdown exporta una tabla a Markdown; la primera fila se toma como cabecera.
+func (r Region) Markdown() string {
+	if len(r.Rows) == 0 {
+		return ""
+	}
+	cols := 0
+	for _, row := range r.Rows {
+		cols = max(cols, len(row))
+	}
+
+	var b strings.Builder
+	writeRow := func(row []string) {
+		b.WriteString("|")
+		for i := 0; i < cols; i++ {
+			cell := ""
+			if i < len(row) {
+				cell = strings.ReplaceAll(row[i], "|", `\|`)
+			}
+			b.WriteString(" " + cell + " |")
+		}
+		b.WriteString("\n")
+	}
+
+	writeRow(r.Rows[0])
+	b.WriteString("|" + strings.Repeat(" --- |", cols) + "\n")
+	for _, row := range r.Rows[1:] {
+		writeRow(row)
+	}
+	return b.String()
+}
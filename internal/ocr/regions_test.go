@@ -0,0 +1,27 @@
+package ocr
+
+import "testing"
+
+func TestClassifyRegions(t *testing.T) {
+	cases := []struct {
+		name string
+		text string
+		want RegionKind
+	}{
+		{"URL", "Material del curso\nhttps://example.edu/ia1/slides\nLeer antes de la clase", RegionText},
+		{"solo URL", "Ver https://example.edu/ia1", RegionText},
+		{"guiones", "Redes neuronales -- repaso\nEl perceptrón -- una sola capa", RegionText},
+		{"raya", "Aprendizaje supervisado — ejemplos con etiqueta\nNo supervisado — sin etiqueta", RegionText},
+		{"comentario //", "// suma los pesos\nx = w * a\ntotal = suma(x)", RegionCode},
+		{"comentario --", "-- clientes activos\nSELECT nombre FROM clientes;", RegionCode},
+		{"código C", "for (i = 0; i < n; i++) {\n  s += a[i];\n}", RegionCode},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			regions := ClassifyRegions(tc.text)
+			if len(regions) != 1 || regions[0].Kind != tc.want {
+				t.Errorf("regiones = %+v, se esperaba %s", regions, tc.want)
+			}
+		})
+	}
+}
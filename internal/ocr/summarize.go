@@ -1,7 +1,6 @@
 package ocr
 
 import (
-	"fmt"
 	"regexp"
 	"sort"
 	"strings"
@@ -12,13 +11,17 @@ type Summary struct {
 	Bullets  []string
 	Keywords []string
 	RawText  string
+	Regions  []Region // código, fórmulas y tablas, conservados tal cual
 }
 
 var nonWord = regexp.MustCompile(`[^\p{L}\p{N}\s]+`)
 
 func Summarize(text string) Summary {
+	// Código, fórmulas y tablas se separan antes de limpiar para no perder símbolos
+	regions := ClassifyRegions(text)
+
 	// Limpiar texto primero
-	clean := cleanText(text)
+	clean := cleanText(proseText(regions))
 	lines := strings.Split(clean, "\n")
 
 	// Extraer título de manera inteligente
@@ -47,41 +50,83 @@ func Summarize(text string) Summary {
 		Bullets:  bullets,
 		Keywords: keywords,
 		RawText:  text,
+		Regions:  specialRegions(regions),
+	}
+}
+
+func proseText(regions []Region) string {
+	var parts []string
+	for _, r := range regions {
+		if r.Kind == RegionText {
+			parts = append(parts, r.Text)
+		}
 	}
+	return strings.Join(parts, "\n")
+}
+
+func specialRegions(regions []Region) []Region {
+	var out []Region
+	for _, r := range regions {
+		if r.Kind != RegionText {
+			out = append(out, r)
+		}
+	}
+	return out
 }
 
 func BuildCaption(s Summary, maxChars int, changeScore float64) string {
-	var b strings.Builder
+	return BuildCaptionLang(s, maxChars, changeScore, "es")
+}
 
-	if s.Title != "" {
-		b.WriteString("Título: ")
-		b.WriteString(s.Title)
-		b.WriteString("\n")
+// BuildCaptionLang genera el caption en texto plano con las etiquetas en el
+// idioma lang ("es" o "en"), descartando bullets si no cabe.
+func BuildCaptionLang(s Summary, maxChars int, changeScore float64, lang string) string {
+	c, err := RenderCaption(NewCaptionData(s, changeScore), CaptionOptions{
+		Format:   FormatPlain,
+		Lang:     lang,
+		MaxChars: maxChars,
+	})
+	if err != nil {
+		// la plantilla por defecto no falla; por si acaso, solo el título
+		t, _ := TruncateRunes(s.Title, maxChars)
+		return t
 	}
+	return c.Text
+}
 
-	if len(s.Bullets) > 0 {
-		b.WriteString("\nPuntos:\n")
-		for _, x := range s.Bullets {
-			b.WriteString("• ")
-			b.WriteString(x)
-			b.WriteString("\n")
-		}
+// BuildNotes genera notas en Markdown de la diapositiva; el código va en
+// bloques monoespaciados y las tablas como tablas Markdown.
+func BuildNotes(s Summary) string {
+	var b strings.Builder
+
+	title := s.Title
+	if title == "" {
+		title = "Diapositiva"
 	}
+	b.WriteString("# " + title + "\n\n")
 
-	if len(s.Keywords) > 0 {
-		b.WriteString("\nPalabras clave: ")
-		b.WriteString(strings.Join(s.Keywords, ", "))
+	for _, x := range s.Bullets {
+		b.WriteString("- " + x + "\n")
+	}
+	if len(s.Bullets) > 0 {
 		b.WriteString("\n")
 	}
 
-	b.WriteString("\nCambio: ")
-	b.WriteString(fmt.Sprintf("%.1f%%", changeScore*100))
+	for _, r := range s.Regions {
+		switch r.Kind {
+		case RegionCode:
+			b.WriteString("```\n" + r.Text + "\n```\n\n")
+		case RegionMath:
+			b.WriteString("$$\n" + r.Text + "\n$$\n\n")
+		case RegionTable:
+			b.WriteString(r.Markdown() + "\n")
+		}
+	}
 
-	out := b.String()
-	if len(out) > maxChars {
-		out = out[:maxChars-3] + "..."
+	if len(s.Keywords) > 0 {
+		b.WriteString("Palabras clave: " + strings.Join(s.Keywords, ", ") + "\n")
 	}
-	return out
+	return b.String()
 }
 
 func splitLines(s string) []string {
@@ -107,25 +152,27 @@ func topKeywords(s string, n int) []string {
 		"uno": true, "como": true, "más": true, "mas": true,
 	}
 
-	freq := map[string]int{}
-	for _, w := range words {
-		if len(w) < 4 || stop[w] {
-			continue
-		}
-		freq[w]++
-	}
-
 	type kv struct {
 		k string
 		v int
 	}
 
+	// en orden de aparición, para que los empates salgan siempre igual
 	var arr []kv
-	for k, v := range freq {
-		arr = append(arr, kv{k: k, v: v})
+	pos := map[string]int{}
+	for _, w := range words {
+		if len(w) < 4 || stop[w] {
+			continue
+		}
+		if i, ok := pos[w]; ok {
+			arr[i].v++
+			continue
+		}
+		pos[w] = len(arr)
+		arr = append(arr, kv{k: w, v: 1})
 	}
 
-	sort.Slice(arr, func(i, j int) bool { return arr[i].v > arr[j].v })
+	sort.SliceStable(arr, func(i, j int) bool { return arr[i].v > arr[j].v })
 
 	var out []string
 	for i := 0; i < len(arr) && len(out) < n; i++ {
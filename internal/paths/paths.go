@@ -0,0 +1,51 @@
+// Package paths valida las rutas de archivos que el panel sirve o borra.
+package paths
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrOutside indica una ruta fuera del directorio permitido.
+var ErrOutside = errors.New("ruta fuera del directorio de salida")
+
+// Within devuelve p como ruta absoluta si, resueltos los enlaces simbólicos,
+// está dentro de root. Las rutas relativas se toman desde el directorio de
+// trabajo, como las guarda el Runner.
+func Within(root, p string) (string, error) {
+	if p == "" {
+		return "", os.ErrNotExist
+	}
+	absRoot, err := resolve(root)
+	if err != nil {
+		return "", err
+	}
+	abs, err := resolve(p)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(absRoot, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) || filepath.IsAbs(rel) {
+		return "", ErrOutside
+	}
+	return abs, nil
+}
+
+// resolve devuelve la ruta absoluta sin enlaces simbólicos; si el archivo no
+// existe (p. ej. ya borrado) resuelve solo su directorio.
+func resolve(p string) (string, error) {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return "", err
+	}
+	if r, err := filepath.EvalSymlinks(abs); err == nil {
+		return r, nil
+	}
+	dir, err := filepath.EvalSymlinks(filepath.Dir(abs))
+	if err != nil {
+		return abs, nil
+	}
+	return filepath.Join(dir, filepath.Base(abs)), nil
+}
@@ -0,0 +1,393 @@
+package schedule
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event es un VEVENT del calendario: una clase suelta o, con Rule, repetida.
+type Event struct {
+	UID      string
+	Summary  string
+	Location string
+	Start    time.Time
+	End      time.Time
+	Rule     *Rule
+	Except   []time.Time // EXDATE y las ocurrencias movidas (RECURRENCE-ID)
+}
+
+// Rule es la parte soportada de un RRULE: FREQ=DAILY o WEEKLY con INTERVAL,
+// BYDAY, UNTIL y COUNT.
+type Rule struct {
+	Freq     string
+	Interval int
+	ByDay    []time.Weekday
+	Until    time.Time
+	Count    int
+}
+
+// LoadICS lee un archivo .ics (ver ParseICS).
+func LoadICS(path string) ([]Event, []error, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	events, skipped, err := ParseICS(f)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return events, skipped, nil
+}
+
+// vevent es un VEVENT mientras se lee.
+type vevent struct {
+	Event
+	movedFrom time.Time // RECURRENCE-ID: reemplaza esa ocurrencia de la serie UID
+	skip      bool      // día completo o cancelado
+	bad       error     // primer problema: el evento se omite
+}
+
+// ParseICS lee los VEVENT de un calendario iCalendar (RFC 5545). Se ignoran
+// los eventos de día completo y los cancelados. Las horas con TZID usan esa
+// zona si el sistema la conoce y si no la local; las flotantes, la local.
+// Un evento que no se puede usar (sin DTSTART, con una regla no soportada,
+// ...) se omite y su motivo vuelve en skipped; el resto del calendario sirve
+// igual.
+func ParseICS(r io.Reader) (events []Event, skipped []error, err error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	var moved []vevent
+	var cur *vevent
+	nested := 0 // VALARM y otros dentro del evento: se ignoran
+	for n, line := range lines {
+		name, params, value := splitProp(line)
+		if cur == nil {
+			if name == "BEGIN" && value == "VEVENT" {
+				cur = &vevent{}
+			}
+			continue
+		}
+		if name == "BEGIN" {
+			nested++
+			continue
+		}
+		if nested > 0 {
+			if name == "END" {
+				nested--
+			}
+			continue
+		}
+		var err error
+		switch name {
+		case "END":
+			if !cur.skip && cur.bad == nil {
+				if cur.Start.IsZero() {
+					cur.bad = errors.New("sin DTSTART")
+				} else if !cur.End.After(cur.Start) {
+					cur.bad = errors.New("sin duración")
+				}
+			}
+			switch {
+			case cur.bad != nil && !cur.skip:
+				skipped = append(skipped, fmt.Errorf("evento %q omitido: %w", cur.Summary, cur.bad))
+			case !cur.movedFrom.IsZero():
+				moved = append(moved, *cur)
+			case !cur.skip:
+				events = append(events, cur.Event)
+			}
+			cur = nil
+		case "UID":
+			cur.UID = value
+		case "SUMMARY":
+			cur.Summary = unescape(value)
+		case "LOCATION":
+			cur.Location = unescape(value)
+		case "STATUS":
+			cur.skip = cur.skip || strings.EqualFold(value, "CANCELLED")
+		case "DTSTART":
+			if params["VALUE"] == "DATE" || len(value) == 8 {
+				cur.skip = true // día completo: no es una clase
+				continue
+			}
+			cur.Start, err = parseTime(value, params["TZID"])
+		case "DTEND":
+			if params["VALUE"] != "DATE" && len(value) != 8 {
+				cur.End, err = parseTime(value, params["TZID"])
+			}
+		case "DURATION":
+			var d time.Duration
+			if d, err = parseDuration(value); err == nil {
+				cur.End = cur.Start.Add(d)
+			}
+		case "RRULE":
+			cur.Rule, err = parseRule(value)
+		case "EXDATE":
+			for _, v := range strings.Split(value, ",") {
+				var t time.Time
+				if t, err = parseTime(v, params["TZID"]); err != nil {
+					break
+				}
+				cur.Except = append(cur.Except, t)
+			}
+		case "RECURRENCE-ID":
+			cur.movedFrom, err = parseTime(value, params["TZID"])
+		}
+		if err != nil && cur != nil && cur.bad == nil {
+			cur.bad = fmt.Errorf("línea %d (%s): %w", n+1, name, err)
+		}
+	}
+	// una ocurrencia movida o cancelada reemplaza a la original de su serie
+	for _, m := range moved {
+		for i := range events {
+			if events[i].UID == m.UID && events[i].Rule != nil {
+				events[i].Except = append(events[i].Except, m.movedFrom)
+			}
+		}
+		if !m.skip {
+			events = append(events, m.Event)
+		}
+	}
+	return events, skipped, nil
+}
+
+// unfold une las líneas continuadas (las que empiezan con espacio o tab).
+func unfold(r io.Reader) ([]string, error) {
+	var lines []string
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), "\r")
+		if len(line) > 0 && (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, sc.Err()
+}
+
+// splitProp separa "NOMBRE;PARAM=valor:VALOR". Los parámetros pueden ir entre
+// comillas y contener ":".
+func splitProp(line string) (string, map[string]string, string) {
+	quoted := false
+	colon := -1
+	for i, c := range line {
+		if c == '"' {
+			quoted = !quoted
+		} else if c == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return strings.ToUpper(line), nil, ""
+	}
+	parts := strings.Split(line[:colon], ";")
+	params := map[string]string{}
+	for _, p := range parts[1:] {
+		k, v, _ := strings.Cut(p, "=")
+		params[strings.ToUpper(k)] = strings.Trim(v, "\"")
+	}
+	return strings.ToUpper(parts[0]), params, line[colon+1:]
+}
+
+func unescape(s string) string {
+	return strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(s)
+}
+
+// parseTime lee una fecha y hora: en UTC con "Z", en tzid o, sin ninguno,
+// flotante (hora local).
+func parseTime(v, tzid string) (time.Time, error) {
+	v = strings.TrimSpace(v)
+	if s, ok := strings.CutSuffix(v, "Z"); ok {
+		return time.ParseInLocation("20060102T150405", s, time.UTC)
+	}
+	loc := time.Local
+	if tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	if len(v) == 8 {
+		return time.ParseInLocation("20060102", v, loc)
+	}
+	return time.ParseInLocation("20060102T150405", v, loc)
+}
+
+// parseDuration lee una duración como "PT1H40M" o "P1D".
+func parseDuration(v string) (time.Duration, error) {
+	s, ok := strings.CutPrefix(strings.TrimPrefix(v, "+"), "P")
+	if !ok || s == "" {
+		return 0, fmt.Errorf("duración %q inválida", v)
+	}
+	var d time.Duration
+	inTime := false
+	num := ""
+	for _, c := range s {
+		switch {
+		case c >= '0' && c <= '9':
+			num += string(c)
+			continue
+		case c == 'T':
+			inTime = true
+			continue
+		}
+		n, err := strconv.Atoi(num)
+		if err != nil {
+			return 0, fmt.Errorf("duración %q inválida", v)
+		}
+		num = ""
+		switch {
+		case c == 'W' && !inTime:
+			d += time.Duration(n) * 7 * 24 * time.Hour
+		case c == 'D' && !inTime:
+			d += time.Duration(n) * 24 * time.Hour
+		case c == 'H' && inTime:
+			d += time.Duration(n) * time.Hour
+		case c == 'M' && inTime:
+			d += time.Duration(n) * time.Minute
+		case c == 'S' && inTime:
+			d += time.Duration(n) * time.Second
+		default:
+			return 0, fmt.Errorf("duración %q inválida", v)
+		}
+	}
+	if num != "" {
+		return 0, fmt.Errorf("duración %q inválida", v)
+	}
+	return d, nil
+}
+
+var icsDays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+func parseRule(v string) (*Rule, error) {
+	r := &Rule{Interval: 1}
+	for _, part := range strings.Split(v, ";") {
+		k, val, _ := strings.Cut(part, "=")
+		var err error
+		switch strings.ToUpper(k) {
+		case "FREQ":
+			r.Freq = strings.ToUpper(val)
+		case "INTERVAL":
+			r.Interval, err = strconv.Atoi(val)
+		case "COUNT":
+			r.Count, err = strconv.Atoi(val)
+		case "UNTIL":
+			r.Until, err = parseTime(val, "")
+			if err == nil && len(val) == 8 {
+				// una fecha sola incluye todo ese día
+				r.Until = r.Until.AddDate(0, 0, 1).Add(-time.Second)
+			}
+		case "BYDAY":
+			for _, d := range strings.Split(val, ",") {
+				wd, ok := icsDays[strings.ToUpper(d)]
+				if !ok {
+					return nil, fmt.Errorf("BYDAY=%s no soportado", d)
+				}
+				r.ByDay = append(r.ByDay, wd)
+			}
+		case "WKST":
+		default:
+			return nil, fmt.Errorf("RRULE %s no soportado", k)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("RRULE %s: %w", k, err)
+		}
+	}
+	if r.Freq != "DAILY" && r.Freq != "WEEKLY" {
+		return nil, fmt.Errorf("RRULE FREQ=%s no soportado (solo DAILY y WEEKLY)", r.Freq)
+	}
+	if r.Interval < 1 {
+		return nil, fmt.Errorf("RRULE INTERVAL=%d inválido", r.Interval)
+	}
+	return r, nil
+}
+
+// occurrences devuelve los inicios de e en [from, to).
+func (e Event) occurrences(from, to time.Time) []time.Time {
+	if e.Rule == nil {
+		if !e.Start.Before(from) && e.Start.Before(to) {
+			return []time.Time{e.Start}
+		}
+		return nil
+	}
+	r := e.Rule
+	if !r.Until.IsZero() && r.Until.Before(from) {
+		return nil
+	}
+	first := e.Start
+	if r.Count == 0 {
+		// sin COUNT no hace falta contar desde DTSTART: se salta a from por
+		// períodos enteros, que no cambian qué días caen en la serie
+		period := r.Interval
+		if r.Freq == "WEEKLY" {
+			period *= 7
+		}
+		if days := civilDays(e.Start, from); days >= period {
+			first = e.Start.AddDate(0, 0, days/period*period)
+		}
+	}
+	var out []time.Time
+	n := 0
+	for day := first; day.Before(to); day = day.AddDate(0, 0, 1) {
+		if !r.Until.IsZero() && day.After(r.Until) {
+			break
+		}
+		if !e.matches(day) {
+			continue
+		}
+		// las excluidas cuentan para COUNT
+		if n++; r.Count > 0 && n > r.Count {
+			break
+		}
+		if day.Before(from) || slices.ContainsFunc(e.Except, day.Equal) {
+			continue
+		}
+		out = append(out, day)
+	}
+	return out
+}
+
+// matches indica si la serie de e cae el día de t.
+func (e Event) matches(t time.Time) bool {
+	r := e.Rule
+	days := civilDays(e.Start, t)
+	switch r.Freq {
+	case "DAILY":
+		return days%r.Interval == 0 && (len(r.ByDay) == 0 || slices.Contains(r.ByDay, t.Weekday()))
+	default: // WEEKLY, semanas desde el lunes
+		byDay := r.ByDay
+		if len(byDay) == 0 {
+			byDay = []time.Weekday{e.Start.Weekday()}
+		}
+		weeks := (days + mondayOffset(e.Start)) / 7
+		return slices.Contains(byDay, t.Weekday()) && weeks%r.Interval == 0
+	}
+}
+
+// civilDays son los días de calendario de a a b.
+func civilDays(a, b time.Time) int {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return int(time.Date(by, bm, bd, 0, 0, 0, 0, time.UTC).Sub(time.Date(ay, am, ad, 0, 0, 0, 0, time.UTC)).Hours() / 24)
+}
+
+// mondayOffset son los días desde el lunes de la semana de t.
+func mondayOffset(t time.Time) int {
+	return (int(t.Weekday()) + 6) % 7
+}
@@ -0,0 +1,102 @@
+package schedule
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseICSSkipsUnsupported(t *testing.T) {
+	ics := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"UID:ia1",
+		"SUMMARY:IA 1",
+		"DTSTART:20260105T080000Z",
+		"DTEND:20260105T094000Z",
+		"RRULE:FREQ=WEEKLY;BYDAY=MO,WE",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"SUMMARY:Tutoría",
+		"DTSTART:20260105T110000Z",
+		"DTEND:20260105T120000Z",
+		"RRULE:FREQ=MONTHLY;BYDAY=1MO",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"SUMMARY:Laboratorio",
+		"DTSTART:20260106T080000Z",
+		"DTEND:20260106T100000Z",
+		"RRULE:FREQ=WEEKLY;BYDAY=1TU",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"SUMMARY:Sin inicio",
+		"DURATION:PT1H",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	events, skipped, err := ParseICS(strings.NewReader(ics))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Summary != "IA 1" {
+		t.Fatalf("eventos = %+v", events)
+	}
+	if len(skipped) != 3 {
+		t.Fatalf("omitidos = %v", skipped)
+	}
+	for i, want := range []string{"Tutoría", "BYDAY=1TU", "sin DTSTART"} {
+		if !strings.Contains(skipped[i].Error(), want) {
+			t.Errorf("omitido %d = %q, se esperaba %q", i, skipped[i], want)
+		}
+	}
+}
+
+// occurrencesFromStart recorre la serie día por día desde DTSTART: el
+// resultado de occurrences tiene que ser el mismo.
+func occurrencesFromStart(e Event, from, to time.Time) []time.Time {
+	var out []time.Time
+	n := 0
+	for day := e.Start; day.Before(to); day = day.AddDate(0, 0, 1) {
+		if !e.Rule.Until.IsZero() && day.After(e.Rule.Until) {
+			break
+		}
+		if !e.matches(day) {
+			continue
+		}
+		if n++; e.Rule.Count > 0 && n > e.Rule.Count {
+			break
+		}
+		if !day.Before(from) && !slices.ContainsFunc(e.Except, day.Equal) {
+			out = append(out, day)
+		}
+	}
+	return out
+}
+
+func TestOccurrencesFarFromStart(t *testing.T) {
+	zone := time.FixedZone("GT", -6*3600)
+	start := time.Date(2024, 1, 3, 8, 0, 0, 0, zone) // miércoles
+	rules := map[string]*Rule{
+		"diaria":             {Freq: "DAILY", Interval: 1},
+		"cada 3 días":        {Freq: "DAILY", Interval: 3},
+		"diaria en semana":   {Freq: "DAILY", Interval: 2, ByDay: []time.Weekday{time.Monday, time.Friday}},
+		"semanal":            {Freq: "WEEKLY", Interval: 1},
+		"cada 2 semanas":     {Freq: "WEEKLY", Interval: 2, ByDay: []time.Weekday{time.Monday, time.Wednesday}},
+		"cada 3 con domingo": {Freq: "WEEKLY", Interval: 3, ByDay: []time.Weekday{time.Sunday, time.Thursday}},
+		"con COUNT":          {Freq: "WEEKLY", Interval: 1, Count: 200},
+		"con UNTIL pasado":   {Freq: "DAILY", Interval: 1, Until: start.AddDate(1, 0, 0)},
+	}
+	for name, r := range rules {
+		e := Event{Start: start, End: start.Add(100 * time.Minute), Rule: r}
+		for _, offset := range []int{0, 1, 5, 400, 401, 733, 1000} {
+			from := start.AddDate(0, 0, offset).Add(3 * time.Hour)
+			to := from.AddDate(0, 0, 21)
+			got, want := e.occurrences(from, to), occurrencesFromStart(e, from, to)
+			if !slices.EqualFunc(got, want, time.Time.Equal) {
+				t.Errorf("%s, +%d días: %v, se esperaba %v", name, offset, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,153 @@
+// Package schedule arma el horario de clases: clases semanales de la
+// configuración y eventos de un calendario .ics, expandidos a intervalos
+// concretos (Slot) para el planificador.
+package schedule
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Clock es una hora del día en minutos desde la medianoche.
+type Clock int
+
+// ParseClock lee "HH:MM" (24 h).
+func ParseClock(s string) (Clock, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("hora %q inválida (se espera HH:MM)", s)
+	}
+	return Clock(t.Hour()*60 + t.Minute()), nil
+}
+
+func (c Clock) String() string {
+	return fmt.Sprintf("%02d:%02d", c/60, c%60)
+}
+
+// on devuelve la hora c del día de t, en la zona de t.
+func (c Clock) on(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, int(c)/60, int(c)%60, 0, 0, t.Location())
+}
+
+var weekdays = map[string]time.Weekday{
+	"domingo": time.Sunday, "lunes": time.Monday, "martes": time.Tuesday, "miercoles": time.Wednesday,
+	"jueves": time.Thursday, "viernes": time.Friday, "sabado": time.Saturday,
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+	"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+}
+
+// ParseWeekday acepta el día en español (con o sin acento) o en inglés,
+// completo o en sus tres primeras letras ("lun", "mié", "mon").
+func ParseWeekday(s string) (time.Weekday, error) {
+	k := strings.NewReplacer("á", "a", "é", "e").Replace(strings.ToLower(strings.TrimSpace(s)))
+	if d, ok := weekdays[k]; ok {
+		return d, nil
+	}
+	if len(k) == 3 {
+		for name, d := range weekdays {
+			if strings.HasPrefix(name, k) {
+				return d, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("día %q inválido (p. ej. \"lunes\")", s)
+}
+
+var dayNames = [...]string{"dom", "lun", "mar", "mié", "jue", "vie", "sáb"}
+
+// DayName es la abreviatura en español del día de t.
+func DayName(t time.Time) string {
+	return dayNames[t.Weekday()]
+}
+
+// Weekly es una clase que se repite cada semana, en la zona horaria local.
+type Weekly struct {
+	Day        time.Weekday
+	Start, End Clock
+	Title      string
+	ChatIDs    []int64
+	Profile    string
+}
+
+// Slot es una clase concreta del horario.
+type Slot struct {
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Title    string    `json:"title,omitempty"`
+	ChatIDs  []int64   `json:"chat_ids,omitempty"`
+	Profile  string    `json:"profile,omitempty"`
+	Location string    `json:"location,omitempty"` // LOCATION del evento .ics
+	Source   string    `json:"source"`             // "config" o "ics"
+}
+
+// Key identifica la clase para no atenderla dos veces.
+func (s Slot) Key() string {
+	return s.Start.Format(time.RFC3339) + " " + s.Title
+}
+
+// Schedule es el horario completo.
+type Schedule struct {
+	Weekly []Weekly
+	Events []Event
+}
+
+// Empty indica que no hay ninguna clase.
+func (s Schedule) Empty() bool {
+	return len(s.Weekly) == 0 && len(s.Events) == 0
+}
+
+// Slots devuelve las clases que se superponen con [from, to), ordenadas por
+// inicio.
+func (s Schedule) Slots(from, to time.Time) []Slot {
+	var out []Slot
+	// un día antes por las clases que empezaron ayer y siguen
+	for day := from.In(time.Local).AddDate(0, 0, -1); day.Before(to); day = day.AddDate(0, 0, 1) {
+		for _, w := range s.Weekly {
+			if day.Weekday() != w.Day {
+				continue
+			}
+			sl := Slot{Start: w.Start.on(day), End: w.End.on(day), Title: w.Title, ChatIDs: w.ChatIDs, Profile: w.Profile, Source: "config"}
+			if sl.End.After(from) && sl.Start.Before(to) {
+				out = append(out, sl)
+			}
+		}
+	}
+	for _, e := range s.Events {
+		for _, start := range e.occurrences(from.Add(-e.End.Sub(e.Start)), to) {
+			sl := Slot{Start: start, End: start.Add(e.End.Sub(e.Start)), Title: e.Summary, Location: e.Location, Source: "ics"}
+			if sl.End.After(from) {
+				out = append(out, sl)
+			}
+		}
+	}
+	slices.SortStableFunc(out, func(a, b Slot) int { return a.Start.Compare(b.Start) })
+	return out
+}
+
+// Current devuelve la clase en curso en t. Si hay varias, la que empezó
+// última.
+func (s Schedule) Current(t time.Time) (Slot, bool) {
+	var cur Slot
+	found := false
+	for _, sl := range s.Slots(t, t.Add(time.Second)) {
+		if !sl.Start.After(t) && sl.End.After(t) {
+			cur, found = sl, true
+		}
+	}
+	return cur, found
+}
+
+// Lookahead es hasta dónde busca Upcoming.
+const Lookahead = 14 * 24 * time.Hour
+
+// Upcoming devuelve hasta n clases en curso o por empezar desde t.
+func (s Schedule) Upcoming(t time.Time, n int) []Slot {
+	slots := s.Slots(t, t.Add(Lookahead))
+	if len(slots) > n {
+		slots = slots[:n]
+	}
+	return slots
+}
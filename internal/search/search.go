@@ -0,0 +1,293 @@
+// Package search indexa el texto OCR y el resumen de cada diapositiva
+// (índice invertido en memoria) para buscar en todas las sesiones.
+package search
+
+import (
+	"html"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"IA1_EV2025_Proyecto2/internal/ocr"
+)
+
+// Doc es una diapositiva indexada.
+type Doc struct {
+	ID           string      `json:"id"`
+	Session      string      `json:"session"`
+	SessionTitle string      `json:"session_title,omitempty"`
+	CapturedAt   time.Time   `json:"captured_at"`
+	Path         string      `json:"path"`
+	RawPath      string      `json:"raw_path"`
+	Thumb        string      `json:"thumb,omitempty"`
+	Summary      ocr.Summary `json:"summary"` // RawText es el texto OCR completo
+}
+
+// Result es una diapositiva encontrada. En Highlights los términos buscados
+// van entre <mark> y </mark>; el resto del texto está escapado para HTML.
+type Result struct {
+	Doc        Doc      `json:"doc"`
+	Score      float64  `json:"score"`
+	Highlights []string `json:"highlights"`
+}
+
+// Index es el índice invertido, en memoria; se arma al iniciar a partir de la
+// base de datos (internal/store).
+type Index struct {
+	mu       sync.RWMutex
+	docs     map[string]*Doc
+	terms    map[string]map[string]int // término -> ID -> frecuencia
+	docTerms map[string][]string       // términos de cada documento, para reindexar
+}
+
+func New() *Index {
+	return &Index{
+		docs:     map[string]*Doc{},
+		terms:    map[string]map[string]int{},
+		docTerms: map[string][]string{},
+	}
+}
+
+// Add indexa d; si ya existía un documento con el mismo ID (p. ej. una
+// revelación incremental) lo reemplaza.
+func (idx *Index) Add(d Doc) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.index(&d)
+}
+
+// Remove quita el documento del índice.
+func (idx *Index) Remove(id string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.unindex(id)
+	delete(idx.docs, id)
+}
+
+func (idx *Index) unindex(id string) {
+	for _, t := range idx.docTerms[id] {
+		delete(idx.terms[t], id)
+		if len(idx.terms[t]) == 0 {
+			delete(idx.terms, t)
+		}
+	}
+	delete(idx.docTerms, id)
+}
+
+func (idx *Index) index(d *Doc) {
+	idx.unindex(d.ID)
+
+	freq := map[string]int{}
+	for _, t := range Tokenize(docText(d)) {
+		freq[t]++
+	}
+	terms := make([]string, 0, len(freq))
+	for t, n := range freq {
+		if idx.terms[t] == nil {
+			idx.terms[t] = map[string]int{}
+		}
+		idx.terms[t][d.ID] = n
+		terms = append(terms, t)
+	}
+	idx.docs[d.ID] = d
+	idx.docTerms[d.ID] = terms
+}
+
+// Get devuelve el documento con ese ID.
+func (idx *Index) Get(id string) (Doc, bool) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	d, ok := idx.docs[id]
+	if !ok {
+		return Doc{}, false
+	}
+	return *d, true
+}
+
+func (idx *Index) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.docs)
+}
+
+// Search devuelve las diapositivas que contienen todos los términos de query,
+// ordenadas por relevancia (tf-idf) y, a igualdad, la más reciente primero.
+// Los términos de 3 o más letras también coinciden como prefijo ("regres"
+// encuentra "regresión"). limit <= 0 = sin límite.
+func (idx *Index) Search(query string, limit int) []Result {
+	qterms := uniq(Tokenize(query))
+	if len(qterms) == 0 {
+		return nil
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	n := float64(len(idx.docs))
+	var scores map[string]float64
+	var matched []string
+	for _, q := range qterms {
+		hits := map[string]float64{}
+		for _, t := range idx.expand(q) {
+			postings := idx.terms[t]
+			idf := math.Log(1 + n/float64(len(postings)))
+			for id, tf := range postings {
+				hits[id] += float64(tf) * idf
+			}
+			matched = append(matched, t)
+		}
+		if scores == nil {
+			scores = hits
+			continue
+		}
+		for id := range scores {
+			if h, ok := hits[id]; ok {
+				scores[id] += h
+			} else {
+				delete(scores, id)
+			}
+		}
+	}
+
+	results := make([]Result, 0, len(scores))
+	for id, sc := range scores {
+		results = append(results, Result{Doc: *idx.docs[id], Score: sc})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Doc.CapturedAt.After(results[j].Doc.CapturedAt)
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	for i := range results {
+		results[i].Highlights = highlights(docText(&results[i].Doc), matched, 3)
+	}
+	return results
+}
+
+// expand devuelve los términos del índice que coinciden con q.
+func (idx *Index) expand(q string) []string {
+	if len([]rune(q)) < 3 {
+		if _, ok := idx.terms[q]; ok {
+			return []string{q}
+		}
+		return nil
+	}
+	var out []string
+	for t := range idx.terms {
+		if strings.HasPrefix(t, q) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func docText(d *Doc) string {
+	s := d.Summary
+	text := s.RawText
+	if text == "" {
+		text = s.Title + "\n" + strings.Join(s.Bullets, "\n")
+	}
+	return text + "\n" + strings.Join(s.Keywords, " ")
+}
+
+// Tokenize separa el texto en términos en minúsculas y sin acentos.
+func Tokenize(text string) []string {
+	var out []string
+	for _, w := range strings.FieldsFunc(text, isSeparator) {
+		if t := Normalize(w); t != "" {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+}
+
+var accentFolder = strings.NewReplacer(
+	"á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u",
+	"à", "a", "è", "e", "ì", "i", "ò", "o", "ù", "u",
+)
+
+// Normalize pasa un término a minúsculas sin acentos (la ñ se conserva).
+func Normalize(w string) string {
+	return accentFolder.Replace(strings.ToLower(w))
+}
+
+// highlights devuelve hasta max fragmentos de las líneas de text que contienen
+// alguno de los términos, marcados con <mark>.
+func highlights(text string, terms []string, max int) []string {
+	set := map[string]bool{}
+	for _, t := range terms {
+		set[t] = true
+	}
+
+	var out []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		marked, ok := markLine(line, set)
+		if !ok {
+			continue
+		}
+		out = append(out, marked)
+		if len(out) >= max {
+			break
+		}
+	}
+	return out
+}
+
+// PlainHighlight convierte un fragmento de Highlights a texto plano, con los
+// términos entre pre y post.
+func PlainHighlight(h, pre, post string) string {
+	return html.UnescapeString(strings.NewReplacer("<mark>", pre, "</mark>", post).Replace(h))
+}
+
+func markLine(line string, terms map[string]bool) (string, bool) {
+	var b strings.Builder
+	found := false
+	runes := []rune(line)
+	for i := 0; i < len(runes); {
+		if isSeparator(runes[i]) {
+			b.WriteString(html.EscapeString(string(runes[i])))
+			i++
+			continue
+		}
+		j := i
+		for j < len(runes) && !isSeparator(runes[j]) {
+			j++
+		}
+		word := string(runes[i:j])
+		if terms[Normalize(word)] {
+			found = true
+			b.WriteString("<mark>" + html.EscapeString(word) + "</mark>")
+		} else {
+			b.WriteString(html.EscapeString(word))
+		}
+		i = j
+	}
+	return b.String(), found
+}
+
+func uniq(in []string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, s := range in {
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,166 @@
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"IA1_EV2025_Proyecto2/internal/metrics"
+)
+
+// ImportStats resume lo importado por ImportMetricsFile.
+type ImportStats struct {
+	Metrics  int
+	Slides   int
+	Sessions int
+	Skipped  bool // el archivo ya se había importado
+}
+
+// ImportMetricsFile importa un metrics.jsonl de versiones anteriores: cada
+// registro pasa a las métricas y, salvo las revelaciones incrementales, crea la
+// diapositiva correspondiente en la sesión del registro. Los registros sin
+// sesión (los archivos más viejos no la guardaban) se agrupan en una sesión
+// por día y curso. Todo se escribe en una sola transacción junto con la marca
+// de importado, así que cada archivo se importa una sola vez y un error a
+// mitad de camino no deja nada a medias.
+func (s *Store) ImportMetricsFile(path string) (ImportStats, error) {
+	var st ImportStats
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return st, err
+	}
+	doneKey := []byte("imported:" + abs)
+	var done bool
+	_ = s.db.View(func(tx *bolt.Tx) error {
+		done = bucket(tx, bucketMeta).Get(doneKey) != nil
+		return nil
+	})
+	if done {
+		st.Skipped = true
+		return st, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return st, err
+	}
+	defer f.Close()
+
+	var records []metrics.Record
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for sc.Scan() {
+		var r metrics.Record
+		if err := json.Unmarshal(sc.Bytes(), &r); err != nil {
+			continue // línea cortada por un cierre abrupto
+		}
+		records = append(records, r)
+	}
+	if err := sc.Err(); err != nil {
+		return st, err
+	}
+
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		st = ImportStats{}
+		sessions := map[string]Session{}
+		var slides []string // IDs en orden, para guardar cada una una vez
+		byID := map[string]*Slide{}
+		var last *Slide
+
+		for _, r := range records {
+			t, err := time.Parse(time.RFC3339, r.TimeISO)
+			if err != nil {
+				if err := addMetric(tx, r); err != nil {
+					return err
+				}
+				st.Metrics++
+				continue
+			}
+
+			if r.Session == "" {
+				r.Session = "import_" + t.Format("20060102")
+				if r.Course != "" {
+					r.Session += "_" + strings.ReplaceAll(strings.ToLower(r.Course), " ", "_")
+				}
+			}
+			if err := addMetric(tx, r); err != nil {
+				return err
+			}
+			st.Metrics++
+
+			if r.Build && last != nil {
+				last.Path, last.RawPath, last.Score = r.SlidePath, r.RawPath, r.ChangeScore
+				last.Builds++
+				continue
+			}
+
+			sess, ok := sessions[r.Session]
+			if !ok {
+				sess = Session{ID: r.Session, Title: r.Course, StartedAt: t, Imported: true}
+			}
+			sess.EndedAt = t
+			sessions[r.Session] = sess
+
+			sl := &Slide{
+				ID:         slideID(r.RawPath, t),
+				Session:    r.Session,
+				CapturedAt: t,
+				RawPath:    r.RawPath,
+				Path:       r.SlidePath,
+				Score:      r.ChangeScore,
+				OCRMillis:  r.OCRMillis,
+			}
+			if !r.OCROK {
+				sl.OCRError = r.Error
+			}
+			if len(r.MessageIDs) > 0 {
+				sl.Delivery = map[int64]Delivery{}
+				for chat, id := range r.MessageIDs {
+					sl.Delivery[chat] = Delivery{MessageID: id}
+				}
+			}
+			if _, ok := byID[sl.ID]; !ok {
+				slides = append(slides, sl.ID)
+			}
+			byID[sl.ID] = sl
+			last = sl
+			st.Slides++
+		}
+
+		for _, id := range slides {
+			if err := putJSON(tx, bucketSlides, []byte(id), byID[id]); err != nil {
+				return err
+			}
+		}
+		for id, sess := range sessions {
+			// una sesión que ya está en la base (la del registro) no se pisa
+			if bucket(tx, bucketSessions).Get([]byte(id)) != nil {
+				continue
+			}
+			if err := putJSON(tx, bucketSessions, []byte(id), sess); err != nil {
+				return err
+			}
+			st.Sessions++
+		}
+		return bucket(tx, bucketMeta).Put(doneKey, []byte(time.Now().Format(time.RFC3339)))
+	})
+	if err != nil {
+		return ImportStats{}, err
+	}
+	return st, nil
+}
+
+// slideID saca el timestamp del nombre "slide_<ts>_raw.jpg" como hace el Runner.
+func slideID(rawPath string, t time.Time) string {
+	name := strings.TrimSuffix(filepath.Base(rawPath), filepath.Ext(rawPath))
+	if strings.HasPrefix(name, "slide_") && strings.HasSuffix(name, "_raw") {
+		return strings.TrimSuffix(strings.TrimPrefix(name, "slide_"), "_raw")
+	}
+	return t.Format("20060102_150405")
+}
@@ -0,0 +1,66 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const legacyMetrics = `{"time_iso":"2025-03-10T08:00:05Z","course":"IA 1","session":"20250310_080000","slide_path":"out/slide_20250310_080005.jpg","raw_path":"out/slide_20250310_080005_raw.jpg","ocr_ok":true}
+{"time_iso":"2025-03-10T08:01:00Z","course":"IA 1","session":"20250310_080000","slide_path":"out/slide_20250310_080100.jpg","raw_path":"out/slide_20250310_080100_raw.jpg","ocr_ok":true,"build":true}
+{"time_iso":"2025-03-11T09:00:00Z","course":"IA 1","slide_path":"out/slide_20250311_090000.jpg","raw_path":"out/slide_20250311_090000_raw.jpg","ocr_ok":false,"error":"tesseract"}
+{"time_iso":"2025-03-11T09:05:0
+`
+
+func TestImportMetricsFile(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	path := filepath.Join(dir, "metrics.jsonl")
+	if err := os.WriteFile(path, []byte(legacyMetrics), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	st, err := s.ImportMetricsFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Metrics != 3 || st.Slides != 2 || st.Sessions != 2 {
+		t.Errorf("stats = %+v", st)
+	}
+
+	// cada diapositiva queda en la sesión de sus métricas
+	for _, sess := range []string{"20250310_080000", "import_20250311_ia_1"} {
+		slides, err := s.Slides(SlideQuery{Session: sess})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ms, err := s.Metrics(MetricQuery{Session: sess})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(slides) != 1 || len(ms) == 0 {
+			t.Errorf("sesión %s: %d diapositivas, %d métricas", sess, len(slides), len(ms))
+		}
+		if _, ok, _ := s.Session(sess); !ok {
+			t.Errorf("falta la sesión %s", sess)
+		}
+	}
+	sl, _, _ := s.Slide("20250310_080005")
+	if sl.Builds != 1 || !strings.HasSuffix(sl.Path, "080100.jpg") {
+		t.Errorf("revelación no aplicada: %+v", sl)
+	}
+
+	// una segunda importación no duplica nada
+	st, err = s.ImportMetricsFile(path)
+	if err != nil || !st.Skipped {
+		t.Fatalf("stats = %+v, err = %v", st, err)
+	}
+	if ms, _ := s.Metrics(MetricQuery{}); len(ms) != 3 {
+		t.Errorf("%d métricas después de reimportar", len(ms))
+	}
+}
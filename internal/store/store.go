@@ -0,0 +1,352 @@
+// Package store es la base de datos embebida (bbolt) con las sesiones, las
+// diapositivas (OCR, resumen y estado de envío) y las métricas.
+package store
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"IA1_EV2025_Proyecto2/internal/metrics"
+	"IA1_EV2025_Proyecto2/internal/ocr"
+)
+
+var (
+	bucketSessions = []byte("sessions")
+	bucketSlides   = []byte("slides")
+	bucketMetrics  = []byte("metrics")
+	bucketMeta     = []byte("meta")
+)
+
+// ErrLocked indica que otro proceso (p. ej. smartslide en ejecución) tiene la
+// base abierta.
+var ErrLocked = errors.New("la base de datos está en uso por otro proceso")
+
+// Session es una ejecución de captura, desde que se inicia hasta que se detiene.
+type Session struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	StartedAt time.Time `json:"started_at"`
+	EndedAt   time.Time `json:"ended_at,omitempty"` // cero = en curso
+	Imported  bool      `json:"imported,omitempty"` // reconstruida desde metrics.jsonl
+}
+
+// Slide es una diapositiva con todo lo que se obtuvo y envió de ella.
+type Slide struct {
+	ID          string      `json:"id"`
+	Session     string      `json:"session"`
+	CapturedAt  time.Time   `json:"captured_at"`
+	RawPath     string      `json:"raw_path"`
+	Path        string      `json:"path"`
+	Thumb       string      `json:"thumb,omitempty"`
+	Attachments []string    `json:"attachments,omitempty"`
+	Score       float64     `json:"score"`
+	OCRMillis   int64       `json:"ocr_ms"`
+	OCRError    string      `json:"ocr_error,omitempty"`
+	Summary     ocr.Summary `json:"summary"` // RawText es el texto OCR completo
+	Caption     string      `json:"caption"`
+	Builds      int         `json:"builds,omitempty"` // revelaciones incrementales recibidas
+
+	Delivery map[int64]Delivery `json:"delivery,omitempty"` // por chat
+}
+
+// Delivery es el estado de envío de una diapositiva a un chat.
+type Delivery struct {
+	MessageID   int    `json:"message_id,omitempty"` // 0 = no enviada
+	Attachments []int  `json:"attachments,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+type Store struct {
+	db *bolt.DB
+}
+
+// Open abre (o crea) la base en path.
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0644, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, openErr(err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, b := range [][]byte{bucketSessions, bucketSlides, bucketMetrics, bucketMeta} {
+			if _, err := tx.CreateBucketIfNotExists(b); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+// OpenReadOnly abre una base existente solo para lectura (CLI).
+func OpenReadOnly(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0644, &bolt.Options{Timeout: time.Second, ReadOnly: true})
+	if err != nil {
+		return nil, openErr(err)
+	}
+	return &Store{db: db}, nil
+}
+
+func openErr(err error) error {
+	if errors.Is(err, bolt.ErrTimeout) {
+		return ErrLocked
+	}
+	return err
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// ---- sesiones ----
+
+func (s *Store) PutSession(sess Session) error {
+	return s.put(bucketSessions, []byte(sess.ID), sess)
+}
+
+func (s *Store) Session(id string) (Session, bool, error) {
+	var sess Session
+	ok, err := s.get(bucketSessions, []byte(id), &sess)
+	return sess, ok, err
+}
+
+// Sessions devuelve todas las sesiones, la más reciente primero.
+func (s *Store) Sessions() ([]Session, error) {
+	var out []Session
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return bucket(tx, bucketSessions).ForEach(func(k, v []byte) error {
+			var sess Session
+			if err := json.Unmarshal(v, &sess); err != nil {
+				return err
+			}
+			out = append(out, sess)
+			return nil
+		})
+	})
+	sort.Slice(out, func(i, j int) bool { return out[i].StartedAt.After(out[j].StartedAt) })
+	return out, err
+}
+
+// ---- diapositivas ----
+
+// PutSlide guarda la diapositiva, reemplazando la anterior con el mismo ID.
+func (s *Store) PutSlide(sl Slide) error {
+	return s.put(bucketSlides, []byte(sl.ID), sl)
+}
+
+func (s *Store) Slide(id string) (Slide, bool, error) {
+	var sl Slide
+	ok, err := s.get(bucketSlides, []byte(id), &sl)
+	return sl, ok, err
+}
+
+// UpdateSlide aplica fn a la diapositiva dentro de una transacción.
+func (s *Store) UpdateSlide(id string, fn func(*Slide)) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := bucket(tx, bucketSlides)
+		v := b.Get([]byte(id))
+		if v == nil {
+			return errors.New("diapositiva no encontrada")
+		}
+		var sl Slide
+		if err := json.Unmarshal(v, &sl); err != nil {
+			return err
+		}
+		fn(&sl)
+		data, err := json.Marshal(sl)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(id), data)
+	})
+}
+
+func (s *Store) DeleteSlide(id string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return bucket(tx, bucketSlides).Delete([]byte(id))
+	})
+}
+
+// SlideQuery filtra Slides; los campos vacíos no filtran.
+type SlideQuery struct {
+	Session string
+	Since   time.Time
+	Until   time.Time
+	Limit   int // las más recientes
+}
+
+// Slides devuelve las diapositivas que cumplen q en orden cronológico.
+func (s *Store) Slides(q SlideQuery) ([]Slide, error) {
+	var out []Slide
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return bucket(tx, bucketSlides).ForEach(func(k, v []byte) error {
+			var sl Slide
+			if err := json.Unmarshal(v, &sl); err != nil {
+				return err
+			}
+			if q.Session != "" && sl.Session != q.Session {
+				return nil
+			}
+			if !q.Since.IsZero() && sl.CapturedAt.Before(q.Since) {
+				return nil
+			}
+			if !q.Until.IsZero() && !sl.CapturedAt.Before(q.Until) {
+				return nil
+			}
+			out = append(out, sl)
+			return nil
+		})
+	})
+	sort.SliceStable(out, func(i, j int) bool { return out[i].CapturedAt.Before(out[j].CapturedAt) })
+	if q.Limit > 0 && len(out) > q.Limit {
+		out = out[len(out)-q.Limit:]
+	}
+	return out, err
+}
+
+// SessionStats resume las diapositivas de una sesión.
+type SessionStats struct {
+	Slides    int    `json:"slides"`
+	LastSlide string `json:"last_slide,omitempty"` // ID de la más reciente
+	lastAt    time.Time
+}
+
+// SessionStats devuelve el resumen de cada sesión con diapositivas.
+func (s *Store) SessionStats() (map[string]SessionStats, error) {
+	out := map[string]SessionStats{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return bucket(tx, bucketSlides).ForEach(func(k, v []byte) error {
+			var sl Slide
+			if err := json.Unmarshal(v, &sl); err != nil {
+				return err
+			}
+			st := out[sl.Session]
+			st.Slides++
+			if !sl.CapturedAt.Before(st.lastAt) {
+				st.LastSlide, st.lastAt = sl.ID, sl.CapturedAt
+			}
+			out[sl.Session] = st
+			return nil
+		})
+	})
+	return out, err
+}
+
+// ---- métricas ----
+
+// AddMetric agrega un registro; la clave es su hora, así que se leen en orden.
+func (s *Store) AddMetric(r metrics.Record) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return addMetric(tx, r)
+	})
+}
+
+func addMetric(tx *bolt.Tx, r metrics.Record) error {
+	t, err := time.Parse(time.RFC3339, r.TimeISO)
+	if err != nil {
+		t = time.Now()
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	b := bucket(tx, bucketMetrics)
+	seq, err := b.NextSequence()
+	if err != nil {
+		return err
+	}
+	return b.Put(metricKey(t, seq), data)
+}
+
+// MetricQuery filtra Metrics; los campos vacíos no filtran.
+type MetricQuery struct {
+	Since   time.Time // incluida
+	Until   time.Time // excluida
+	Session string
+}
+
+// Metrics devuelve los registros que cumplen q en orden cronológico.
+func (s *Store) Metrics(q MetricQuery) ([]metrics.Record, error) {
+	var out []metrics.Record
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := bucket(tx, bucketMetrics).Cursor()
+		var k, v []byte
+		if q.Since.IsZero() {
+			k, v = c.First()
+		} else {
+			k, v = c.Seek(metricKey(q.Since, 0))
+		}
+		var end []byte
+		if !q.Until.IsZero() {
+			end = metricKey(q.Until, 0)
+		}
+		for ; k != nil; k, v = c.Next() {
+			if end != nil && string(k) >= string(end) {
+				break
+			}
+			var r metrics.Record
+			if err := json.Unmarshal(v, &r); err != nil {
+				return err
+			}
+			if q.Session != "" && r.Session != q.Session {
+				continue
+			}
+			out = append(out, r)
+		}
+		return nil
+	})
+	return out, err
+}
+
+func metricKey(t time.Time, seq uint64) []byte {
+	k := make([]byte, 16)
+	binary.BigEndian.PutUint64(k, uint64(t.UnixNano()))
+	binary.BigEndian.PutUint64(k[8:], seq)
+	return k
+}
+
+// ---- auxiliares ----
+
+func bucket(tx *bolt.Tx, name []byte) *bolt.Bucket {
+	return tx.Bucket(name)
+}
+
+func (s *Store) put(name, key []byte, v any) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return putJSON(tx, name, key, v)
+	})
+}
+
+func putJSON(tx *bolt.Tx, name, key []byte, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return bucket(tx, name).Put(key, data)
+}
+
+func (s *Store) get(name, key []byte, v any) (bool, error) {
+	var data []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := bucket(tx, name)
+		if b == nil {
+			return nil
+		}
+		if d := b.Get(key); d != nil {
+			data = append([]byte(nil), d...)
+		}
+		return nil
+	})
+	if err != nil || data == nil {
+		return false, err
+	}
+	return true, json.Unmarshal(data, v)
+}
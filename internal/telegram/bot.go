@@ -1,40 +1,252 @@
 package telegram
 
 import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"IA1_EV2025_Proyecto2/internal/logs"
 )
 
+// Telegram acepta entre 2 y 10 elementos por álbum.
+const MaxAlbumSize = 10
+
 type Client struct {
-	bot    *tgbotapi.BotAPI
-	chatID int64
+	bot     *tgbotapi.BotAPI
+	retries int
+	ctx     context.Context // corta las esperas entre reintentos; nil = nunca
+	log     *slog.Logger
+}
+
+// Photo es un elemento de un álbum.
+type Photo struct {
+	Path      string
+	Caption   string
+	ParseMode string
+}
+
+func New(token string) (*Client, error) {
+	return NewWithEndpoint(token, "")
 }
 
-func New(token string, chatID int64) (*Client, error) {
-	bot, err := tgbotapi.NewBotAPI(token)
+// NewWithEndpoint usa otro servidor de la Bot API (p. ej. uno local o
+// fakebot). endpoint es la URL base ("http://127.0.0.1:8081") o un formato con
+// dos %s para el token y el método; vacío = api.telegram.org.
+func NewWithEndpoint(token, endpoint string) (*Client, error) {
+	client := &http.Client{Transport: statusTransport{base: http.DefaultTransport}}
+	bot, err := tgbotapi.NewBotAPIWithClient(token, apiEndpoint(endpoint), client)
 	if err != nil {
 		return nil, err
 	}
-	return &Client{bot: bot, chatID: chatID}, nil
+	log := logs.For("telegram")
+	log.Info("connected", "bot", bot.Self.UserName)
+	return &Client{bot: bot, retries: defaultRetries, log: log}, nil
 }
 
-func (c *Client) SendPhotoWithCaption(path string, caption string) error {
-	f, err := os.Open(path)
+// WithContext devuelve un cliente que comparte la conexión con c y deja de
+// esperar para reintentar en cuanto se cancela ctx.
+func (c *Client) WithContext(ctx context.Context) *Client {
+	cc := *c
+	cc.ctx = ctx
+	return &cc
+}
+
+// Username es el usuario del bot, obtenido al conectar.
+func (c *Client) Username() string {
+	return c.bot.Self.UserName
+}
+
+// ChatTitle devuelve el nombre del chat (o del usuario) si el bot puede
+// verlo; sirve para comprobar los destinos.
+func (c *Client) ChatTitle(chatID int64) (string, error) {
+	ch, err := c.bot.GetChat(tgbotapi.ChatInfoConfig{ChatConfig: tgbotapi.ChatConfig{ChatID: chatID}})
 	if err != nil {
-		return err
+		return "", err
 	}
-	defer f.Close()
+	return chatTitle(&ch), nil
+}
+
+func apiEndpoint(endpoint string) string {
+	switch {
+	case endpoint == "":
+		return tgbotapi.APIEndpoint
+	case strings.Contains(endpoint, "%s"):
+		return endpoint
+	}
+	return strings.TrimRight(endpoint, "/") + "/bot%s/%s"
+}
+
+// SendPhotoWithCaption envía la imagen y devuelve el ID del mensaje. parseMode
+// es "MarkdownV2", "HTML" o "" para texto plano.
+func (c *Client) SendPhotoWithCaption(chatID int64, path string, caption string, parseMode string) (int, error) {
+	var id int
+	err := c.retry("sendPhoto", func() error {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
 
-	// Versión actualizada de la API
-	file := tgbotapi.FileReader{
-		Name:   "slide.jpg",
-		Reader: f,
+		// Versión actualizada de la API
+		file := tgbotapi.FileReader{
+			Name:   "slide.jpg",
+			Reader: f,
+		}
+
+		msg := tgbotapi.NewPhoto(chatID, file)
+		msg.Caption = caption
+		msg.ParseMode = parseMode
+
+		sent, err := c.bot.Send(msg)
+		if err != nil {
+			return err
+		}
+		id = sent.MessageID
+		return nil
+	})
+	return id, err
+}
+
+// SendAlbum envía varias imágenes como un solo grupo y devuelve el ID del
+// mensaje de cada una, en el mismo orden.
+func (c *Client) SendAlbum(chatID int64, photos []Photo) ([]int, error) {
+	if len(photos) == 0 {
+		return nil, nil
+	}
+	if len(photos) == 1 {
+		id, err := c.SendPhotoWithCaption(chatID, photos[0].Path, photos[0].Caption, photos[0].ParseMode)
+		return []int{id}, err
 	}
+	if len(photos) > MaxAlbumSize {
+		return nil, errors.New("álbum con más de 10 imágenes")
+	}
+
+	var ids []int
+	err := c.retry("sendMediaGroup", func() error {
+		media := make([]interface{}, 0, len(photos))
+		for _, p := range photos {
+			f, err := os.Open(p.Path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			m := tgbotapi.NewInputMediaPhoto(tgbotapi.FileReader{
+				Name:   filepath.Base(p.Path),
+				Reader: f,
+			})
+			m.Caption = p.Caption
+			m.ParseMode = p.ParseMode
+			media = append(media, m)
+		}
+
+		msgs, err := c.bot.SendMediaGroup(tgbotapi.NewMediaGroup(chatID, media))
+		if err != nil {
+			return err
+		}
+		ids = make([]int, len(msgs))
+		for i, m := range msgs {
+			ids[i] = m.MessageID
+		}
+		return nil
+	})
+	return ids, err
+}
+
+// EditPhoto reemplaza la imagen y el caption de un mensaje ya enviado.
+func (c *Client) EditPhoto(chatID int64, messageID int, path string, caption string, parseMode string) error {
+	return c.retry("editMessageMedia", func() error {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		media := tgbotapi.NewInputMediaPhoto(tgbotapi.FileReader{
+			Name:   "slide.jpg",
+			Reader: f,
+		})
+		media.Caption = caption
+		media.ParseMode = parseMode
+
+		edit := tgbotapi.EditMessageMediaConfig{
+			BaseEdit: tgbotapi.BaseEdit{
+				ChatID:    chatID,
+				MessageID: messageID,
+			},
+			Media: media,
+		}
+
+		_, err = c.bot.Request(edit)
+		return err
+	})
+}
+
+// EditCaption cambia solo el caption de un mensaje ya enviado.
+func (c *Client) EditCaption(chatID int64, messageID int, caption string, parseMode string) error {
+	edit := tgbotapi.NewEditMessageCaption(chatID, messageID, caption)
+	edit.ParseMode = parseMode
+	return c.retry("editMessageCaption", func() error {
+		_, err := c.bot.Request(edit)
+		return err
+	})
+}
+
+// SendText envía un mensaje de texto y devuelve su ID.
+func (c *Client) SendText(chatID int64, text string) (int, error) {
+	return c.ReplyText(chatID, 0, text, "")
+}
+
+// ReplyText envía un texto como respuesta a replyTo (0 = sin respuesta).
+func (c *Client) ReplyText(chatID int64, replyTo int, text string, parseMode string) (int, error) {
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = parseMode
+	msg.ReplyToMessageID = replyTo
+	msg.AllowSendingWithoutReply = true
+
+	var id int
+	err := c.retry("sendMessage", func() error {
+		sent, err := c.bot.Send(msg)
+		if err != nil {
+			return err
+		}
+		id = sent.MessageID
+		return nil
+	})
+	return id, err
+}
+
+// SendDocument envía un archivo (p. ej. CSV de una tabla), opcionalmente como
+// respuesta a replyTo.
+func (c *Client) SendDocument(chatID int64, path string, caption string, replyTo int) (int, error) {
+	var id int
+	err := c.retry("sendDocument", func() error {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
 
-	msg := tgbotapi.NewPhoto(c.chatID, file)
-	msg.Caption = caption
+		doc := tgbotapi.NewDocument(chatID, tgbotapi.FileReader{
+			Name:   filepath.Base(path),
+			Reader: f,
+		})
+		doc.Caption = caption
+		doc.ReplyToMessageID = replyTo
+		doc.AllowSendingWithoutReply = true
 
-	_, err = c.bot.Send(msg)
-	return err
+		sent, err := c.bot.Send(doc)
+		if err != nil {
+			return err
+		}
+		id = sent.MessageID
+		return nil
+	})
+	return id, err
 }
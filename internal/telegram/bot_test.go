@@ -0,0 +1,156 @@
+package telegram
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"IA1_EV2025_Proyecto2/internal/telegram/fakebot"
+)
+
+const testChat int64 = -100123
+
+func newTestClient(t *testing.T) (*Client, *fakebot.Server) {
+	t.Helper()
+	srv := fakebot.Start()
+	t.Cleanup(srv.Close)
+	c, err := NewWithEndpoint("123:TEST", srv.Endpoint())
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.Reset() // getMe
+	return c, srv
+}
+
+func testPhoto(t *testing.T, name string) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(p, []byte("\xff\xd8\xff\xe0 jpeg de prueba"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestSendPhotoAndEdit(t *testing.T) {
+	c, srv := newTestClient(t)
+
+	id, err := c.SendPhotoWithCaption(testChat, testPhoto(t, "a.jpg"), "<b>Redes</b>", "HTML")
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := srv.Calls("sendPhoto")
+	if len(calls) != 1 {
+		t.Fatalf("sendPhoto: %d llamadas", len(calls))
+	}
+	sent := calls[0]
+	if sent.ChatID != testChat || sent.Param("caption") != "<b>Redes</b>" || sent.Param("parse_mode") != "HTML" {
+		t.Errorf("sendPhoto = %+v", sent)
+	}
+	if len(sent.Files) != 1 || sent.Files[0].Size == 0 {
+		t.Errorf("no se subió la imagen: %+v", sent.Files)
+	}
+	if len(sent.MessageIDs) != 1 || sent.MessageIDs[0] != id {
+		t.Errorf("id = %d, el servidor creó %v", id, sent.MessageIDs)
+	}
+
+	if err := c.EditPhoto(testChat, id, testPhoto(t, "b.jpg"), "Redes 2", ""); err != nil {
+		t.Fatal(err)
+	}
+	edits := srv.Calls("editMessageMedia")
+	if len(edits) != 1 || edits[0].MessageIDs[0] != id {
+		t.Fatalf("editMessageMedia = %+v", edits)
+	}
+
+	if err := c.EditPhoto(testChat, id+100, testPhoto(t, "c.jpg"), "", ""); err == nil {
+		t.Error("editar un mensaje que no existe debería fallar")
+	}
+}
+
+func TestSendAlbum(t *testing.T) {
+	c, srv := newTestClient(t)
+
+	photos := []Photo{
+		{Path: testPhoto(t, "1.jpg"), Caption: "uno"},
+		{Path: testPhoto(t, "2.jpg"), Caption: "dos"},
+		{Path: testPhoto(t, "3.jpg"), Caption: "tres"},
+	}
+	ids, err := c.SendAlbum(testChat, photos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := srv.Calls("sendMediaGroup")
+	if len(calls) != 1 {
+		t.Fatalf("sendMediaGroup: %d llamadas", len(calls))
+	}
+	if len(ids) != 3 || len(calls[0].Files) != 3 {
+		t.Errorf("ids = %v, archivos = %d", ids, len(calls[0].Files))
+	}
+
+	// una sola foto va como sendPhoto
+	if _, err := c.SendAlbum(testChat, photos[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(srv.Calls("sendPhoto")); n != 1 {
+		t.Errorf("sendPhoto: %d llamadas", n)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	cases := []struct {
+		name       string
+		status     int
+		retryAfter int
+		fails      int
+		calls      int
+		ok         bool
+	}{
+		{"429 con retry_after", http.StatusTooManyRequests, 1, 1, 2, true},
+		{"5xx", http.StatusBadGateway, 0, 1, 2, true},
+		{"agota los reintentos", http.StatusInternalServerError, 0, 3, 2, false},
+		{"espera más que el máximo", http.StatusTooManyRequests, 30, 1, 1, false},
+		{"400 no se reintenta", http.StatusBadRequest, 0, 1, 1, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, srv := newTestClient(t)
+			c.retries = 1
+			for i := 0; i < tc.fails; i++ {
+				srv.FailNext("sendPhoto", tc.status, tc.retryAfter)
+			}
+
+			start := time.Now()
+			_, err := c.SendPhotoWithCaption(testChat, testPhoto(t, "a.jpg"), "", "")
+			if (err == nil) != tc.ok {
+				t.Fatalf("err = %v", err)
+			}
+			if n := len(srv.Calls("sendPhoto")); n != tc.calls {
+				t.Errorf("sendPhoto: %d llamadas, se esperaban %d", n, tc.calls)
+			}
+			if elapsed := time.Since(start); elapsed > 3*time.Second {
+				t.Errorf("tardó %s", elapsed)
+			}
+		})
+	}
+}
+
+func TestRetryStopsWhenCancelled(t *testing.T) {
+	c, srv := newTestClient(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	c = c.WithContext(ctx)
+	srv.FailNext("sendPhoto", http.StatusTooManyRequests, 10)
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	if _, err := c.SendPhotoWithCaption(testChat, testPhoto(t, "a.jpg"), "", ""); err == nil {
+		t.Fatal("se esperaba el error del 429")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("siguió esperando %s después de cancelar", elapsed)
+	}
+	if n := len(srv.Calls("sendPhoto")); n != 1 {
+		t.Errorf("sendPhoto: %d llamadas", n)
+	}
+}
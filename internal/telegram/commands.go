@@ -0,0 +1,151 @@
+package telegram
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Command es un comando recibido por el bot, p. ej. "/slides 5".
+type Command struct {
+	Name      string // sin "/" ni "@bot"
+	Args      string
+	ChatID    int64
+	ChatTitle string
+	UserID    int64
+	MessageID int
+}
+
+// Reply es la respuesta a un comando. Si Photo o Document tienen una ruta, Text
+// se usa como caption.
+type Reply struct {
+	Text     string
+	Photo    string
+	Document string
+}
+
+type Handler func(cmd Command) Reply
+
+// BotCommands se registran en Telegram para que aparezcan en el menú del chat.
+var BotCommands = []tgbotapi.BotCommand{
+	{Command: "status", Description: "Estado de la captura"},
+	{Command: "start", Description: "Iniciar o reanudar la captura"},
+	{Command: "pause", Description: "Pausar la captura"},
+	{Command: "stop", Description: "Detener la captura"},
+	{Command: "last", Description: "Última diapositiva"},
+	{Command: "slides", Description: "Últimas N diapositivas"},
+	{Command: "search", Description: "Buscar en las diapositivas"},
+	{Command: "export", Description: "Exportar las notas de la sesión"},
+	{Command: "subscribe", Description: "Recibir las diapositivas en este chat"},
+	{Command: "unsubscribe", Description: "Dejar de recibirlas en este chat"},
+	{Command: "profile", Description: "Ver o aplicar un perfil de aula"},
+}
+
+// Listen consulta las actualizaciones del bot hasta que ctx termina y pasa los
+// comandos de usuarios en allowed al handler.
+func (c *Client) Listen(ctx context.Context, allowed []int64, h Handler) {
+	if _, err := c.bot.Request(tgbotapi.NewSetMyCommands(BotCommands...)); err != nil {
+		c.log.Warn("setMyCommands", "err", err)
+	}
+
+	u := tgbotapi.NewUpdate(0)
+	u.Timeout = 30
+	updates := c.bot.GetUpdatesChan(u)
+
+	go func() {
+		<-ctx.Done()
+		c.bot.StopReceivingUpdates()
+	}()
+
+	for upd := range updates {
+		msg := upd.Message
+		if msg == nil || !msg.IsCommand() || msg.From == nil {
+			continue
+		}
+
+		cmd := Command{
+			Name:      strings.ToLower(msg.Command()),
+			Args:      strings.TrimSpace(msg.CommandArguments()),
+			ChatID:    msg.Chat.ID,
+			ChatTitle: chatTitle(msg.Chat),
+			UserID:    msg.From.ID,
+			MessageID: msg.MessageID,
+		}
+
+		var reply Reply
+		if isAllowed(allowed, cmd.UserID) {
+			c.log.Info("command", "command", cmd.Name, "chat", cmd.ChatID, "user", cmd.UserID)
+			reply = h(cmd)
+		} else {
+			c.log.Warn("command from unauthorized user", "command", cmd.Name, "chat", cmd.ChatID, "user", cmd.UserID)
+			reply = Reply{Text: "No autorizado."}
+		}
+
+		if err := c.sendReply(cmd, reply); err != nil {
+			c.log.Error("reply", "command", cmd.Name, "chat", cmd.ChatID, "err", err)
+		}
+	}
+}
+
+func chatTitle(ch *tgbotapi.Chat) string {
+	if ch == nil {
+		return ""
+	}
+	if ch.Title != "" {
+		return ch.Title
+	}
+	return strings.TrimSpace(ch.FirstName + " " + ch.LastName)
+}
+
+func isAllowed(allowed []int64, userID int64) bool {
+	for _, id := range allowed {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Client) sendReply(cmd Command, r Reply) error {
+	var msg tgbotapi.Chattable
+
+	switch {
+	case r.Photo != "" || r.Document != "":
+		path := r.Photo
+		if path == "" {
+			path = r.Document
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		file := tgbotapi.FileReader{Name: filepath.Base(path), Reader: f}
+
+		if r.Photo != "" {
+			p := tgbotapi.NewPhoto(cmd.ChatID, file)
+			p.Caption = r.Text
+			p.ReplyToMessageID = cmd.MessageID
+			msg = p
+		} else {
+			d := tgbotapi.NewDocument(cmd.ChatID, file)
+			d.Caption = r.Text
+			d.ReplyToMessageID = cmd.MessageID
+			msg = d
+		}
+
+	case r.Text != "":
+		m := tgbotapi.NewMessage(cmd.ChatID, r.Text)
+		m.ReplyToMessageID = cmd.MessageID
+		msg = m
+
+	default:
+		return nil
+	}
+
+	_, err := c.bot.Send(msg)
+	return err
+}
@@ -0,0 +1,345 @@
+// Package fakebot es un servidor falso de la Bot API de Telegram para probar
+// telegram.Client y el Runner sin hablar con api.telegram.org. Registra las
+// llamadas (sendPhoto, sendMediaGroup, editMessageMedia, ...), responde como
+// la API real y puede simular 429 y errores 5xx.
+package fakebot
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Call es una petición recibida por el servidor.
+type Call struct {
+	Method     string            `json:"method"`
+	ChatID     int64             `json:"chat_id,omitempty"`
+	Params     map[string]string `json:"params"`
+	Files      []File            `json:"files,omitempty"`
+	Status     int               `json:"status"`                // código HTTP de la respuesta
+	MessageIDs []int             `json:"message_ids,omitempty"` // mensajes creados o editados
+	Time       time.Time         `json:"time"`
+}
+
+// File es un archivo subido en una petición multipart.
+type File struct {
+	Field string `json:"field"`
+	Name  string `json:"name"`
+	Size  int64  `json:"size"`
+}
+
+// Param devuelve un parámetro de la llamada ("" si no está).
+func (c Call) Param(name string) string {
+	return c.Params[name]
+}
+
+type failure struct {
+	method     string // "" = cualquiera
+	status     int
+	retryAfter int
+}
+
+// Bot es el estado del servidor falso; implementa http.Handler.
+type Bot struct {
+	mu       sync.Mutex
+	calls    []Call
+	failures []failure
+	nextID   int
+	messages map[int64]map[int]bool // mensajes enviados por chat
+	updates  []tgbotapi.Update
+	nextUpd  int
+	notify   chan struct{}
+
+	// OnCall, si no es nil, se llama tras cada petición (p. ej. para log).
+	OnCall func(Call)
+}
+
+func New() *Bot {
+	return &Bot{
+		messages: map[int64]map[int]bool{},
+		notify:   make(chan struct{}),
+	}
+}
+
+// Server es un Bot servido con httptest.
+type Server struct {
+	*Bot
+	HTTP *httptest.Server
+}
+
+// Start levanta el servidor en un puerto local libre.
+func Start() *Server {
+	b := New()
+	return &Server{Bot: b, HTTP: httptest.NewServer(b)}
+}
+
+// Endpoint es el valor para telegram.NewWithEndpoint o telegram_api_endpoint.
+func (s *Server) Endpoint() string {
+	return s.HTTP.URL
+}
+
+func (s *Server) Close() {
+	s.HTTP.Close()
+}
+
+// Calls devuelve las llamadas registradas, filtradas por método si se indica.
+func (b *Bot) Calls(methods ...string) []Call {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var out []Call
+	for _, c := range b.calls {
+		if len(methods) == 0 || contains(methods, c.Method) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// Reset borra las llamadas registradas y las fallas pendientes.
+func (b *Bot) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.calls = nil
+	b.failures = nil
+}
+
+// FailNext hace que la próxima llamada a method ("" = cualquiera) responda con
+// status. Con 429, retryAfter son los segundos de espera que se piden.
+func (b *Bot) FailNext(method string, status int, retryAfter int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = append(b.failures, failure{method: method, status: status, retryAfter: retryAfter})
+}
+
+// PushCommand encola un mensaje de texto (p. ej. "/status") para getUpdates.
+func (b *Bot) PushCommand(chatID, userID int64, text string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	msg := &tgbotapi.Message{
+		MessageID: b.nextID,
+		From:      &tgbotapi.User{ID: userID, FirstName: "Test"},
+		Chat:      &tgbotapi.Chat{ID: chatID, Type: "private", FirstName: "Test"},
+		Date:      int(time.Now().Unix()),
+		Text:      text,
+	}
+	if strings.HasPrefix(text, "/") {
+		n := len(strings.Fields(text)[0])
+		msg.Entities = []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: n}}
+	}
+	b.nextUpd++
+	b.updates = append(b.updates, tgbotapi.Update{UpdateID: b.nextUpd, Message: msg})
+
+	close(b.notify)
+	b.notify = make(chan struct{})
+}
+
+func (b *Bot) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// /bot<token>/<método>
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) != 2 || !strings.HasPrefix(parts[0], "bot") {
+		http.NotFound(w, r)
+		return
+	}
+	call := Call{Method: parts[1], Params: map[string]string{}, Time: time.Now()}
+	if err := parseCall(r, &call); err != nil {
+		writeError(w, &call, http.StatusBadRequest, "Bad Request: "+err.Error(), 0)
+		b.record(call)
+		return
+	}
+
+	if f, ok := b.takeFailure(call.Method); ok {
+		desc := http.StatusText(f.status)
+		if f.status == http.StatusTooManyRequests {
+			desc = fmt.Sprintf("Too Many Requests: retry after %d", f.retryAfter)
+		}
+		writeError(w, &call, f.status, desc, f.retryAfter)
+		b.record(call)
+		return
+	}
+
+	if call.Method == "getUpdates" {
+		b.getUpdates(w, r, &call)
+		return
+	}
+
+	result, status, desc := b.handle(&call)
+	if status != http.StatusOK {
+		writeError(w, &call, status, desc, 0)
+	} else {
+		writeResult(w, &call, result)
+	}
+	b.record(call)
+}
+
+func (b *Bot) handle(call *Call) (interface{}, int, string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch call.Method {
+	case "getMe":
+		return tgbotapi.User{ID: 1, IsBot: true, FirstName: "SmartSlide", UserName: "fakebot"}, http.StatusOK, ""
+
+	case "sendPhoto", "sendMessage", "sendDocument":
+		return b.newMessage(call), http.StatusOK, ""
+
+	case "sendMediaGroup":
+		var media []map[string]interface{}
+		if err := json.Unmarshal([]byte(call.Params["media"]), &media); err != nil || len(media) < 2 || len(media) > 10 {
+			return nil, http.StatusBadRequest, "Bad Request: wrong number of media"
+		}
+		msgs := make([]tgbotapi.Message, len(media))
+		for i := range media {
+			msgs[i] = b.newMessage(call)
+		}
+		return msgs, http.StatusOK, ""
+
+	case "editMessageMedia", "editMessageCaption", "editMessageText":
+		id, _ := strconv.Atoi(call.Params["message_id"])
+		if !b.messages[call.ChatID][id] {
+			return nil, http.StatusBadRequest, "Bad Request: message to edit not found"
+		}
+		call.MessageIDs = append(call.MessageIDs, id)
+		return tgbotapi.Message{MessageID: id, Chat: &tgbotapi.Chat{ID: call.ChatID}, Date: int(time.Now().Unix())}, http.StatusOK, ""
+	}
+
+	// setMyCommands y demás: respuesta genérica
+	return true, http.StatusOK, ""
+}
+
+func (b *Bot) newMessage(call *Call) tgbotapi.Message {
+	b.nextID++
+	if b.messages[call.ChatID] == nil {
+		b.messages[call.ChatID] = map[int]bool{}
+	}
+	b.messages[call.ChatID][b.nextID] = true
+	call.MessageIDs = append(call.MessageIDs, b.nextID)
+	return tgbotapi.Message{
+		MessageID: b.nextID,
+		Chat:      &tgbotapi.Chat{ID: call.ChatID},
+		Date:      int(time.Now().Unix()),
+		Text:      call.Params["text"],
+		Caption:   call.Params["caption"],
+	}
+}
+
+// getUpdates espera hasta timeout segundos (máximo 5) a que haya mensajes.
+func (b *Bot) getUpdates(w http.ResponseWriter, r *http.Request, call *Call) {
+	offset, _ := strconv.Atoi(call.Params["offset"])
+	timeout, _ := strconv.Atoi(call.Params["timeout"])
+	deadline := time.After(time.Duration(min(timeout, 5)) * time.Second)
+
+	for {
+		b.mu.Lock()
+		var out []tgbotapi.Update
+		for _, u := range b.updates {
+			if u.UpdateID >= offset {
+				out = append(out, u)
+			}
+		}
+		notify := b.notify
+		b.mu.Unlock()
+
+		if len(out) > 0 || timeout <= 0 {
+			if out == nil {
+				out = []tgbotapi.Update{}
+			}
+			writeResult(w, call, out)
+			b.record(*call)
+			return
+		}
+		select {
+		case <-notify:
+		case <-deadline:
+			timeout = 0
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func (b *Bot) takeFailure(method string) (failure, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, f := range b.failures {
+		if f.method == "" || f.method == method {
+			b.failures = append(b.failures[:i], b.failures[i+1:]...)
+			return f, true
+		}
+	}
+	return failure{}, false
+}
+
+func (b *Bot) record(c Call) {
+	b.mu.Lock()
+	b.calls = append(b.calls, c)
+	onCall := b.OnCall
+	b.mu.Unlock()
+	if onCall != nil {
+		onCall(c)
+	}
+}
+
+func parseCall(r *http.Request, call *Call) error {
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			return err
+		}
+		for field, fhs := range r.MultipartForm.File {
+			for _, fh := range fhs {
+				call.Files = append(call.Files, File{Field: field, Name: fh.Filename, Size: fh.Size})
+			}
+		}
+	} else if err := r.ParseForm(); err != nil {
+		return err
+	}
+	for k, v := range r.Form {
+		if len(v) > 0 {
+			call.Params[k] = v[0]
+		}
+	}
+	if r.MultipartForm != nil {
+		for k, v := range r.MultipartForm.Value {
+			if len(v) > 0 {
+				call.Params[k] = v[0]
+			}
+		}
+	}
+	call.ChatID, _ = strconv.ParseInt(call.Params["chat_id"], 10, 64)
+	return nil
+}
+
+func writeResult(w http.ResponseWriter, call *Call, result interface{}) {
+	call.Status = http.StatusOK
+	raw, _ := json.Marshal(result)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(tgbotapi.APIResponse{Ok: true, Result: raw})
+}
+
+func writeError(w http.ResponseWriter, call *Call, status int, desc string, retryAfter int) {
+	call.Status = status
+	resp := tgbotapi.APIResponse{Ok: false, ErrorCode: status, Description: desc}
+	if retryAfter > 0 {
+		resp.Parameters = &tgbotapi.ResponseParameters{RetryAfter: retryAfter}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func contains(list []string, v string) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
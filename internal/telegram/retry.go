@@ -0,0 +1,110 @@
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"IA1_EV2025_Proyecto2/internal/metrics"
+)
+
+// Reintentos ante 429 (Too Many Requests) y errores 5xx de la API. Los envíos
+// corren en el loop de captura, así que entre todos los reintentos de una
+// petición no se espera más de maxRetryWait: si Telegram pide más, se
+// devuelve el error (la diapositiva queda con el error y se puede reenviar).
+const (
+	defaultRetries = 3
+	maxRetryWait   = 15 * time.Second
+)
+
+// serverError es una respuesta 5xx. La devuelve el transporte para que el
+// error llegue igual desde MakeRequest y UploadFiles (esta última no copia el
+// código de error de la respuesta) aunque el cuerpo no sea JSON.
+type serverError struct {
+	status int
+}
+
+func (e *serverError) Error() string {
+	return fmt.Sprintf("error del servidor de Telegram (%d)", e.status)
+}
+
+type statusTransport struct {
+	base http.RoundTripper
+}
+
+func (t statusTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 500 {
+		resp.Body.Close()
+		return nil, &serverError{status: resp.StatusCode}
+	}
+	return resp, nil
+}
+
+// retry ejecuta fn hasta c.retries veces más mientras Telegram pida esperar
+// (retry_after) o responda con un 5xx, sin pasar de maxRetryWait en total ni
+// seguir esperando si se cancela el contexto del cliente.
+func (c *Client) retry(method string, fn func() error) error {
+	start := time.Now()
+	err := c.retryLoop(method, fn)
+	metrics.SendDuration.ObserveSince(start)
+	if err != nil {
+		metrics.SendFailures.Inc(method)
+	}
+	return err
+}
+
+func (c *Client) retryLoop(method string, fn func() error) error {
+	backoff := time.Second
+	budget := maxRetryWait
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= c.retries {
+			return err
+		}
+
+		var wait time.Duration
+		var tgErr *tgbotapi.Error
+		var srvErr *serverError
+		switch {
+		case errors.As(err, &tgErr) && tgErr.RetryAfter > 0:
+			wait = time.Duration(tgErr.RetryAfter) * time.Second
+		case errors.As(err, &tgErr) && tgErr.Code >= 500, errors.As(err, &srvErr):
+			wait = backoff
+			backoff *= 2
+		default:
+			return err
+		}
+		if wait > budget {
+			return err
+		}
+		budget -= wait
+		c.log.Warn("request failed, retrying", "method", method, "wait", wait, "err", err)
+		metrics.SendRetries.Inc()
+		if !c.sleep(wait) {
+			return err
+		}
+	}
+}
+
+// sleep espera d; devuelve false si antes se cancela el contexto del cliente.
+func (c *Client) sleep(d time.Duration) bool {
+	var done <-chan struct{}
+	if c.ctx != nil {
+		done = c.ctx.Done()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-done:
+		return false
+	}
+}
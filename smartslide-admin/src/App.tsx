@@ -12,7 +12,9 @@ import type { SystemStatus, Config, MetricsData, LogEntry } from './types';
 const App: React.FC = () => {
   const [activeTab, setActiveTab] = useState('control');
   const [status, setStatus] = useState<SystemStatus>({
-    Status: 'stopped',
+    Status: 'idle',
+    SessionID: '',
+    SessionTitle: '',
     StartedAt: '',
     LastSlideAt: '',
     SlidesCaptured: 0,
@@ -103,23 +105,21 @@ const App: React.FC = () => {
 
   const loadLogs = useCallback(async () => {
     try {
-      // Simulación - necesitarías implementar el endpoint
-      const mockLogs: LogEntry[] = Array.from({ length: 20 }, (_, i) => ({
-        id: `log-${i}`,
-        timestamp: new Date(Date.now() - i * 60000).toISOString(),
-        level: ['INFO', 'WARN', 'ERROR', 'DEBUG'][Math.floor(Math.random() * 4)] as any,
-        message: `Log entry ${i + 1}: System operation ${['started', 'completed', 'failed', 'warning'][Math.floor(Math.random() * 4)]}`,
-        source: ['capture', 'ocr', 'telegram', 'system'][Math.floor(Math.random() * 4)]
-      }));
-      setLogs(mockLogs);
+      const data: LogEntry[] = await systemAPI.getLogs();
+      setLogs(data);
     } catch (error) {
       toast.error('Error al cargar logs');
     }
   }, []);
 
-  const handleClearLogs = () => {
-    setLogs([]);
-    toast.success('Logs limpiados');
+  const handleClearLogs = async () => {
+    try {
+      await systemAPI.clearLogs();
+      setLogs([]);
+      toast.success('Logs limpiados');
+    } catch (error) {
+      toast.error('Error al limpiar logs');
+    }
   };
 
   // Carga inicial
@@ -51,7 +51,7 @@ const ControlPanel: React.FC<ControlPanelProps> = ({
           <div className="grid grid-cols-3 gap-3">
             <button
               onClick={onStart}
-              disabled={isLoading || status.Status === 'running'}
+              disabled={isLoading || !['idle', 'paused', 'error'].includes(status.Status)}
               className="bg-green-500 hover:bg-green-600 text-white py-3 px-4 rounded-lg font-medium flex flex-col items-center justify-center gap-2 transition-colors disabled:opacity-50 disabled:cursor-not-allowed"
             >
               <FaPlay className="h-6 w-6" />
@@ -69,7 +69,7 @@ const ControlPanel: React.FC<ControlPanelProps> = ({
             
             <button
               onClick={onStop}
-              disabled={isLoading || status.Status === 'stopped'}
+              disabled={isLoading || !['starting', 'running', 'paused', 'error'].includes(status.Status)}
               className="bg-red-500 hover:bg-red-600 text-white py-3 px-4 rounded-lg font-medium flex flex-col items-center justify-center gap-2 transition-colors disabled:opacity-50 disabled:cursor-not-allowed"
             >
               <FaStop className="h-6 w-6" />
@@ -11,7 +11,7 @@ import {
 } from 'react-icons/fa';
 
 interface LogEntry {
-  id: string;
+  id: number;
   timestamp: string;
   level: 'INFO' | 'WARN' | 'ERROR' | 'DEBUG';
   message: string;
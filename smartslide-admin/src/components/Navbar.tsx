@@ -12,7 +12,10 @@ const Navbar: React.FC<NavbarProps> = ({ activeTab, onTabChange, status }) => {
     switch (status) {
       case 'running': return 'bg-green-500';
       case 'paused': return 'bg-yellow-500';
-      case 'stopped': return 'bg-red-500';
+      case 'starting':
+      case 'stopping': return 'bg-blue-500';
+      case 'idle': return 'bg-gray-400';
+      case 'error': return 'bg-red-500';
       default: return 'bg-gray-500';
     }
   };
@@ -1,5 +1,5 @@
 import React from 'react';
-import { FaPlay, FaPause, FaStop, FaExclamationTriangle } from 'react-icons/fa';
+import { FaPlay, FaPause, FaStop, FaSpinner, FaExclamationTriangle } from 'react-icons/fa';
 
 interface StatusBadgeProps {
   status: string;
@@ -29,11 +29,32 @@ const StatusBadge: React.FC<StatusBadgeProps> = ({ status, size = 'md' }) => {
           text: 'Pausado',
           bgColor: 'bg-yellow-100 text-yellow-800'
         };
-      case 'stopped':
+      case 'idle':
         return {
-          color: 'bg-red-500',
+          color: 'bg-gray-500',
           icon: <FaStop className="text-white" />,
           text: 'Detenido',
+          bgColor: 'bg-gray-100 text-gray-800'
+        };
+      case 'starting':
+        return {
+          color: 'bg-blue-500',
+          icon: <FaSpinner className="text-white animate-spin" />,
+          text: 'Iniciando',
+          bgColor: 'bg-blue-100 text-blue-800'
+        };
+      case 'stopping':
+        return {
+          color: 'bg-blue-500',
+          icon: <FaSpinner className="text-white animate-spin" />,
+          text: 'Deteniendo',
+          bgColor: 'bg-blue-100 text-blue-800'
+        };
+      case 'error':
+        return {
+          color: 'bg-red-500',
+          icon: <FaExclamationTriangle className="text-white" />,
+          text: 'Error',
           bgColor: 'bg-red-100 text-red-800'
         };
       default:
@@ -10,6 +10,27 @@ const api = axios.create({
   },
 });
 
+// Token de sesión de /auth/login (o un token fijo) guardado en el navegador
+const TOKEN_KEY = 'smartslide_token';
+export const getToken = () => localStorage.getItem(TOKEN_KEY) ?? '';
+export const setToken = (token: string) =>
+  token ? localStorage.setItem(TOKEN_KEY, token) : localStorage.removeItem(TOKEN_KEY);
+
+api.interceptors.request.use((config) => {
+  const token = getToken();
+  if (token) {
+    config.headers.Authorization = `Bearer ${token}`;
+  }
+  return config;
+});
+
+// EventSource e <img> no pueden mandar cabeceras: el token va en la URL
+const withToken = (url: string) => {
+  const token = getToken();
+  if (!token) return url;
+  return `${url}${url.includes('?') ? '&' : '?'}access_token=${encodeURIComponent(token)}`;
+};
+
 // Interceptor para manejar errores
 api.interceptors.response.use(
   (response) => response,
@@ -20,23 +41,82 @@ api.interceptors.response.use(
 );
 
 export const systemAPI = {
-  // Estado del sistema
+  // Acceso: login guarda el token; me indica si el panel pide credenciales y el rol
+  login: (username: string, password: string) =>
+    api.post('/auth/login', { username, password }).then((res) => {
+      setToken(res.data.token);
+      return res.data;
+    }),
+  logout: () => api.post('/auth/logout').finally(() => setToken('')),
+  me: () => api.get('/auth/me').then((res) => res.data),
+
+  // Estado del sistema; Upcoming trae las próximas clases del horario
   getStatus: () => api.get('/status').then((res) => res.data),
   
   // Configuración
   getConfig: () => api.get('/config').then((res) => res.data),
   updateConfig: (config: any) => api.post('/config', config).then((res) => res.data),
+  // Cambios parciales (JSON merge patch); devuelve la configuración resultante.
+  // Con datos inválidos responde 422 con { error, fields: [{ field, message }] }
+  patchConfig: (patch: Record<string, unknown>) =>
+    api.patch('/config', patch, { headers: { 'Content-Type': 'application/merge-patch+json' } }).then((res) => res.data),
+  // JSON Schema para armar el formulario de configuración
+  getConfigSchema: () => api.get('/config/schema').then((res) => res.data),
+  // Origen de cada valor y campos fijados por entorno o flags (no editables)
+  getConfigSources: () => api.get('/config/sources').then((res) => res.data),
+
+  // Perfiles por aula: { active, profiles: { nombre: patch } }
+  getProfiles: () => api.get('/config/profiles').then((res) => res.data),
+  saveProfile: (name: string, patch: Record<string, unknown>) =>
+    api.put(`/config/profiles/${encodeURIComponent(name)}`, patch).then((res) => res.data),
+  deleteProfile: (name: string) => api.delete(`/config/profiles/${encodeURIComponent(name)}`).then((res) => res.data),
+  applyProfile: (name: string) => api.post(`/config/profiles/${encodeURIComponent(name)}/apply`).then((res) => res.data),
+
+  // Historial de configuración
+  getConfigHistory: (offset = 0, limit = 50) =>
+    api.get('/config/history', { params: { offset, limit } }).then((res) => res.data),
+  getConfigVersion: (version: number) => api.get(`/config/history/${version}`).then((res) => res.data),
+  // Campos que cambian de version a to (o a la configuración actual)
+  diffConfigVersion: (version: number, to?: number) =>
+    api.get(`/config/history/${version}/diff`, { params: to ? { to } : {} }).then((res) => res.data),
+  rollbackConfig: (version: number) => api.post(`/config/history/${version}/rollback`).then((res) => res.data),
   
-  // Control
+  // Control: {ok, from, to, session}; 409 {error, status} si el estado no lo permite.
+  // start también reanuda la pausa y stop no cierra el servicio: se puede volver a iniciar
   start: () => api.post('/control/start').then((res) => res.data),
   pause: () => api.post('/control/pause').then((res) => res.data),
   stop: () => api.post('/control/stop').then((res) => res.data),
   
-  // Métricas (necesitarás crear este endpoint)
+  // Métricas agregadas; acepta ?from=24h&to=...&session=...
   getMetrics: () => api.get('/metrics').then((res) => res.data),
   
-  // Logs (necesitarás crear este endpoint)
-  getLogs: () => api.get('/logs').then((res) => res.data),
+  // Logs, el más reciente primero; acepta ?level=WARN&source=telegram&q=...&before=ID&limit=N
+  getLogs: (params?: Record<string, string | number>) =>
+    api.get('/logs', { params }).then((res) => res.data),
+  clearLogs: () => api.delete('/logs').then((res) => res.data),
+  // En vivo: new EventSource(logsStreamURL()), un evento "log" por registro
+  logsStreamURL: () => withToken(`${API_BASE_URL}/logs/stream`),
+
+  // Eventos del pipeline: new EventSource(eventsURL('frame,state'));
+  // cada tipo llega como evento con su nombre (ver PipelineEvent)
+  eventsURL: (types = '') => withToken(`${API_BASE_URL}/events${types ? `?types=${types}` : ''}`),
+
+  // Vista previa para un <img src>; overlay=roi,screen,diff (o all)
+  previewURL: (overlay = '', width = 640) =>
+    withToken(`${API_BASE_URL}/preview.mjpeg?width=${width}&overlay=${overlay}`),
+  snapshotURL: () => withToken(`${API_BASE_URL}/snapshot.jpg?t=${Date.now()}`),
+
+  // Galería: sesiones y diapositivas paginadas (offset, limit)
+  getGallerySessions: (offset = 0, limit = 50) =>
+    api.get('/gallery/sessions', { params: { offset, limit } }).then((res) => res.data),
+  getGallerySession: (id: string, offset = 0, limit = 50) =>
+    api.get(`/gallery/sessions/${encodeURIComponent(id)}`, { params: { offset, limit } }).then((res) => res.data),
+  getSlide: (id: string) => api.get(`/gallery/slides/${encodeURIComponent(id)}`).then((res) => res.data),
+  deleteSlide: (id: string) => api.delete(`/gallery/slides/${encodeURIComponent(id)}`).then((res) => res.data),
+  resendSlide: (id: string, chatIds: number[] = []) =>
+    api.post(`/gallery/slides/${encodeURIComponent(id)}/resend`, { chat_ids: chatIds }).then((res) => res.data),
+  // Las URL de imagen de la galería son relativas: image, raw y thumb
+  galleryImageURL: (path: string) => withToken(`${API_BASE_URL}${path}`),
 };
 
 export default api;
\ No newline at end of file
@@ -1,9 +1,24 @@
 export interface SystemStatus {
-  Status: 'stopped' | 'running' | 'paused';
+  Status: 'idle' | 'starting' | 'running' | 'paused' | 'stopping' | 'error';
+  SessionID: string;
+  SessionTitle: string;
+  SessionSource: string;
+  SessionChats: number[] | null;
   StartedAt: string;
   LastSlideAt: string;
   SlidesCaptured: number;
   LastError: string;
+  Upcoming: ScheduleSlot[] | null;
+}
+
+export interface ScheduleSlot {
+  start: string;
+  end: string;
+  title?: string;
+  chat_ids?: number[];
+  profile?: string;
+  location?: string;
+  source: 'config' | 'ics';
 }
 
 export interface Config {
@@ -35,13 +50,30 @@ export interface ControlResponse {
 }
 
 export interface LogEntry {
-  id: string;
+  id: number;
   timestamp: string;
   level: 'INFO' | 'WARN' | 'ERROR' | 'DEBUG';
   message: string;
   source: string;
 }
 
+export type PipelineEventType =
+  | 'state'
+  | 'frame'
+  | 'slide_detected'
+  | 'ocr_done'
+  | 'slide_sent'
+  | 'error';
+
+// Evento de /events; data depende de type (p. ej. frame: {score, threshold, changed, cooldown})
+export interface PipelineEvent {
+  id: number;
+  type: PipelineEventType;
+  time: string;
+  session?: string;
+  data: any;
+}
+
 export interface MetricsData {
   ocr_accuracy: number;
   processing_time: number;